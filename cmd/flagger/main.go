@@ -29,7 +29,11 @@ import (
 	"github.com/go-logr/zapr"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/dynamic"
+	kubeinformers "k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/cache"
@@ -40,6 +44,7 @@ import (
 	_ "k8s.io/code-generator/cmd/client-gen/generators"
 	"k8s.io/klog/v2"
 
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/fluxcd/flagger/pkg/canary"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 	informers "github.com/fluxcd/flagger/pkg/client/informers/externalversions"
@@ -49,10 +54,13 @@ import (
 	"github.com/fluxcd/flagger/pkg/notifier"
 	"github.com/fluxcd/flagger/pkg/router"
 	"github.com/fluxcd/flagger/pkg/server"
+	"github.com/fluxcd/flagger/pkg/sharding"
 	"github.com/fluxcd/flagger/pkg/signals"
 	"github.com/fluxcd/flagger/pkg/version"
 
 	knative "knative.dev/serving/pkg/client/clientset/versioned"
+	knativeinformers "knative.dev/serving/pkg/client/informers/externalversions"
+	servinginformers "knative.dev/serving/pkg/client/informers/externalversions/serving/v1"
 )
 
 var (
@@ -61,6 +69,7 @@ var (
 	kubeconfigQPS            int
 	kubeconfigBurst          int
 	metricsServer            string
+	metricsQueries           string
 	controlLoopInterval      time.Duration
 	logLevel                 string
 	port                     string
@@ -74,6 +83,9 @@ var (
 	slackChannel             string
 	eventWebhook             string
 	threadiness              int
+	maxConcurrentAnalyses    int
+	maxProgressing           int
+	maxProgressingNamespace  int
 	zapReplaceGlobals        bool
 	zapEncoding              string
 	namespace                string
@@ -83,11 +95,16 @@ var (
 	ingressClass             string
 	enableLeaderElection     bool
 	leaderElectionNamespace  string
+	enableSharding           bool
+	shardGroup               string
+	shardSelector            string
+	shardLeaseDuration       time.Duration
 	enableConfigTracking     bool
 	ver                      bool
 	kubeconfigServiceMesh    string
 	clusterName              string
 	noCrossNamespaceRefs     bool
+	linkerdGatewayAPI        bool
 )
 
 func init() {
@@ -96,6 +113,7 @@ func init() {
 	flag.IntVar(&kubeconfigBurst, "kubeconfig-burst", 250, "Set Burst for kubeconfig.")
 	flag.StringVar(&masterURL, "master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
 	flag.StringVar(&metricsServer, "metrics-server", "http://prometheus:9090", "Prometheus URL.")
+	flag.StringVar(&metricsQueries, "metrics-queries", "", "ConfigMap in the namespace/name format overriding the builtin metrics queries.")
 	flag.DurationVar(&controlLoopInterval, "control-loop-interval", 10*time.Second, "Kubernetes API sync interval.")
 	flag.StringVar(&logLevel, "log-level", "debug", "Log level can be: debug, info, warning, error.")
 	flag.StringVar(&port, "port", "8080", "Port to listen on.")
@@ -109,20 +127,28 @@ func init() {
 	flag.StringVar(&msteamsProxyURL, "msteams-proxy-url", "", "MS Teams proxy URL.")
 	flag.StringVar(&includeLabelPrefix, "include-label-prefix", "", "List of prefixes of labels that are copied when creating primary deployments or daemonsets. Use * to include all.")
 	flag.IntVar(&threadiness, "threadiness", 2, "Worker concurrency.")
+	flag.IntVar(&maxConcurrentAnalyses, "max-concurrent-analyses", 20, "Maximum number of canary analyses running at the same time.")
+	flag.IntVar(&maxProgressing, "max-progressing-canaries", 0, "Maximum number of canaries progressing at the same time in the cluster or in each shard, the other canaries wait in a queue. Zero means unlimited.")
+	flag.IntVar(&maxProgressingNamespace, "max-progressing-canaries-per-namespace", 0, "Maximum number of canaries progressing at the same time in a namespace or in each shard, the other canaries wait in a queue. Zero means unlimited.")
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
 	flag.StringVar(&namespace, "namespace", "", "Namespace that flagger would watch canary object.")
-	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be apisix, appmesh, appmesh:v1beta2, contour, gatewayapi:v1, gatewayapi:v1beta1, gloo, istio, knative, kubernetes, kuma, linkerd, nginx, osm, skipper, smi:v1alpha1, smi:v1alpha2, smi:v1alpha3, or traefik.")
+	flag.StringVar(&meshProvider, "mesh-provider", "istio", "Service mesh provider, can be alb, apisix, appmesh, appmesh:v1beta2, contour, gatewayapi:v1, gatewayapi:v1beta1, gloo, haproxy, istio, knative, kubernetes, kuma, linkerd, nginx, osm, skipper, smi:v1alpha1, smi:v1alpha2, smi:v1alpha3, or traefik.")
 	flag.StringVar(&selectorLabels, "selector-labels", "app,name,app.kubernetes.io/name", "List of pod labels that Flagger uses to create pod selectors.")
 	flag.StringVar(&ingressAnnotationsPrefix, "ingress-annotations-prefix", "nginx.ingress.kubernetes.io", "Annotations prefix for NGINX ingresses.")
 	flag.StringVar(&ingressClass, "ingress-class", "", "Ingress class used for annotating HTTPProxy objects.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false, "Enable leader election.")
-	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "kube-system", "Namespace used to create the leader election config map.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "kube-system", "Namespace used to create the leader election config map and the shard leases.")
+	flag.BoolVar(&enableSharding, "enable-sharding", false, "Split the canaries between all running replicas by consistent hashing of the canary namespace/name.")
+	flag.StringVar(&shardGroup, "shard-group", "flagger", "Name of the shard group, the replicas with the same group split the canaries between them.")
+	flag.StringVar(&shardSelector, "shard-selector", "", "Label selector of the canaries reconciled by this shard.")
+	flag.DurationVar(&shardLeaseDuration, "shard-lease-duration", 15*time.Second, "Duration after which the canaries of a replica that stopped renewing its shard lease move to the other replicas.")
 	flag.BoolVar(&enableConfigTracking, "enable-config-tracking", true, "Enable secrets and configmaps tracking.")
 	flag.BoolVar(&ver, "version", false, "Print version")
 	flag.StringVar(&kubeconfigServiceMesh, "kubeconfig-service-mesh", "", "Path to a kubeconfig for the service mesh control plane cluster.")
 	flag.StringVar(&clusterName, "cluster-name", "", "Cluster name to be included in alert msgs.")
 	flag.BoolVar(&noCrossNamespaceRefs, "no-cross-namespace-refs", false, "When set to true, Flagger can only refer to resources in the same namespace.")
+	flag.BoolVar(&linkerdGatewayAPI, "linkerd-gateway-api", false, "Route the linkerd provider traffic with a Gateway API HTTPRoute instead of a SMI TrafficSplit, requires Linkerd 2.15 or newer.")
 }
 
 func main() {
@@ -192,7 +218,7 @@ func main() {
 
 	verifyCRDs(flaggerClient, logger)
 	verifyKubernetesVersion(kubeClient, logger)
-	infos := startInformers(flaggerClient, logger, stopCh)
+	infos := startInformers(kubeClient, flaggerClient, knativeClient, logger, stopCh)
 
 	labels := strings.Split(selectorLabels, ",")
 	if len(labels) < 1 {
@@ -203,12 +229,29 @@ func main() {
 		logger.Infof("Watching namespace %s", namespace)
 	}
 
+	if enableSharding && enableLeaderElection {
+		logger.Fatalf("Sharding and leader election can't be enabled at the same time")
+	}
+
 	observerFactory, err := observers.NewFactory(metricsServer)
 	if err != nil {
 		logger.Fatalf("Error building prometheus client: %s", err.Error())
 	}
 
-	ok, err := observerFactory.Client.IsOnline()
+	observerFactory.DynamicClient, err = dynamic.NewForConfig(cfg)
+	if err != nil {
+		logger.Fatalf("Error building dynamic client: %s", err.Error())
+	}
+
+	if metricsQueries != "" {
+		observerFactory.Queries, err = loadMetricsQueries(kubeClient, metricsQueries)
+		if err != nil {
+			logger.Fatalf("Error loading the metrics queries from %s: %v", metricsQueries, err)
+		}
+		logger.Infof("Loaded the metrics queries overrides from %s", metricsQueries)
+	}
+
+	ok, err := observerFactory.Client.IsOnline(context.Background())
 	if ok {
 		logger.Infof("Connected to metrics server %s", metricsServer)
 	} else {
@@ -228,14 +271,35 @@ func main() {
 		setOwnerRefs = false
 	}
 
-	routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, knativeClient, ingressAnnotationsPrefix, ingressClass, logger, meshClient, setOwnerRefs)
+	routerFactory := router.NewFactory(cfg, kubeClient, flaggerClient, knativeClient, ingressAnnotationsPrefix, ingressClass, logger, meshClient, setOwnerRefs, linkerdGatewayAPI)
+
+	canaryListers := &canary.Listers{
+		Deployments:       infos.DeploymentInformer.Lister(),
+		DeploymentsSynced: infos.DeploymentInformer.Informer().HasSynced,
+		DaemonSets:        infos.DaemonSetInformer.Lister(),
+		DaemonSetsSynced:  infos.DaemonSetInformer.Informer().HasSynced,
+		Services:          infos.ServiceInformer.Lister(),
+		ServicesSynced:    infos.ServiceInformer.Informer().HasSynced,
+		HPAs:              infos.HPAInformer.Lister(),
+		HPAsSynced:        infos.HPAInformer.Informer().HasSynced,
+		Secrets:           infos.SecretInformer.Lister(),
+		SecretsSynced:     infos.SecretInformer.Informer().HasSynced,
+	}
+
+	if infos.KnativeServiceInformer != nil {
+		canaryListers.KnativeServices = infos.KnativeServiceInformer.Lister()
+		canaryListers.KnativeServicesSynced = infos.KnativeServiceInformer.Informer().HasSynced
+	}
 
 	var configTracker canary.Tracker
 	if enableConfigTracking {
+		canaryListers.ConfigMaps = infos.ConfigMapInformer.Lister()
+		canaryListers.ConfigMapsSynced = infos.ConfigMapInformer.Informer().HasSynced
 		configTracker = &canary.ConfigTracker{
 			Logger:        logger,
 			KubeClient:    kubeClient,
 			FlaggerClient: flaggerClient,
+			Listers:       canaryListers,
 		}
 	} else {
 		configTracker = &canary.NopTracker{}
@@ -243,7 +307,9 @@ func main() {
 
 	includeLabelPrefixArray := strings.Split(includeLabelPrefix, ",")
 
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, knativeClient, configTracker, labels, includeLabelPrefixArray, logger)
+	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, knativeClient, configTracker, labels, includeLabelPrefixArray, canaryListers, logger)
+
+	shard := initShard(kubeClient, logger)
 
 	c := controller.NewController(
 		kubeClient,
@@ -261,6 +327,10 @@ func main() {
 		fromEnv("EVENT_WEBHOOK_URL", eventWebhook),
 		clusterName,
 		noCrossNamespaceRefs,
+		maxConcurrentAnalyses,
+		maxProgressing,
+		maxProgressingNamespace,
+		shard,
 		cfg,
 	)
 
@@ -284,6 +354,14 @@ func main() {
 		}
 	}
 
+	// register this instance in the shard group
+	if shard != nil {
+		if err := shard.Start(ctx); err != nil {
+			logger.Fatalf("Error starting shard: %v", err)
+		}
+		logger.Infof("Running shard group %s with members %s", shardGroup, strings.Join(shard.Members(), ", "))
+	}
+
 	// run controller when this instance wins the leader election
 	if enableLeaderElection {
 		ns := leaderElectionNamespace
@@ -296,7 +374,8 @@ func main() {
 	}
 }
 
-func startInformers(flaggerClient clientset.Interface, logger *zap.SugaredLogger, stopCh <-chan struct{}) controller.Informers {
+func startInformers(kubeClient kubernetes.Interface, flaggerClient clientset.Interface, knativeClient knative.Interface,
+	logger *zap.SugaredLogger, stopCh <-chan struct{}) controller.Informers {
 	flaggerInformerFactory := informers.NewSharedInformerFactoryWithOptions(flaggerClient, time.Second*30, informers.WithNamespace(namespace))
 
 	logger.Info("Waiting for canary informer cache to sync")
@@ -320,13 +399,102 @@ func startInformers(flaggerClient clientset.Interface, logger *zap.SugaredLogger
 		logger.Fatalf("failed to wait for cache to sync")
 	}
 
+	logger.Info("Waiting for secret informer cache to sync")
+	kubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, time.Second*30, kubeinformers.WithNamespace(namespace))
+	secretInformer := kubeInformerFactory.Core().V1().Secrets()
+	go secretInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, secretInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
+	logger.Info("Waiting for deployment informer cache to sync")
+	deploymentInformer := kubeInformerFactory.Apps().V1().Deployments()
+	go deploymentInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, deploymentInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
+	logger.Info("Waiting for daemonset informer cache to sync")
+	daemonSetInformer := kubeInformerFactory.Apps().V1().DaemonSets()
+	go daemonSetInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, daemonSetInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
+	logger.Info("Waiting for service informer cache to sync")
+	serviceInformer := kubeInformerFactory.Core().V1().Services()
+	go serviceInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, serviceInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
+	logger.Info("Waiting for HPA informer cache to sync")
+	hpaInformer := kubeInformerFactory.Autoscaling().V2().HorizontalPodAutoscalers()
+	go hpaInformer.Informer().Run(stopCh)
+	if ok := cache.WaitForNamedCacheSync("flagger", stopCh, hpaInformer.Informer().HasSynced); !ok {
+		logger.Fatalf("failed to wait for cache to sync")
+	}
+
+	// config maps are only read by the config tracker
+	var configMapInformer coreinformers.ConfigMapInformer
+	if enableConfigTracking {
+		logger.Info("Waiting for config map informer cache to sync")
+		configMapInformer = kubeInformerFactory.Core().V1().ConfigMaps()
+		go configMapInformer.Informer().Run(stopCh)
+		if ok := cache.WaitForNamedCacheSync("flagger", stopCh, configMapInformer.Informer().HasSynced); !ok {
+			logger.Fatalf("failed to wait for cache to sync")
+		}
+	}
+
+	// the Knative CRDs are only installed when Knative is the mesh provider
+	var knativeServiceInformer servinginformers.ServiceInformer
+	if meshProvider == flaggerv1.KnativeProvider {
+		logger.Info("Waiting for Knative service informer cache to sync")
+		knativeInformerFactory := knativeinformers.NewSharedInformerFactoryWithOptions(knativeClient, time.Second*30, knativeinformers.WithNamespace(namespace))
+		knativeServiceInformer = knativeInformerFactory.Serving().V1().Services()
+		go knativeServiceInformer.Informer().Run(stopCh)
+		if ok := cache.WaitForNamedCacheSync("flagger", stopCh, knativeServiceInformer.Informer().HasSynced); !ok {
+			logger.Fatalf("failed to wait for cache to sync")
+		}
+	}
+
 	return controller.Informers{
-		CanaryInformer: canaryInformer,
-		MetricInformer: metricInformer,
-		AlertInformer:  alertInformer,
+		CanaryInformer:     canaryInformer,
+		MetricInformer:     metricInformer,
+		AlertInformer:      alertInformer,
+		SecretInformer:     secretInformer,
+		DeploymentInformer: deploymentInformer,
+		DaemonSetInformer:  daemonSetInformer,
+		ServiceInformer:    serviceInformer,
+		HPAInformer:        hpaInformer,
+		ConfigMapInformer:  configMapInformer,
+
+		KnativeServiceInformer: knativeServiceInformer,
 	}
 }
 
+func initShard(kubeClient kubernetes.Interface, logger *zap.SugaredLogger) *sharding.Shard {
+	if !enableSharding && shardSelector == "" {
+		return nil
+	}
+
+	selector, err := k8slabels.Parse(shardSelector)
+	if err != nil {
+		logger.Fatalf("Error parsing shard selector %s: %v", shardSelector, err)
+	}
+
+	id, err := os.Hostname()
+	if err != nil {
+		logger.Fatalf("Error running controller: %v", err)
+	}
+
+	ns := leaderElectionNamespace
+	if namespace != "" {
+		ns = namespace
+	}
+	return sharding.NewShard(kubeClient, ns, shardGroup, id, selector, enableSharding, shardLeaseDuration, logger)
+}
+
 func startLeaderElection(ctx context.Context, run func(), ns string, kubeClient kubernetes.Interface, logger *zap.SugaredLogger) {
 	configMapName := "flagger-leader-election"
 	id, err := os.Hostname()
@@ -403,6 +571,20 @@ func fromEnv(envVar string, defaultVal string) string {
 	return defaultVal
 }
 
+// loadMetricsQueries reads the builtin metrics query overrides from a ConfigMap
+func loadMetricsQueries(kubeClient kubernetes.Interface, ref string) (map[string]map[string]string, error) {
+	ns, name, err := cache.SplitMetaNamespaceKey(ref)
+	if err != nil || ns == "" {
+		return nil, fmt.Errorf("%s is not in the namespace/name format", ref)
+	}
+
+	cm, err := kubeClient.CoreV1().ConfigMaps(ns).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return observers.ParseQueries(cm.Data)
+}
+
 func verifyCRDs(flaggerClient clientset.Interface, logger *zap.SugaredLogger) {
 	_, err := flaggerClient.FlaggerV1beta1().Canaries(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
 	if err != nil {
@@ -20,6 +20,7 @@ import (
 	"flag"
 	"log"
 	"regexp"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -37,6 +38,12 @@ var (
 	namespaceRegexp   string
 	zapReplaceGlobals bool
 	zapEncoding       string
+	diffProxyPort     string
+	diffPrimaryURL    string
+	diffCanaryURL     string
+	diffIgnoreHeaders string
+	diffIgnoreFields  string
+	diffNoise         bool
 )
 
 func init() {
@@ -46,6 +53,12 @@ func init() {
 	flag.StringVar(&namespaceRegexp, "namespace-regexp", "", "Restrict access to canaries in matching namespaces.")
 	flag.BoolVar(&zapReplaceGlobals, "zap-replace-globals", false, "Whether to change the logging level of the global zap logger.")
 	flag.StringVar(&zapEncoding, "zap-encoding", "json", "Zap logger encoding.")
+	flag.StringVar(&diffProxyPort, "diff-proxy-port", "", "Port the diff proxy listens on, the diff proxy is disabled if empty.")
+	flag.StringVar(&diffPrimaryURL, "diff-primary-url", "", "Primary address the diff proxy sends the requests to.")
+	flag.StringVar(&diffCanaryURL, "diff-canary-url", "", "Canary address the diff proxy sends the requests to.")
+	flag.StringVar(&diffIgnoreHeaders, "diff-ignore-headers", "Date,Expires,Last-Modified,Etag,Set-Cookie,X-Request-Id,Content-Length,Age", "Comma separated list of response headers excluded from the diff.")
+	flag.StringVar(&diffIgnoreFields, "diff-ignore-fields", "", "Comma separated list of JSON fields excluded from the diff, e.g. id,items.*.createdAt.")
+	flag.BoolVar(&diffNoise, "diff-noise-detection", true, "Exclude from the diff the headers and fields that differ between two primary responses of GET and HEAD requests.")
 }
 
 func main() {
@@ -77,5 +90,22 @@ func main() {
 	}
 	authorizer := loadtester.NewAuthorizer(namespaceRegexpCompiled)
 
+	if diffProxyPort != "" {
+		diffProxy, err := loadtester.NewDiffProxy(loadtester.DiffProxyOptions{
+			PrimaryURL:     diffPrimaryURL,
+			CanaryURL:      diffCanaryURL,
+			Timeout:        time.Minute,
+			IgnoreHeaders:  strings.Split(diffIgnoreHeaders, ","),
+			IgnoreFields:   strings.Split(diffIgnoreFields, ","),
+			NoiseDetection: diffNoise,
+		}, logger, true)
+		if err != nil {
+			logger.Fatalf("Error creating diff proxy: %v", err)
+		}
+
+		logger.Infof("Starting diff proxy on port %s", diffProxyPort)
+		go diffProxy.ListenAndServe(diffProxyPort, stopCh)
+	}
+
 	loadtester.ListenAndServe(port, time.Minute, logger, taskRunner, gateStorage, authorizer, stopCh)
 }
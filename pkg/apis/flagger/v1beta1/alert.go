@@ -73,6 +73,9 @@ type AlertProviderSpec struct {
 	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
 }
 
+// AlertProviderReadyType is the condition reporting if the provider type and address are valid
+const AlertProviderReadyType = "Ready"
+
 type AlertProviderStatus struct {
 	// Conditions of this status
 	Conditions []AlertProviderCondition `json:"conditions,omitempty"`
@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,6 +30,8 @@ import (
 
 const (
 	CanaryKind              = "Canary"
+	ResumeStepAnnotation    = "flagger.app/resume-step"
+	ApexLabel               = "flagger.app/apex"
 	ProgressDeadlineSeconds = 600
 	AnalysisInterval        = 60 * time.Second
 	PrimaryReadyThreshold   = 100
@@ -77,6 +80,11 @@ type CanarySpec struct {
 	// +optional
 	MetricsServer string `json:"metricsServer,omitempty"`
 
+	// GatewayImplementation selects the builtin metrics queries of the Gateway API
+	// implementation serving the HTTPRoute, can be cilium or istio
+	// +optional
+	GatewayImplementation string `json:"gatewayImplementation,omitempty"`
+
 	// TargetRef references a target resource
 	TargetRef LocalObjectReference `json:"targetRef"`
 
@@ -88,7 +96,7 @@ type CanarySpec struct {
 	// +optional
 	IngressRef *LocalObjectReference `json:"ingressRef,omitempty"`
 
-	// Reference to APISIX route resource
+	// Reference to APISIX route or Traefik IngressRoute resource
 	// +optional
 	RouteRef *LocalObjectReference `json:"routeRef,omitempty"`
 
@@ -124,6 +132,11 @@ type CanarySpec struct {
 	// Canary is suspended during an analysis, its paused until the Canary is unsuspended.
 	// +optional
 	Suspend bool `json:"suspend,omitempty"`
+
+	// Priority orders the canaries waiting for a progressing slot when the
+	// number of progressing canaries is limited, higher values start first
+	// +optional
+	Priority int `json:"priority,omitempty"`
 }
 
 // CanaryService defines how ClusterIP services, service mesh or ingress routing objects are generated
@@ -287,6 +300,16 @@ type CanaryAnalysis struct {
 	// +optional
 	StepWeightPromotion int `json:"stepWeightPromotion,omitempty"`
 
+	// Shift the traffic of the kubernetes provider by scaling the canary deployment,
+	// the apex service selects the primary and canary pods by the flagger.app/apex label
+	// +optional
+	ReplicaRatio bool `json:"replicaRatio,omitempty"`
+
+	// Ordered traffic steps for analysis phase, each with its own
+	// bake time and checks. Can't be combined with StepWeight or StepWeights.
+	// +optional
+	Steps []CanaryStep `json:"steps,omitempty"`
+
 	// Max number of failed checks before the canary is terminated
 	Threshold int `json:"threshold"`
 
@@ -314,6 +337,52 @@ type CanaryAnalysis struct {
 	// SessionAffinity represents the session affinity settings for a canary run.
 	// +optional
 	SessionAffinity *SessionAffinity `json:"sessionAffinity,omitempty"`
+
+	// RevisionPolicy defines how a new revision is handled during the analysis:
+	// restart, finish or reject (default restart)
+	// +optional
+	RevisionPolicy CanaryRevisionPolicy `json:"revisionPolicy,omitempty"`
+}
+
+// CanaryRevisionPolicy defines how a new revision detected during the analysis is handled
+type CanaryRevisionPolicy string
+
+const (
+	// RevisionPolicyRestart routes all traffic back to primary and restarts the analysis
+	RevisionPolicyRestart CanaryRevisionPolicy = "restart"
+	// RevisionPolicyFinish completes the current analysis and then starts
+	// the analysis of the latest revision, skipping the intermediate ones
+	RevisionPolicyFinish CanaryRevisionPolicy = "finish"
+	// RevisionPolicyReject ignores new revisions until the current analysis completes
+	RevisionPolicyReject CanaryRevisionPolicy = "reject"
+)
+
+// CanaryStep defines a traffic weight step of the canary analysis
+type CanaryStep struct {
+	// Traffic weight routed to canary during this step
+	Weight int `json:"weight"`
+
+	// Minimum time spent at this step before advancing
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// Minimum number of successful checks at this step before advancing (default 1)
+	// +optional
+	Iterations int `json:"iterations,omitempty"`
+
+	// Metric check list run in addition to the analysis metrics during this step
+	// +optional
+	Metrics []CanaryMetric `json:"metrics,omitempty"`
+
+	// Webhook list run in addition to the analysis webhooks during this step,
+	// only rollout and confirm-traffic-increase hooks are supported
+	// +optional
+	Webhooks []CanaryWebhook `json:"webhooks,omitempty"`
+
+	// Pause holds the canary at this step until it is resumed
+	// with the flagger.app/resume-step annotation
+	// +optional
+	Pause bool `json:"pause,omitempty"`
 }
 
 type SessionAffinity struct {
@@ -374,6 +443,53 @@ type CanaryMetric struct {
 	// TemplateVariables provides a map of key/value pairs that can be used to inject variables into a metric query.
 	// +optional
 	TemplateVariables map[string]string `json:"templateVariables,omitempty"`
+
+	// Evaluation of the series returned by the metric query,
+	// by default the threshold is checked against a single value
+	// +optional
+	Evaluation *CanaryMetricEvaluation `json:"evaluation,omitempty"`
+
+	// Params of the builtin request-success-rate and request-duration metrics
+	// +optional
+	Params *CanaryMetricParams `json:"params,omitempty"`
+}
+
+// CanaryMetricParams defines the parameters of the builtin metrics queries
+type CanaryMetricParams struct {
+	// Quantile of the request-duration histogram, defaults to 0.99
+	// +optional
+	Quantile string `json:"quantile,omitempty"`
+
+	// ExcludedStatus is the regex matching the status codes that are not counted
+	// as successful requests by request-success-rate, defaults to 5xx
+	// +optional
+	ExcludedStatus string `json:"excludedStatus,omitempty"`
+}
+
+// CanaryMetricEvaluationMode defines how the series of a metric result are evaluated
+type CanaryMetricEvaluationMode string
+
+const (
+	// MetricEvaluationAll checks the threshold against every series
+	MetricEvaluationAll CanaryMetricEvaluationMode = "all"
+	// MetricEvaluationMax checks the threshold against the series with the highest value
+	MetricEvaluationMax CanaryMetricEvaluationMode = "max"
+	// MetricEvaluationMin checks the threshold against the series with the lowest value
+	MetricEvaluationMin CanaryMetricEvaluationMode = "min"
+	// MetricEvaluationAvg checks the threshold against the average of the series
+	MetricEvaluationAvg CanaryMetricEvaluationMode = "avg"
+	// MetricEvaluationSeries checks the threshold against the series matching a label set
+	MetricEvaluationSeries CanaryMetricEvaluationMode = "series"
+)
+
+// CanaryMetricEvaluation defines the evaluation of a vector metric result
+type CanaryMetricEvaluation struct {
+	// Mode of evaluation: all, max, min, avg or series
+	Mode CanaryMetricEvaluationMode `json:"mode"`
+
+	// Series label set selecting the evaluated series in series mode
+	// +optional
+	Series map[string]string `json:"series,omitempty"`
 }
 
 // CanaryThresholdRange defines the range used for metrics validation
@@ -675,6 +791,47 @@ func (c *Canary) GetAnalysisCanaryReadyThreshold() int {
 	return CanaryReadyThreshold
 }
 
+// UsesReplicaRatio returns true if the canary opted in to shift the traffic by scaling the canary deployment
+func (c *Canary) UsesReplicaRatio() bool {
+	return c.GetAnalysis() != nil && c.GetAnalysis().ReplicaRatio
+}
+
+// GetAnalysisRevisionPolicy returns the canary revision policy (default restart)
+func (c *Canary) GetAnalysisRevisionPolicy() CanaryRevisionPolicy {
+	switch c.GetAnalysis().RevisionPolicy {
+	case RevisionPolicyFinish, RevisionPolicyReject:
+		return c.GetAnalysis().RevisionPolicy
+	default:
+		return RevisionPolicyRestart
+	}
+}
+
+// GetAnalysisStep returns the analysis step the canary is currently at,
+// nil if no steps are defined or the first step hasn't been reached yet
+func (c *Canary) GetAnalysisStep() *CanaryStep {
+	steps := c.GetAnalysis().Steps
+	if c.Status.StepStartTime == nil || c.Status.CurrentStep >= len(steps) {
+		return nil
+	}
+	return &steps[c.Status.CurrentStep]
+}
+
+// GetAnalysisMetrics returns the analysis metrics along with the metrics of the current step
+func (c *Canary) GetAnalysisMetrics() []CanaryMetric {
+	metrics := c.GetAnalysis().Metrics
+	if step := c.GetAnalysisStep(); step != nil && len(step.Metrics) > 0 {
+		metrics = append(metrics[:len(metrics):len(metrics)], step.Metrics...)
+	}
+	return metrics
+}
+
+// IsAnalysisStepResumed returns true if the current step has been
+// resumed by setting the flagger.app/resume-step annotation to its index
+func (c *Canary) IsAnalysisStepResumed() bool {
+	value, ok := c.Annotations[ResumeStepAnnotation]
+	return ok && value == strconv.Itoa(c.Status.CurrentStep)
+}
+
 // GetMetricInterval returns the metric interval default value (1m)
 func (c *Canary) GetMetricInterval() string {
 	return MetricInterval
@@ -710,6 +867,27 @@ func (c *Canary) DeploymentStrategy() string {
 	return DeploymentStrategyCanary
 }
 
+// GetDuration returns the minimum time spent at this step (default 0)
+func (s *CanaryStep) GetDuration() time.Duration {
+	if s.Duration == "" {
+		return 0
+	}
+
+	duration, err := time.ParseDuration(s.Duration)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
+
+// GetIterations returns the minimum number of checks run at this step (default 1)
+func (s *CanaryStep) GetIterations() int {
+	if s.Iterations > 0 {
+		return s.Iterations
+	}
+	return 1
+}
+
 // BuildCookie returns the cookie that should be used as the value of a Set-Cookie header
 func (s *SessionAffinity) BuildCookie(cookieName string, maxAge int) string {
 	cookie := fmt.Sprintf("%s; %s=%d", cookieName, "Max-Age",
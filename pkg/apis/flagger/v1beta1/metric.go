@@ -83,6 +83,24 @@ type MetricTemplateProvider struct {
 	// InsecureSkipVerify disables certificate verification for the provider
 	// +optional
 	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// Timeout of each query attempt e.g. 10s, defaults to the provider client timeout
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// Retries is the number of times a query is retried after a transient error
+	// such as a network timeout or a gateway error
+	// +optional
+	Retries int `json:"retries,omitempty"`
+
+	// RetryBackoff is the wait time before the first retry, doubled after each attempt, defaults to 1s
+	// +optional
+	RetryBackoff string `json:"retryBackoff,omitempty"`
+
+	// ResultPath is the dot separated path to the value in the query response,
+	// used by providers that return JSON documents such as Elasticsearch
+	// +optional
+	ResultPath string `json:"resultPath,omitempty"`
 }
 
 // MetricTemplateModel is the query template model
@@ -95,6 +113,10 @@ type MetricTemplateModel struct {
 	Route     string            `json:"route"`
 	Interval  string            `json:"interval"`
 	Variables map[string]string `json:"variables"`
+
+	// Quantile and ExcludedStatus are the builtin metrics parameters
+	Quantile       string `json:"quantile,omitempty"`
+	ExcludedStatus string `json:"excludedStatus,omitempty"`
 }
 
 // TemplateFunctions returns a map of functions, one for each model field
@@ -108,9 +130,23 @@ func (mtm *MetricTemplateModel) TemplateFunctions() template.FuncMap {
 		"route":     func() string { return mtm.Route },
 		"interval":  func() string { return mtm.Interval },
 		"variables": func() map[string]string { return mtm.Variables },
+		"quantile":  func(fallback string) string { return valueOrDefault(mtm.Quantile, fallback) },
+		"excludedStatus": func(fallback string) string {
+			return valueOrDefault(mtm.ExcludedStatus, fallback)
+		},
+	}
+}
+
+func valueOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
 	}
+	return value
 }
 
+// MetricTemplateReadyType is the condition reporting if the template secret, provider and query are valid
+const MetricTemplateReadyType = "Ready"
+
 type MetricTemplateStatus struct {
 	// Conditions of this status
 	Conditions []MetricTemplateCondition `json:"conditions,omitempty"`
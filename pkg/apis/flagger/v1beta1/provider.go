@@ -1,6 +1,7 @@
 package v1beta1
 
 const (
+	ALBProvider        string = "alb"
 	ApisixProvider     string = "apisix"
 	AppMeshProvider    string = "appmesh"
 	LinkerdProvider    string = "linkerd"
@@ -16,4 +17,11 @@ const (
 	OsmProvider        string = "osm"
 	KumaProvider       string = "kuma"
 	GatewayAPIProvider string = "gatewayapi"
+	HAProxyProvider    string = "haproxy"
+)
+
+// Gateway API implementations, used to select the builtin metrics queries of gatewayapi canaries
+const (
+	CiliumGatewayImplementation string = "cilium"
+	IstioGatewayImplementation  string = "istio"
 )
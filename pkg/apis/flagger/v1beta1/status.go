@@ -74,6 +74,18 @@ type CanaryStatus struct {
 	CanaryWeight int         `json:"canaryWeight"`
 	Iterations   int         `json:"iterations"`
 	// +optional
+	TransientChecks int `json:"transientChecks,omitempty"`
+	// +optional
+	CurrentStep int `json:"currentStep,omitempty"`
+	// +optional
+	StepIterations int `json:"stepIterations,omitempty"`
+	// +optional
+	StepStartTime *metav1.Time `json:"stepStartTime,omitempty"`
+	// +optional
+	QueuePosition int `json:"queuePosition,omitempty"`
+	// +optional
+	PendingRevision string `json:"pendingRevision,omitempty"`
+	// +optional
 	PreviousSessionAffinityCookie string `json:"previousSessionAffinityCookie,omitempty"`
 	// +optional
 	SessionAffinityCookie string `json:"sessionAffinityCookie,omitempty"`
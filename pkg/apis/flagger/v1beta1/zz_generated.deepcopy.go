@@ -234,6 +234,13 @@ func (in *CanaryAnalysis) DeepCopyInto(out *CanaryAnalysis) {
 		*out = make([]int, len(*in))
 		copy(*out, *in)
 	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]CanaryStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PrimaryReadyThreshold != nil {
 		in, out := &in.PrimaryReadyThreshold, &out.PrimaryReadyThreshold
 		*out = new(int)
@@ -359,6 +366,16 @@ func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
 			(*out)[key] = val
 		}
 	}
+	if in.Evaluation != nil {
+		in, out := &in.Evaluation, &out.Evaluation
+		*out = new(CanaryMetricEvaluation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = new(CanaryMetricParams)
+		**out = **in
+	}
 	return
 }
 
@@ -372,6 +389,45 @@ func (in *CanaryMetric) DeepCopy() *CanaryMetric {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricEvaluation) DeepCopyInto(out *CanaryMetricEvaluation) {
+	*out = *in
+	if in.Series != nil {
+		in, out := &in.Series, &out.Series
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricEvaluation.
+func (in *CanaryMetricEvaluation) DeepCopy() *CanaryMetricEvaluation {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricEvaluation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryMetricParams) DeepCopyInto(out *CanaryMetricParams) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryMetricParams.
+func (in *CanaryMetricParams) DeepCopy() *CanaryMetricParams {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryMetricParams)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryService) DeepCopyInto(out *CanaryService) {
 	*out = *in
@@ -526,6 +582,10 @@ func (in *CanarySpec) DeepCopy() *CanarySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
 	*out = *in
+	if in.StepStartTime != nil {
+		in, out := &in.StepStartTime, &out.StepStartTime
+		*out = (*in).DeepCopy()
+	}
 	if in.TrackedConfigs != nil {
 		in, out := &in.TrackedConfigs, &out.TrackedConfigs
 		*out = new(map[string]string)
@@ -558,6 +618,36 @@ func (in *CanaryStatus) DeepCopy() *CanaryStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStep) DeepCopyInto(out *CanaryStep) {
+	*out = *in
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]CanaryMetric, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]CanaryWebhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStep.
+func (in *CanaryStep) DeepCopy() *CanaryStep {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryThresholdRange) DeepCopyInto(out *CanaryThresholdRange) {
 	*out = *in
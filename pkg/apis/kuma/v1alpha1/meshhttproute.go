@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+
+// MeshHTTPRoute is the Schema for the HTTP routing policy API.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type MeshHTTPRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MeshHTTPRouteSpec `json:"spec,omitempty"`
+}
+
+// MeshHTTPRouteList defines a list of MeshHTTPRoute objects.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type MeshHTTPRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MeshHTTPRoute `json:"items"`
+}
+
+// MeshHTTPRouteSpec defines the spec for a MeshHTTPRoute.
+type MeshHTTPRouteSpec struct {
+	// TargetRef is a reference to the resource the policy takes an effect on.
+	TargetRef TargetRef `json:"targetRef"`
+	// To matches destination services of requests and holds configuration.
+	To []MeshHTTPRouteTo `json:"to,omitempty"`
+}
+
+// TargetRef defines the resource a policy or a backend refers to.
+type TargetRef struct {
+	// Kind of the referenced resource, e.g. Mesh or MeshService.
+	Kind string `json:"kind"`
+	// Name of the referenced resource.
+	Name string `json:"name,omitempty"`
+}
+
+// MeshHTTPRouteTo holds the routing rules of a destination service.
+type MeshHTTPRouteTo struct {
+	// TargetRef is a reference to the destination service.
+	TargetRef TargetRef `json:"targetRef"`
+	// Rules contains the routing rules that apply to the target.
+	Rules []MeshHTTPRouteRule `json:"rules,omitempty"`
+}
+
+// MeshHTTPRouteRule defines the matches and the configuration of a route.
+type MeshHTTPRouteRule struct {
+	// Matches describes how to match HTTP requests this rule should be applied to.
+	Matches []MeshHTTPRouteMatch `json:"matches"`
+	// Default holds the configuration applied to the matched requests.
+	Default MeshHTTPRouteConf `json:"default"`
+}
+
+// MeshHTTPRouteMatch matches the requests of a route.
+type MeshHTTPRouteMatch struct {
+	Path *PathMatch `json:"path,omitempty"`
+}
+
+// PathMatch matches the path of the requests.
+type PathMatch struct {
+	// Type of the match: Exact, PathPrefix or RegularExpression.
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// MeshHTTPRouteConf holds the backends and the filters of a route.
+type MeshHTTPRouteConf struct {
+	Filters     []MeshHTTPRouteFilter `json:"filters,omitempty"`
+	BackendRefs []BackendRef          `json:"backendRefs,omitempty"`
+}
+
+// MeshHTTPRouteFilter modifies the requests of a route.
+type MeshHTTPRouteFilter struct {
+	// Type of the filter, e.g. RequestMirror.
+	Type          string         `json:"type"`
+	RequestMirror *RequestMirror `json:"requestMirror,omitempty"`
+}
+
+// RequestMirror mirrors the requests to a backend.
+type RequestMirror struct {
+	// BackendRef is the destination of the mirrored requests.
+	BackendRef BackendRef `json:"backendRef"`
+	// Percentage of the requests to mirror, defaults to 100.
+	Percentage *string `json:"percentage,omitempty"`
+}
+
+// BackendRef defines a weighted destination of the requests.
+type BackendRef struct {
+	TargetRef `json:",inline"`
+	Weight    *uint32 `json:"weight,omitempty"`
+}
@@ -25,6 +25,270 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendRef) DeepCopyInto(out *BackendRef) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(uint32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendRef.
+func (in *BackendRef) DeepCopy() *BackendRef {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshHTTPRoute) DeepCopyInto(out *MeshHTTPRoute) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshHTTPRoute.
+func (in *MeshHTTPRoute) DeepCopy() *MeshHTTPRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshHTTPRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MeshHTTPRoute) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshHTTPRouteConf) DeepCopyInto(out *MeshHTTPRouteConf) {
+	*out = *in
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = make([]MeshHTTPRouteFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BackendRefs != nil {
+		in, out := &in.BackendRefs, &out.BackendRefs
+		*out = make([]BackendRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshHTTPRouteConf.
+func (in *MeshHTTPRouteConf) DeepCopy() *MeshHTTPRouteConf {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshHTTPRouteConf)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshHTTPRouteFilter) DeepCopyInto(out *MeshHTTPRouteFilter) {
+	*out = *in
+	if in.RequestMirror != nil {
+		in, out := &in.RequestMirror, &out.RequestMirror
+		*out = new(RequestMirror)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshHTTPRouteFilter.
+func (in *MeshHTTPRouteFilter) DeepCopy() *MeshHTTPRouteFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshHTTPRouteFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshHTTPRouteList) DeepCopyInto(out *MeshHTTPRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MeshHTTPRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshHTTPRouteList.
+func (in *MeshHTTPRouteList) DeepCopy() *MeshHTTPRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshHTTPRouteList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MeshHTTPRouteList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshHTTPRouteMatch) DeepCopyInto(out *MeshHTTPRouteMatch) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(PathMatch)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshHTTPRouteMatch.
+func (in *MeshHTTPRouteMatch) DeepCopy() *MeshHTTPRouteMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshHTTPRouteMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshHTTPRouteRule) DeepCopyInto(out *MeshHTTPRouteRule) {
+	*out = *in
+	if in.Matches != nil {
+		in, out := &in.Matches, &out.Matches
+		*out = make([]MeshHTTPRouteMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Default.DeepCopyInto(&out.Default)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshHTTPRouteRule.
+func (in *MeshHTTPRouteRule) DeepCopy() *MeshHTTPRouteRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshHTTPRouteRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshHTTPRouteSpec) DeepCopyInto(out *MeshHTTPRouteSpec) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]MeshHTTPRouteTo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshHTTPRouteSpec.
+func (in *MeshHTTPRouteSpec) DeepCopy() *MeshHTTPRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshHTTPRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MeshHTTPRouteTo) DeepCopyInto(out *MeshHTTPRouteTo) {
+	*out = *in
+	out.TargetRef = in.TargetRef
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]MeshHTTPRouteRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshHTTPRouteTo.
+func (in *MeshHTTPRouteTo) DeepCopy() *MeshHTTPRouteTo {
+	if in == nil {
+		return nil
+	}
+	out := new(MeshHTTPRouteTo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PathMatch) DeepCopyInto(out *PathMatch) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PathMatch.
+func (in *PathMatch) DeepCopy() *PathMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(PathMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestMirror) DeepCopyInto(out *RequestMirror) {
+	*out = *in
+	in.BackendRef.DeepCopyInto(&out.BackendRef)
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestMirror.
+func (in *RequestMirror) DeepCopy() *RequestMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selector) DeepCopyInto(out *Selector) {
 	*out = *in
@@ -48,6 +312,22 @@ func (in *Selector) DeepCopy() *Selector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetRef) DeepCopyInto(out *TargetRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetRef.
+func (in *TargetRef) DeepCopy() *TargetRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficRoute) DeepCopyInto(out *TrafficRoute) {
 	*out = *in
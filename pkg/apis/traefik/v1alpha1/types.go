@@ -31,17 +31,120 @@ type TraefikServiceList struct {
 // ServiceSpec defines whether a TraefikService is a load-balancer of services or a
 // mirroring service.
 type ServiceSpec struct {
-	Weighted *WeightedRoundRobin `json:"weighted,omitempty"`
+	Weighted  *WeightedRoundRobin `json:"weighted,omitempty"`
+	Mirroring *Mirroring          `json:"mirroring,omitempty"`
+}
+
+// Mirroring defines a mirroring service, which is composed of a main
+// load-balancer, and a list of mirrors.
+type Mirroring struct {
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	Kind      string          `json:"kind,omitempty"`
+	Port      int32           `json:"port"`
+	Mirrors   []MirrorService `json:"mirrors,omitempty"`
+}
+
+// MirrorService defines one of the mirrors of a Mirroring service.
+type MirrorService struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind,omitempty"`
+	Port      int32  `json:"port"`
+	Percent   int    `json:"percent,omitempty"`
 }
 
 // WeightedRoundRobin defines a load-balancer of services.
 type WeightedRoundRobin struct {
 	Services []Service `json:"services,omitempty"`
+	Sticky   *Sticky   `json:"sticky,omitempty"`
+}
+
+// Sticky holds the sticky sessions configuration of the load-balancer.
+type Sticky struct {
+	Cookie *Cookie `json:"cookie,omitempty"`
+}
+
+// Cookie holds the sticky session cookie configuration.
+type Cookie struct {
+	Name     string `json:"name,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	SameSite string `json:"sameSite,omitempty"`
+	MaxAge   int    `json:"maxAge,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
 }
 
 type Service struct {
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
+	Kind      string `json:"kind,omitempty"`
 	Port      int32  `json:"port"`
 	Weight    uint   `json:"weight,omitempty"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IngressRoute is the CRD implementation of a Traefik HTTP Router.
+type IngressRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec IngressRouteSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// IngressRouteList is a list of IngressRoute resources.
+type IngressRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []IngressRoute `json:"items"`
+}
+
+// IngressRouteSpec defines the desired state of IngressRoute.
+type IngressRouteSpec struct {
+	Routes      []Route  `json:"routes"`
+	EntryPoints []string `json:"entryPoints,omitempty"`
+	TLS         *TLS     `json:"tls,omitempty"`
+}
+
+// Route holds the HTTP route configuration.
+type Route struct {
+	Match       string          `json:"match"`
+	Kind        string          `json:"kind,omitempty"`
+	Priority    int             `json:"priority,omitempty"`
+	Syntax      string          `json:"syntax,omitempty"`
+	Services    []Service       `json:"services,omitempty"`
+	Middlewares []MiddlewareRef `json:"middlewares,omitempty"`
+}
+
+// MiddlewareRef is a reference to a Middleware resource.
+type MiddlewareRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// TLS holds the TLS configuration.
+type TLS struct {
+	SecretName   string      `json:"secretName,omitempty"`
+	Options      *TLSRef     `json:"options,omitempty"`
+	Store        *TLSRef     `json:"store,omitempty"`
+	CertResolver string      `json:"certResolver,omitempty"`
+	Domains      []TLSDomain `json:"domains,omitempty"`
+}
+
+// TLSRef is a reference to a TLSOption or TLSStore resource.
+type TLSRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// TLSDomain holds a domain name with SANs.
+type TLSDomain struct {
+	Main string   `json:"main,omitempty"`
+	SANs []string `json:"sans,omitempty"`
+}
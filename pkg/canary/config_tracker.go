@@ -39,6 +39,7 @@ type ConfigTracker struct {
 	KubeClient    kubernetes.Interface
 	FlaggerClient clientset.Interface
 	Logger        *zap.SugaredLogger
+	Listers       *Listers
 }
 
 type ConfigRefType string
@@ -76,7 +77,7 @@ func configIsDisabled(annotations map[string]string) bool {
 // getRefFromConfigMap transforms a Kubernetes ConfigMap into a ConfigRef
 // and computes the checksum of the ConfigMap data
 func (ct *ConfigTracker) getRefFromConfigMap(name string, namespace string) (*ConfigRef, error) {
-	config, err := ct.KubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	config, err := ct.Listers.getConfigMap(ct.KubeClient, namespace, name)
 	if err != nil {
 		return nil, fmt.Errorf("configmap %s.%s get query error: %w", name, namespace, err)
 	}
@@ -95,7 +96,7 @@ func (ct *ConfigTracker) getRefFromConfigMap(name string, namespace string) (*Co
 // getRefFromConfigMap transforms a Kubernetes Secret into a ConfigRef
 // and computes the checksum of the Secret data
 func (ct *ConfigTracker) getRefFromSecret(name string, namespace string) (*ConfigRef, error) {
-	secret, err := ct.KubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	secret, err := ct.Listers.getSecret(ct.KubeClient, namespace, name)
 	if err != nil {
 		return nil, fmt.Errorf("secret %s.%s get query error: %w", name, namespace, err)
 	}
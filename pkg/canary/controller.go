@@ -26,8 +26,12 @@ type Controller interface {
 	GetMetadata(canary *flaggerv1.Canary) (string, string, map[string]int32, error)
 	SyncStatus(canary *flaggerv1.Canary, status flaggerv1.CanaryStatus) error
 	SetStatusFailedChecks(canary *flaggerv1.Canary, val int) error
+	SetStatusTransientChecks(canary *flaggerv1.Canary, val int) error
 	SetStatusWeight(canary *flaggerv1.Canary, val int) error
 	SetStatusIterations(canary *flaggerv1.Canary, val int) error
+	SetStatusStep(canary *flaggerv1.Canary, step int, iterations int) error
+	SetStatusQueuePosition(canary *flaggerv1.Canary, position int) error
+	SetStatusPendingRevision(canary *flaggerv1.Canary, revision string) error
 	SetStatusPhase(canary *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error
 	Initialize(canary *flaggerv1.Canary) (bool, error)
 	Promote(canary *flaggerv1.Canary) error
@@ -37,3 +41,12 @@ type Controller interface {
 	ScaleFromZero(canary *flaggerv1.Canary) error
 	Finalize(canary *flaggerv1.Canary) error
 }
+
+// RevisionPinner represents a controller that can hold the canary pods on the
+// analysed revision while a newer revision waits for the analysis to complete.
+type RevisionPinner interface {
+	TargetRevision(canary *flaggerv1.Canary) (string, error)
+	PinRevision(canary *flaggerv1.Canary) error
+	UnpinRevision(canary *flaggerv1.Canary) error
+	IsRevisionPinned(canary *flaggerv1.Canary) (bool, error)
+}
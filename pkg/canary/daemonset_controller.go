@@ -45,6 +45,7 @@ type DaemonSetController struct {
 	configTracker      Tracker
 	labels             []string
 	includeLabelPrefix []string
+	listers            *Listers
 }
 
 func (c *DaemonSetController) ScaleToZero(cd *flaggerv1.Canary) error {
@@ -187,7 +188,7 @@ func (c *DaemonSetController) Promote(cd *flaggerv1.Canary) error {
 // HasTargetChanged returns true if the canary DaemonSet pod spec has changed
 func (c *DaemonSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
-	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.listers.getDaemonSet(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return false, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
@@ -209,7 +210,7 @@ func (c *DaemonSetController) HasTargetChanged(cd *flaggerv1.Canary) (bool, erro
 func (c *DaemonSetController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
 	targetName := cd.Spec.TargetRef.Name
 
-	canaryDae, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canaryDae, err := c.listers.getDaemonSet(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return "", "", nil, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
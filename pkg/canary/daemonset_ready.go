@@ -17,12 +17,10 @@ limitations under the License.
 package canary
 
 import (
-	"context"
 	"fmt"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
@@ -31,7 +29,7 @@ import (
 // the daemonset is in the middle of a rolling update
 func (c *DaemonSetController) IsPrimaryReady(cd *flaggerv1.Canary) (bool, error) {
 	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
-	primary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	primary, err := c.listers.getDaemonSet(c.kubeClient, cd.Namespace, primaryName)
 	if err != nil {
 		return true, fmt.Errorf("daemonset %s.%s get query error: %w", primaryName, cd.Namespace, err)
 	}
@@ -47,7 +45,7 @@ func (c *DaemonSetController) IsPrimaryReady(cd *flaggerv1.Canary) (bool, error)
 // the daemonset is in the middle of a rolling update
 func (c *DaemonSetController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
-	canary, err := c.kubeClient.AppsV1().DaemonSets(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.listers.getDaemonSet(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return true, fmt.Errorf("daemonset %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
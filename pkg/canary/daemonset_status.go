@@ -57,6 +57,11 @@ func (c *DaemonSetController) SetStatusFailedChecks(cd *flaggerv1.Canary, val in
 	return setStatusFailedChecks(c.flaggerClient, cd, val)
 }
 
+// SetStatusTransientChecks updates the canary transient checks counter
+func (c *DaemonSetController) SetStatusTransientChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusTransientChecks(c.flaggerClient, cd, val)
+}
+
 // SetStatusWeight updates the canary status weight value
 func (c *DaemonSetController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
 	return setStatusWeight(c.flaggerClient, cd, val)
@@ -67,6 +72,21 @@ func (c *DaemonSetController) SetStatusIterations(cd *flaggerv1.Canary, val int)
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusStep updates the canary status analysis step and its iterations
+func (c *DaemonSetController) SetStatusStep(cd *flaggerv1.Canary, step int, iterations int) error {
+	return setStatusStep(c.flaggerClient, cd, step, iterations)
+}
+
+// SetStatusQueuePosition updates the canary position in the progressing queue
+func (c *DaemonSetController) SetStatusQueuePosition(cd *flaggerv1.Canary, position int) error {
+	return setStatusQueuePosition(c.flaggerClient, cd, position)
+}
+
+// SetStatusPendingRevision updates the revision waiting for the analysis to complete
+func (c *DaemonSetController) SetStatusPendingRevision(cd *flaggerv1.Canary, revision string) error {
+	return setStatusPendingRevision(c.flaggerClient, cd, revision)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *DaemonSetController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
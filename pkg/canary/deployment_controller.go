@@ -42,6 +42,7 @@ type DeploymentController struct {
 	configTracker      Tracker
 	labels             []string
 	includeLabelPrefix []string
+	listers            *Listers
 }
 
 // Initialize creates the primary deployment if it does not exist.
@@ -72,6 +73,16 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 			return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
 		}
 
+		// promote the analysed revision when a newer one is waiting
+		if isPinned(canary) {
+			template, err := c.pinnedTemplate(canary)
+			if err != nil {
+				return err
+			}
+			canary.Spec.Template = *template
+			delete(canary.Annotations, pinnedRevisionAnnotation)
+		}
+
 		label, labelValue, err := c.getSelectorLabel(canary)
 		primaryLabelValue := fmt.Sprintf("%s-primary", labelValue)
 		if err != nil {
@@ -112,7 +123,7 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 		}
 
 		primaryCopy.Spec.Template.Annotations = podAnnotations
-		primaryCopy.Spec.Template.Labels = makePrimaryLabels(canary.Spec.Template.Labels, primaryLabelValue, label)
+		primaryCopy.Spec.Template.Labels = makeApexLabels(cd, makePrimaryLabels(canary.Spec.Template.Labels, primaryLabelValue, label))
 
 		// update deploy annotations
 		primaryCopy.ObjectMeta.Annotations = make(map[string]string)
@@ -139,18 +150,18 @@ func (c *DeploymentController) Promote(cd *flaggerv1.Canary) error {
 // HasTargetChanged returns true if the canary deployment pod spec has changed
 func (c *DeploymentController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
-	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return false, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
 
-	return hasSpecChanged(cd, canary.Spec.Template)
+	return hasSpecChanged(cd, templateSpec(canary.Spec.Template))
 }
 
 // ScaleToZero Scale sets the canary deployment replicas
 func (c *DeploymentController) ScaleToZero(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
-	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	dep, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
@@ -165,7 +176,7 @@ func (c *DeploymentController) ScaleToZero(cd *flaggerv1.Canary) error {
 
 func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	targetName := cd.Spec.TargetRef.Name
-	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	dep, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
@@ -176,7 +187,7 @@ func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 	} else if cd.Spec.AutoscalerRef == nil {
 		// If HPA isn't set and replicas are not specified, it uses the primary replicas when scaling up the canary
 		primaryName := fmt.Sprintf("%s-primary", targetName)
-		primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+		primary, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, primaryName)
 		if err != nil {
 			return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
 		}
@@ -186,7 +197,7 @@ func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 		}
 	} else if cd.Spec.AutoscalerRef != nil {
 		if cd.Spec.AutoscalerRef.Kind == "HorizontalPodAutoscaler" {
-			hpa, err := c.kubeClient.AutoscalingV2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), cd.Spec.AutoscalerRef.Name, metav1.GetOptions{})
+			hpa, err := c.listers.getHPA(c.kubeClient, cd.Namespace, cd.Spec.AutoscalerRef.Name)
 			if err == nil {
 				if hpa.Spec.MinReplicas != nil && *hpa.Spec.MinReplicas > 1 {
 					replicas = hpa.Spec.MinReplicas
@@ -214,7 +225,7 @@ func (c *DeploymentController) ScaleFromZero(cd *flaggerv1.Canary) error {
 func (c *DeploymentController) GetMetadata(cd *flaggerv1.Canary) (string, string, map[string]int32, error) {
 	targetName := cd.Spec.TargetRef.Name
 
-	canaryDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canaryDep, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return "", "", nil, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
@@ -297,7 +308,7 @@ func (c *DeploymentController) createPrimaryDeployment(cd *flaggerv1.Canary, inc
 				},
 				Template: corev1.PodTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
-						Labels:      makePrimaryLabels(canaryDep.Spec.Template.Labels, primaryLabelValue, label),
+						Labels:      makeApexLabels(cd, makePrimaryLabels(canaryDep.Spec.Template.Labels, primaryLabelValue, label)),
 						Annotations: annotations,
 					},
 					// update spec with the primary secrets and config maps
@@ -340,6 +351,10 @@ func (c *DeploymentController) HaveDependenciesChanged(cd *flaggerv1.Canary) (bo
 // during a delete to attempt to revert the deployment back to the original state.  Error is returned if unable
 // update the reference deployment replicas to the primary replicas
 func (c *DeploymentController) Finalize(cd *flaggerv1.Canary) error {
+	// resume the rollout of the latest revision
+	if err := c.UnpinRevision(cd); err != nil {
+		return fmt.Errorf("UnpinRevision failed: %w", err)
+	}
 
 	// get ref deployment
 	refDep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
@@ -373,7 +388,7 @@ func (c *DeploymentController) Finalize(cd *flaggerv1.Canary) error {
 // Scale sets the canary deployment replicas
 func (c *DeploymentController) scale(cd *flaggerv1.Canary, replicas int32) error {
 	targetName := cd.Spec.TargetRef.Name
-	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	dep, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return fmt.Errorf("deployment %s.%s query error: %w", targetName, cd.Namespace, err)
 	}
@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// pinnedRevisionAnnotation marks a deployment paused by Flagger,
+// the value is the spec hash of the revision under analysis
+const pinnedRevisionAnnotation = "flagger.app/pinned-revision"
+
+// TargetRevision returns the spec hash of the canary deployment pod template
+func (c *DeploymentController) TargetRevision(cd *flaggerv1.Canary) (string, error) {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, targetName)
+	if err != nil {
+		return "", fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	return ComputeHash(templateSpec(dep.Spec.Template)), nil
+}
+
+// PinRevision pauses the canary deployment to keep its pods on the revision under analysis,
+// the deployment is only paused once the analysed revision has been rolled out
+func (c *DeploymentController) PinRevision(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	if dep.Spec.Paused || dep.Generation > dep.Status.ObservedGeneration ||
+		ComputeHash(templateSpec(dep.Spec.Template)) != cd.Status.LastAppliedSpec {
+		return nil
+	}
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}},"spec":{"paused":true}}`,
+		pinnedRevisionAnnotation, cd.Status.LastAppliedSpec))
+	_, err = c.kubeClient.AppsV1().Deployments(dep.Namespace).Patch(context.TODO(), dep.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s patch query error: %w", targetName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// UnpinRevision resumes the canary deployment if it was paused by PinRevision
+func (c *DeploymentController) UnpinRevision(cd *flaggerv1.Canary) error {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+
+	if _, ok := dep.Annotations[pinnedRevisionAnnotation]; !ok {
+		return nil
+	}
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}},"spec":{"paused":false}}`,
+		pinnedRevisionAnnotation))
+	_, err = c.kubeClient.AppsV1().Deployments(dep.Namespace).Patch(context.TODO(), dep.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s patch query error: %w", targetName, cd.Namespace, err)
+	}
+	return nil
+}
+
+// IsRevisionPinned returns true if the canary deployment is paused by PinRevision
+func (c *DeploymentController) IsRevisionPinned(cd *flaggerv1.Canary) (bool, error) {
+	targetName := cd.Spec.TargetRef.Name
+	dep, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, targetName)
+	if err != nil {
+		return false, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
+	}
+	return isPinned(dep), nil
+}
+
+func isPinned(dep *appsv1.Deployment) bool {
+	_, ok := dep.Annotations[pinnedRevisionAnnotation]
+	return ok && dep.Spec.Paused
+}
+
+// pinnedTemplate returns the pod template of the replica set running the pinned revision
+func (c *DeploymentController) pinnedTemplate(dep *appsv1.Deployment) (*corev1.PodTemplateSpec, error) {
+	selector, err := metav1.LabelSelectorAsSelector(dep.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("deployment %s.%s selector error: %w", dep.Name, dep.Namespace, err)
+	}
+
+	rsList, err := c.kubeClient.AppsV1().ReplicaSets(dep.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("replicasets %s list query error: %w", dep.Namespace, err)
+	}
+
+	revision := dep.Annotations[pinnedRevisionAnnotation]
+	for _, rs := range rsList.Items {
+		if !metav1.IsControlledBy(&rs, dep) {
+			continue
+		}
+
+		template := rs.Spec.Template.DeepCopy()
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		if ComputeHash(templateSpec(*template)) == revision {
+			return template, nil
+		}
+	}
+
+	return nil, fmt.Errorf("replicaset of deployment %s.%s with revision %s not found",
+		dep.Name, dep.Namespace, revision)
+}
+
+// isPinnedDeploymentReady checks the availability of a paused deployment,
+// the updated replicas are ignored as the pods run the pinned revision
+func (c *DeploymentController) isPinnedDeploymentReady(deployment *appsv1.Deployment, readyThreshold int) (bool, error) {
+	replicas := int32Default(deployment.Spec.Replicas)
+	readyThresholdRatio := float32(readyThreshold) / float32(100)
+	readyThresholdReplicas := int32(float32(replicas) * readyThresholdRatio)
+
+	if deployment.Status.AvailableReplicas < readyThresholdReplicas {
+		return true, fmt.Errorf("waiting for pinned revision: %d of %d (readyThreshold %d%%) replicas are available",
+			deployment.Status.AvailableReplicas, readyThresholdReplicas, readyThreshold)
+	}
+	return true, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestDeploymentController_PinRevision(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.UID = types.UID("podinfo-uid")
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// skip pinning a revision that isn't under analysis
+	mocks.canary.Status.LastAppliedSpec = "unknown"
+	err = mocks.controller.PinRevision(mocks.canary)
+	require.NoError(t, err)
+	pinned, err := mocks.controller.IsRevisionPinned(mocks.canary)
+	require.NoError(t, err)
+	assert.False(t, pinned)
+
+	mocks.canary.Status.LastAppliedSpec = ComputeHash(dep.Spec.Template)
+	err = mocks.controller.PinRevision(mocks.canary)
+	require.NoError(t, err)
+	pinned, err = mocks.controller.IsRevisionPinned(mocks.canary)
+	require.NoError(t, err)
+	assert.True(t, pinned)
+
+	// replica set running the pinned revision
+	rs := newPinnedReplicaSet(dep)
+	_, err = mocks.kubeClient.AppsV1().ReplicaSets("default").Create(context.TODO(), rs, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// apply a new revision while the deployment is paused
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Template = newDeploymentControllerTestV2().Spec.Template
+	dep.Status = appsv1.DeploymentStatus{Replicas: 1, AvailableReplicas: 1}
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	revision, err := mocks.controller.TargetRevision(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, ComputeHash(dep.Spec.Template), revision)
+
+	// the pinned pods are ready even if they don't run the latest template
+	_, err = mocks.controller.IsCanaryReady(mocks.canary)
+	require.NoError(t, err)
+
+	// promote the pinned revision
+	err = mocks.controller.Promote(mocks.canary)
+	require.NoError(t, err)
+
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/stefanprodan/podinfo:1.2.0", primary.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, "podinfo-primary", primary.Spec.Template.Labels["name"])
+	assert.NotContains(t, primary.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	assert.NotContains(t, primary.Annotations, pinnedRevisionAnnotation)
+
+	err = mocks.controller.UnpinRevision(mocks.canary)
+	require.NoError(t, err)
+
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, dep.Spec.Paused)
+	assert.NotContains(t, dep.Annotations, pinnedRevisionAnnotation)
+}
+
+func TestDeploymentController_UnpinRevisionSkipsUserPause(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Paused = true
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	pinned, err := mocks.controller.IsRevisionPinned(mocks.canary)
+	require.NoError(t, err)
+	assert.False(t, pinned)
+
+	err = mocks.controller.UnpinRevision(mocks.canary)
+	require.NoError(t, err)
+
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, dep.Spec.Paused)
+}
+
+func newPinnedReplicaSet(dep *appsv1.Deployment) *appsv1.ReplicaSet {
+	template := dep.Spec.Template.DeepCopy()
+	template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = "6f7b8c9d"
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: dep.Namespace,
+			Name:      dep.Name + "-6f7b8c9d",
+			Labels:    template.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(dep, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+			},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Selector: dep.Spec.Selector,
+			Template: *template,
+		},
+	}
+}
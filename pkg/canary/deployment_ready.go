@@ -17,12 +17,10 @@ limitations under the License.
 package canary
 
 import (
-	"context"
 	"fmt"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
@@ -32,7 +30,7 @@ import (
 // it will return a non retryable error if the rolling update is stuck
 func (c *DeploymentController) IsPrimaryReady(cd *flaggerv1.Canary) (bool, error) {
 	primaryName := fmt.Sprintf("%s-primary", cd.Spec.TargetRef.Name)
-	primary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	primary, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, primaryName)
 	if err != nil {
 		return true, fmt.Errorf("deployment %s.%s get query error: %w", primaryName, cd.Namespace, err)
 	}
@@ -54,11 +52,19 @@ func (c *DeploymentController) IsPrimaryReady(cd *flaggerv1.Canary) (bool, error
 // it will return a non retriable error if the rolling update is stuck
 func (c *DeploymentController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
-	canary, err := c.kubeClient.AppsV1().Deployments(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.listers.getDeployment(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return true, fmt.Errorf("deployment %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
 
+	if isPinned(canary) {
+		retryable, err := c.isPinnedDeploymentReady(canary, cd.GetAnalysisCanaryReadyThreshold())
+		if err != nil {
+			return retryable, fmt.Errorf("canary deployment %s.%s not ready: %w", targetName, cd.Namespace, err)
+		}
+		return true, nil
+	}
+
 	retryable, err := c.isDeploymentReady(canary, cd.GetProgressDeadlineSeconds(), cd.GetAnalysisCanaryReadyThreshold())
 	if err != nil {
 		return retryable, fmt.Errorf(
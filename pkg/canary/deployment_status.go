@@ -37,7 +37,7 @@ func (c *DeploymentController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1
 		return fmt.Errorf("GetConfigRefs failed: %w", err)
 	}
 
-	return syncCanaryStatus(c.flaggerClient, cd, status, dep.Spec.Template, func(cdCopy *flaggerv1.Canary) {
+	return syncCanaryStatus(c.flaggerClient, cd, status, templateSpec(dep.Spec.Template), func(cdCopy *flaggerv1.Canary) {
 		cdCopy.Status.TrackedConfigs = configs
 	})
 }
@@ -47,6 +47,11 @@ func (c *DeploymentController) SetStatusFailedChecks(cd *flaggerv1.Canary, val i
 	return setStatusFailedChecks(c.flaggerClient, cd, val)
 }
 
+// SetStatusTransientChecks updates the canary transient checks counter
+func (c *DeploymentController) SetStatusTransientChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusTransientChecks(c.flaggerClient, cd, val)
+}
+
 // SetStatusWeight updates the canary status weight value
 func (c *DeploymentController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
 	return setStatusWeight(c.flaggerClient, cd, val)
@@ -57,6 +62,21 @@ func (c *DeploymentController) SetStatusIterations(cd *flaggerv1.Canary, val int
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusStep updates the canary status analysis step and its iterations
+func (c *DeploymentController) SetStatusStep(cd *flaggerv1.Canary, step int, iterations int) error {
+	return setStatusStep(c.flaggerClient, cd, step, iterations)
+}
+
+// SetStatusQueuePosition updates the canary position in the progressing queue
+func (c *DeploymentController) SetStatusQueuePosition(cd *flaggerv1.Canary, position int) error {
+	return setStatusQueuePosition(c.flaggerClient, cd, position)
+}
+
+// SetStatusPendingRevision updates the revision waiting for the analysis to complete
+func (c *DeploymentController) SetStatusPendingRevision(cd *flaggerv1.Canary, revision string) error {
+	return setStatusPendingRevision(c.flaggerClient, cd, revision)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *DeploymentController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
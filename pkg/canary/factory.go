@@ -33,6 +33,7 @@ type Factory struct {
 	configTracker      Tracker
 	labels             []string
 	includeLabelPrefix []string
+	listers            *Listers
 }
 
 func NewFactory(kubeClient kubernetes.Interface,
@@ -41,6 +42,7 @@ func NewFactory(kubeClient kubernetes.Interface,
 	configTracker Tracker,
 	labels []string,
 	includeLabelPrefix []string,
+	listers *Listers,
 	logger *zap.SugaredLogger) *Factory {
 	return &Factory{
 		kubeClient:         kubeClient,
@@ -50,9 +52,15 @@ func NewFactory(kubeClient kubernetes.Interface,
 		configTracker:      configTracker,
 		labels:             labels,
 		includeLabelPrefix: includeLabelPrefix,
+		listers:            listers,
 	}
 }
 
+// Listers returns the informer caches shared by the canary controllers
+func (factory *Factory) Listers() *Listers {
+	return factory.listers
+}
+
 func (factory *Factory) Controller(obj v1beta1.LocalObjectReference) Controller {
 	deploymentCtrl := &DeploymentController{
 		logger:             factory.logger,
@@ -61,6 +69,7 @@ func (factory *Factory) Controller(obj v1beta1.LocalObjectReference) Controller
 		labels:             factory.labels,
 		configTracker:      factory.configTracker,
 		includeLabelPrefix: factory.includeLabelPrefix,
+		listers:            factory.listers,
 	}
 	daemonSetCtrl := &DaemonSetController{
 		logger:             factory.logger,
@@ -69,16 +78,19 @@ func (factory *Factory) Controller(obj v1beta1.LocalObjectReference) Controller
 		labels:             factory.labels,
 		configTracker:      factory.configTracker,
 		includeLabelPrefix: factory.includeLabelPrefix,
+		listers:            factory.listers,
 	}
 	serviceCtrl := &ServiceController{
 		logger:             factory.logger,
 		kubeClient:         factory.kubeClient,
 		flaggerClient:      factory.flaggerClient,
 		includeLabelPrefix: factory.includeLabelPrefix,
+		listers:            factory.listers,
 	}
 	knativeCtrl := &KnativeController{
 		flaggerClient: factory.flaggerClient,
 		knativeClient: factory.knativeClient,
+		listers:       factory.listers,
 	}
 
 	switch obj.Kind {
@@ -104,6 +116,7 @@ func (factory *Factory) ScalerReconciler(kind string) ScalerReconciler {
 		kubeClient:         factory.kubeClient,
 		flaggerClient:      factory.flaggerClient,
 		includeLabelPrefix: factory.includeLabelPrefix,
+		listers:            factory.listers,
 	}
 
 	soReconciler := &ScaledObjectReconciler{
@@ -22,6 +22,7 @@ type HPAReconciler struct {
 	flaggerClient      clientset.Interface
 	logger             *zap.SugaredLogger
 	includeLabelPrefix []string
+	listers            *Listers
 }
 
 func (hr *HPAReconciler) ReconcilePrimaryScaler(cd *flaggerv1.Canary, init bool) error {
@@ -34,7 +35,7 @@ func (hr *HPAReconciler) ReconcilePrimaryScaler(cd *flaggerv1.Canary, init bool)
 }
 
 func (hr *HPAReconciler) reconcilePrimaryHpa(cd *flaggerv1.Canary, init bool) error {
-	hpa, err := hr.kubeClient.AutoscalingV2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), cd.Spec.AutoscalerRef.Name, metav1.GetOptions{})
+	hpa, err := hr.listers.getHPA(hr.kubeClient, cd.Namespace, cd.Spec.AutoscalerRef.Name)
 	if err != nil {
 		hpa = nil
 		hr.logger.Debugf("v2 HorizontalPodAutoscaler %s.%s get query error: %s;",
@@ -77,7 +78,7 @@ func (hr *HPAReconciler) reconcilePrimaryHpaV2(cd *flaggerv1.Canary, hpa *hpav2.
 	}
 
 	primaryHpaName := fmt.Sprintf("%s-primary", cd.Spec.AutoscalerRef.Name)
-	primaryHpa, err := hr.kubeClient.AutoscalingV2().HorizontalPodAutoscalers(cd.Namespace).Get(context.TODO(), primaryHpaName, metav1.GetOptions{})
+	primaryHpa, err := hr.listers.getHPA(hr.kubeClient, cd.Namespace, primaryHpaName)
 
 	// create HPA
 	if errors.IsNotFound(err) {
@@ -87,6 +88,10 @@ func (hr *HPAReconciler) reconcilePrimaryHpaV2(cd *flaggerv1.Canary, hpa *hpav2.
 		}
 
 		_, err = hr.kubeClient.AutoscalingV2().HorizontalPodAutoscalers(cd.Namespace).Create(context.TODO(), primaryHpa, metav1.CreateOptions{})
+		// the informer cache may not have caught up with an HPA created in a previous run
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
 		if err != nil {
 			return fmt.Errorf("creating HorizontalPodAutoscaler v2 %s.%s failed: %w",
 				primaryHpa.Name, primaryHpa.Namespace, err)
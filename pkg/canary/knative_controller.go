@@ -16,9 +16,11 @@ type KnativeController struct {
 	flaggerClient clientset.Interface
 	knativeClient knative.Interface
 	logger        *zap.SugaredLogger
+	listers       *Listers
 }
 
-// IsPrimaryReady checks if the primary revision is ready
+// IsPrimaryReady checks if the primary revision is ready, the service is read from the API
+// as the primary revision annotation is set by Flagger in the same analysis run
 func (kc *KnativeController) IsPrimaryReady(cd *flaggerv1.Canary) (bool, error) {
 	service, err := kc.knativeClient.ServingV1().Services(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
 	if err != nil {
@@ -40,7 +42,7 @@ func (kc *KnativeController) IsPrimaryReady(cd *flaggerv1.Canary) (bool, error)
 
 // IsCanaryReady checks if the canary revision is ready
 func (kc *KnativeController) IsCanaryReady(cd *flaggerv1.Canary) (bool, error) {
-	service, err := kc.knativeClient.ServingV1().Services(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	service, err := kc.listers.GetKnativeService(kc.knativeClient, cd.Namespace, cd.Spec.TargetRef.Name)
 	if err != nil {
 		return true, fmt.Errorf("Knative Service %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
 	}
@@ -60,7 +62,7 @@ func (kc *KnativeController) GetMetadata(canary *flaggerv1.Canary) (string, stri
 
 // SyncStatus encodes list of revisions and updates the canary status
 func (kc *KnativeController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
-	service, err := kc.knativeClient.ServingV1().Services(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	service, err := kc.listers.GetKnativeService(kc.knativeClient, cd.Namespace, cd.Spec.TargetRef.Name)
 	if err != nil {
 		return fmt.Errorf("Knative Service %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
 	}
@@ -72,6 +74,11 @@ func (kc *KnativeController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int
 	return setStatusFailedChecks(kc.flaggerClient, cd, val)
 }
 
+// SetStatusTransientChecks updates the canary transient checks counter
+func (kc *KnativeController) SetStatusTransientChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusTransientChecks(kc.flaggerClient, cd, val)
+}
+
 // SetStatusWeight updates the canary status weight value
 func (kc *KnativeController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
 	return setStatusWeight(kc.flaggerClient, cd, val)
@@ -82,6 +89,21 @@ func (kc *KnativeController) SetStatusIterations(cd *flaggerv1.Canary, val int)
 	return setStatusIterations(kc.flaggerClient, cd, val)
 }
 
+// SetStatusStep updates the canary status analysis step and its iterations
+func (kc *KnativeController) SetStatusStep(cd *flaggerv1.Canary, step int, iterations int) error {
+	return setStatusStep(kc.flaggerClient, cd, step, iterations)
+}
+
+// SetStatusQueuePosition updates the canary position in the progressing queue
+func (kc *KnativeController) SetStatusQueuePosition(cd *flaggerv1.Canary, position int) error {
+	return setStatusQueuePosition(kc.flaggerClient, cd, position)
+}
+
+// SetStatusPendingRevision updates the revision waiting for the analysis to complete
+func (kc *KnativeController) SetStatusPendingRevision(cd *flaggerv1.Canary, revision string) error {
+	return setStatusPendingRevision(kc.flaggerClient, cd, revision)
+}
+
 // SetStatusPhase updates the canary status phase
 func (kc *KnativeController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(kc.flaggerClient, cd, phase)
@@ -137,7 +159,7 @@ func (kc *KnativeController) Promote(cd *flaggerv1.Canary) error {
 }
 
 func (kc *KnativeController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
-	service, err := kc.knativeClient.ServingV1().Services(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	service, err := kc.listers.GetKnativeService(kc.knativeClient, cd.Namespace, cd.Spec.TargetRef.Name)
 	if err != nil {
 		return true, fmt.Errorf("Knative Service %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
 	}
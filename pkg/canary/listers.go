@@ -0,0 +1,140 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	hpav2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	autoscalinglisters "k8s.io/client-go/listers/autoscaling/v2"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	serving "knative.dev/serving/pkg/apis/serving/v1"
+	knative "knative.dev/serving/pkg/client/clientset/versioned"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
+)
+
+// Listers serve the read-only queries of the canary analysis from the shared informer caches,
+// the queries are sent to the API server when a lister isn't set or its cache hasn't synced yet.
+// Objects that Flagger creates or updates right after reading them are still fetched from the API.
+// The routing objects of the mesh and ingress providers aren't cached, the routers read them from the API.
+type Listers struct {
+	Deployments       appslisters.DeploymentLister
+	DeploymentsSynced cache.InformerSynced
+	DaemonSets        appslisters.DaemonSetLister
+	DaemonSetsSynced  cache.InformerSynced
+	Services          corelisters.ServiceLister
+	ServicesSynced    cache.InformerSynced
+	HPAs              autoscalinglisters.HorizontalPodAutoscalerLister
+	HPAsSynced        cache.InformerSynced
+	ConfigMaps        corelisters.ConfigMapLister
+	ConfigMapsSynced  cache.InformerSynced
+	Secrets           corelisters.SecretLister
+	SecretsSynced     cache.InformerSynced
+
+	KnativeServices       servinglisters.ServiceLister
+	KnativeServicesSynced cache.InformerSynced
+}
+
+// getDeployment returns a copy of the deployment that the caller is free to modify
+func (l *Listers) getDeployment(kubeClient kubernetes.Interface, namespace string, name string) (*appsv1.Deployment, error) {
+	if l != nil && l.Deployments != nil && l.DeploymentsSynced != nil && l.DeploymentsSynced() {
+		dep, err := l.Deployments.Deployments(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return dep.DeepCopy(), nil
+	}
+	return kubeClient.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// getDaemonSet returns a copy of the daemonset that the caller is free to modify
+func (l *Listers) getDaemonSet(kubeClient kubernetes.Interface, namespace string, name string) (*appsv1.DaemonSet, error) {
+	if l != nil && l.DaemonSets != nil && l.DaemonSetsSynced != nil && l.DaemonSetsSynced() {
+		dae, err := l.DaemonSets.DaemonSets(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return dae.DeepCopy(), nil
+	}
+	return kubeClient.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// getService returns a copy of the service that the caller is free to modify
+func (l *Listers) getService(kubeClient kubernetes.Interface, namespace string, name string) (*corev1.Service, error) {
+	if l != nil && l.Services != nil && l.ServicesSynced != nil && l.ServicesSynced() {
+		svc, err := l.Services.Services(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return svc.DeepCopy(), nil
+	}
+	return kubeClient.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// getHPA returns a copy of the horizontal pod autoscaler that the caller is free to modify
+func (l *Listers) getHPA(kubeClient kubernetes.Interface, namespace string, name string) (*hpav2.HorizontalPodAutoscaler, error) {
+	if l != nil && l.HPAs != nil && l.HPAsSynced != nil && l.HPAsSynced() {
+		hpa, err := l.HPAs.HorizontalPodAutoscalers(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return hpa.DeepCopy(), nil
+	}
+	return kubeClient.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// getConfigMap returns a copy of the config map that the caller is free to modify
+func (l *Listers) getConfigMap(kubeClient kubernetes.Interface, namespace string, name string) (*corev1.ConfigMap, error) {
+	if l != nil && l.ConfigMaps != nil && l.ConfigMapsSynced != nil && l.ConfigMapsSynced() {
+		config, err := l.ConfigMaps.ConfigMaps(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return config.DeepCopy(), nil
+	}
+	return kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// getSecret returns a copy of the secret that the caller is free to modify
+func (l *Listers) getSecret(kubeClient kubernetes.Interface, namespace string, name string) (*corev1.Secret, error) {
+	if l != nil && l.Secrets != nil && l.SecretsSynced != nil && l.SecretsSynced() {
+		secret, err := l.Secrets.Secrets(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return secret.DeepCopy(), nil
+	}
+	return kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// GetKnativeService returns a copy of the Knative service that the caller is free to modify
+func (l *Listers) GetKnativeService(knativeClient knative.Interface, namespace string, name string) (*serving.Service, error) {
+	if l != nil && l.KnativeServices != nil && l.KnativeServicesSynced != nil && l.KnativeServicesSynced() {
+		service, err := l.KnativeServices.Services(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return service.DeepCopy(), nil
+	}
+	return knativeClient.ServingV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
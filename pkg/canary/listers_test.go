@@ -0,0 +1,138 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	fakeKnative "knative.dev/serving/pkg/client/clientset/versioned/fake"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1"
+)
+
+func TestListers_GetDeployment(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+
+	cached := newDeploymentControllerTest(dc)
+	cached.Labels = map[string]string{"cached": "true"}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, indexer.Add(cached))
+
+	synced := false
+	listers := &Listers{
+		Deployments:       appslisters.NewDeploymentLister(indexer),
+		DeploymentsSynced: func() bool { return synced },
+	}
+
+	// the cache hasn't synced, the deployment is fetched from the API
+	dep, err := listers.getDeployment(mocks.kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.Empty(t, dep.Labels["cached"])
+
+	synced = true
+	dep, err = listers.getDeployment(mocks.kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, "true", dep.Labels["cached"])
+
+	// the returned object is a copy
+	dep.Labels["cached"] = "false"
+	obj, _, err := indexer.GetByKey("default/podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, cached, obj)
+	assert.Equal(t, "true", cached.Labels["cached"])
+
+	// a nil lister falls back to the API
+	var nilListers *Listers
+	dep, err = nilListers.getDeployment(mocks.kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.Empty(t, dep.Labels["cached"])
+}
+
+func TestListers_GetService(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+	})
+
+	cached := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default", Labels: map[string]string{"cached": "true"}},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, indexer.Add(cached))
+
+	synced := false
+	listers := &Listers{
+		Services:       corelisters.NewServiceLister(indexer),
+		ServicesSynced: func() bool { return synced },
+	}
+
+	// the cache hasn't synced, the service is fetched from the API
+	svc, err := listers.getService(kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.Empty(t, svc.Labels["cached"])
+
+	synced = true
+	svc, err = listers.getService(kubeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, "true", svc.Labels["cached"])
+
+	// the returned object is a copy
+	svc.Labels["cached"] = "false"
+	assert.Equal(t, "true", cached.Labels["cached"])
+}
+
+func TestListers_GetKnativeService(t *testing.T) {
+	knativeClient := fakeKnative.NewSimpleClientset(newKnativeControllerTestService("podinfo"))
+
+	cached := newKnativeControllerTestService("podinfo")
+	cached.Status.LatestCreatedRevisionName = "podinfo-00002"
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, indexer.Add(cached))
+
+	synced := false
+	listers := &Listers{
+		KnativeServices:       servinglisters.NewServiceLister(indexer),
+		KnativeServicesSynced: func() bool { return synced },
+	}
+
+	// the cache hasn't synced, the service is fetched from the API
+	service, err := listers.GetKnativeService(knativeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-00001", service.Status.LatestCreatedRevisionName)
+
+	synced = true
+	service, err = listers.GetKnativeService(knativeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-00002", service.Status.LatestCreatedRevisionName)
+
+	// the returned object is a copy
+	service.Status.LatestCreatedRevisionName = "podinfo-00003"
+	assert.Equal(t, "podinfo-00002", cached.Status.LatestCreatedRevisionName)
+
+	// a nil lister falls back to the API
+	var nilListers *Listers
+	service, err = nilListers.GetKnativeService(knativeClient, "default", "podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo-00001", service.Status.LatestCreatedRevisionName)
+}
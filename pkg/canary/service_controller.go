@@ -38,6 +38,7 @@ type ServiceController struct {
 	flaggerClient      clientset.Interface
 	logger             *zap.SugaredLogger
 	includeLabelPrefix []string
+	listers            *Listers
 }
 
 // SetStatusFailedChecks updates the canary failed checks counter
@@ -45,6 +46,11 @@ func (c *ServiceController) SetStatusFailedChecks(cd *flaggerv1.Canary, val int)
 	return setStatusFailedChecks(c.flaggerClient, cd, val)
 }
 
+// SetStatusTransientChecks updates the canary transient checks counter
+func (c *ServiceController) SetStatusTransientChecks(cd *flaggerv1.Canary, val int) error {
+	return setStatusTransientChecks(c.flaggerClient, cd, val)
+}
+
 // SetStatusWeight updates the canary status weight value
 func (c *ServiceController) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
 	return setStatusWeight(c.flaggerClient, cd, val)
@@ -55,6 +61,21 @@ func (c *ServiceController) SetStatusIterations(cd *flaggerv1.Canary, val int) e
 	return setStatusIterations(c.flaggerClient, cd, val)
 }
 
+// SetStatusStep updates the canary status analysis step and its iterations
+func (c *ServiceController) SetStatusStep(cd *flaggerv1.Canary, step int, iterations int) error {
+	return setStatusStep(c.flaggerClient, cd, step, iterations)
+}
+
+// SetStatusQueuePosition updates the canary position in the progressing queue
+func (c *ServiceController) SetStatusQueuePosition(cd *flaggerv1.Canary, position int) error {
+	return setStatusQueuePosition(c.flaggerClient, cd, position)
+}
+
+// SetStatusPendingRevision updates the revision waiting for the analysis to complete
+func (c *ServiceController) SetStatusPendingRevision(cd *flaggerv1.Canary, revision string) error {
+	return setStatusPendingRevision(c.flaggerClient, cd, revision)
+}
+
 // SetStatusPhase updates the canary status phase
 func (c *ServiceController) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	return setStatusPhase(c.flaggerClient, cd, phase)
@@ -225,7 +246,7 @@ func (c *ServiceController) Promote(cd *flaggerv1.Canary) error {
 // HasServiceChanged returns true if the canary service spec has changed
 func (c *ServiceController) HasTargetChanged(cd *flaggerv1.Canary) (bool, error) {
 	targetName := cd.Spec.TargetRef.Name
-	canary, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	canary, err := c.listers.getService(c.kubeClient, cd.Namespace, targetName)
 	if err != nil {
 		return false, fmt.Errorf("service %s.%s get query error: %w", targetName, cd.Namespace, err)
 	}
@@ -242,7 +263,7 @@ func (c *ServiceController) ScaleFromZero(_ *flaggerv1.Canary) error {
 }
 
 func (c *ServiceController) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
-	dep, err := c.kubeClient.CoreV1().Services(cd.Namespace).Get(context.TODO(), cd.Spec.TargetRef.Name, metav1.GetOptions{})
+	dep, err := c.listers.getService(c.kubeClient, cd.Namespace, cd.Spec.TargetRef.Name)
 	if err != nil {
 		return fmt.Errorf("service %s.%s get query error: %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
 	}
@@ -21,6 +21,7 @@ import (
 	"hash/fnv"
 
 	"github.com/davecgh/go-spew/spew"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -48,6 +49,17 @@ func hasSpecChanged(cd *flaggerv1.Canary, spec interface{}) (bool, error) {
 	return false, nil
 }
 
+// templateSpec returns the pod template without the labels injected by Flagger,
+// so that injecting them doesn't count as a new revision
+func templateSpec(template corev1.PodTemplateSpec) corev1.PodTemplateSpec {
+	if _, ok := template.Labels[flaggerv1.ApexLabel]; !ok {
+		return template
+	}
+	res := *template.DeepCopy()
+	delete(res.Labels, flaggerv1.ApexLabel)
+	return res
+}
+
 // ComputeHash returns a hash value calculated from a spec using the spew library
 // which follows pointers and prints actual values of the nested objects
 // ensuring the hash does not change when a pointer changes.
@@ -46,8 +46,18 @@ func syncCanaryStatus(flaggerClient clientset.Interface, cd *flaggerv1.Canary, s
 		cdCopy.Status.Phase = status.Phase
 		cdCopy.Status.CanaryWeight = status.CanaryWeight
 		cdCopy.Status.FailedChecks = status.FailedChecks
+		cdCopy.Status.TransientChecks = status.TransientChecks
 		cdCopy.Status.Iterations = status.Iterations
-		cdCopy.Status.LastAppliedSpec = hash
+		cdCopy.Status.CurrentStep = status.CurrentStep
+		cdCopy.Status.StepIterations = status.StepIterations
+		cdCopy.Status.StepStartTime = status.StepStartTime
+		cdCopy.Status.QueuePosition = status.QueuePosition
+		cdCopy.Status.PendingRevision = status.PendingRevision
+		// a failed analysis leaves the pending revision unapplied so that it gets analysed next
+		if status.Phase != flaggerv1.CanaryPhaseFailed || status.PendingRevision != hash ||
+			cd.GetAnalysisRevisionPolicy() != flaggerv1.RevisionPolicyFinish {
+			cdCopy.Status.LastAppliedSpec = hash
+		}
 		if status.Phase == flaggerv1.CanaryPhaseInitialized {
 			cdCopy.Status.LastPromotedSpec = hash
 		}
@@ -93,6 +103,30 @@ func setStatusFailedChecks(flaggerClient clientset.Interface, cd *flaggerv1.Cana
 	return nil
 }
 
+func setStatusTransientChecks(flaggerClient clientset.Interface, cd *flaggerv1.Canary, val int) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.TransientChecks = val
+		cdCopy.Status.LastTransitionTime = metav1.Now()
+
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
 func setStatusWeight(flaggerClient clientset.Interface, cd *flaggerv1.Canary, val int) error {
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
@@ -143,6 +177,95 @@ func setStatusIterations(flaggerClient clientset.Interface, cd *flaggerv1.Canary
 	return nil
 }
 
+func setStatusStep(flaggerClient clientset.Interface, cd *flaggerv1.Canary, step int, iterations int) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		applyStatusStep(cdCopy, step, iterations)
+		cdCopy.Status.LastTransitionTime = metav1.Now()
+
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+// applyStatusStep records the current step and its iterations,
+// restarting the bake time when the canary enters a new step
+func applyStatusStep(cd *flaggerv1.Canary, step int, iterations int) {
+	if cd.Status.StepStartTime == nil || cd.Status.CurrentStep != step {
+		now := metav1.Now()
+		cd.Status.StepStartTime = &now
+	}
+	cd.Status.CurrentStep = step
+	cd.Status.StepIterations = iterations
+}
+
+func setStatusQueuePosition(flaggerClient clientset.Interface, cd *flaggerv1.Canary, position int) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.QueuePosition = position
+		cdCopy.Status.LastTransitionTime = metav1.Now()
+
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
+func setStatusPendingRevision(flaggerClient clientset.Interface, cd *flaggerv1.Canary, revision string) error {
+	firstTry := true
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		cdCopy.Status.PendingRevision = revision
+		cdCopy.Status.LastTransitionTime = metav1.Now()
+
+		err = updateStatusWithUpgrade(flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	return nil
+}
+
 func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
 	firstTry := true
 	name, ns := cd.GetName(), cd.GetNamespace()
@@ -157,10 +280,17 @@ func setStatusPhase(flaggerClient clientset.Interface, cd *flaggerv1.Canary, pha
 		cdCopy := cd.DeepCopy()
 		cdCopy.Status.Phase = phase
 		cdCopy.Status.LastTransitionTime = metav1.Now()
+		if phase != flaggerv1.CanaryPhaseWaiting {
+			cdCopy.Status.QueuePosition = 0
+		}
 
 		if phase != flaggerv1.CanaryPhaseProgressing && phase != flaggerv1.CanaryPhaseWaiting && phase != flaggerv1.CanaryPhasePromoting {
 			cdCopy.Status.CanaryWeight = 0
 			cdCopy.Status.Iterations = 0
+			cdCopy.Status.TransientChecks = 0
+			cdCopy.Status.CurrentStep = 0
+			cdCopy.Status.StepIterations = 0
+			cdCopy.Status.StepStartTime = nil
 			if phase == flaggerv1.CanaryPhaseWaitingPromotion {
 				cdCopy.Status.Iterations = cd.GetAnalysis().Iterations - 1
 			}
@@ -213,6 +343,9 @@ func MakeStatusConditions(cd *flaggerv1.Canary,
 	case flaggerv1.CanaryPhaseWaiting:
 		status = corev1.ConditionUnknown
 		message = "Waiting for approval."
+		if cd.Status.QueuePosition > 0 {
+			message = fmt.Sprintf("Waiting for a progressing slot, queue position %d.", cd.Status.QueuePosition)
+		}
 	case flaggerv1.CanaryPhaseWaitingPromotion:
 		status = corev1.ConditionUnknown
 		message = "Waiting for approval."
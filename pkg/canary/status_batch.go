@@ -0,0 +1,185 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// StatusBatch wraps a canary controller and buffers the weight, iterations, failed checks, step and queue
+// position status updates made during one analysis run, the buffered values are written with a single
+// status update when Flush is called or before a phase or a full status sync is written
+type StatusBatch struct {
+	Controller
+	flaggerClient clientset.Interface
+	canary        *flaggerv1.Canary
+	pending       []func(cdCopy *flaggerv1.Canary)
+	written       bool
+}
+
+// NewStatusBatch returns a StatusBatch that buffers the status updates of the given canary
+func NewStatusBatch(ctrl Controller, flaggerClient clientset.Interface, cd *flaggerv1.Canary) *StatusBatch {
+	return &StatusBatch{
+		Controller:    ctrl,
+		flaggerClient: flaggerClient,
+		canary:        cd,
+	}
+}
+
+// Written returns true if the canary status has been updated through the batch
+func (b *StatusBatch) Written() bool {
+	return b.written
+}
+
+func (b *StatusBatch) SetStatusFailedChecks(cd *flaggerv1.Canary, val int) error {
+	if !b.owns(cd) {
+		return b.Controller.SetStatusFailedChecks(cd, val)
+	}
+	b.pending = append(b.pending, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.FailedChecks = val
+	})
+	return nil
+}
+
+func (b *StatusBatch) SetStatusTransientChecks(cd *flaggerv1.Canary, val int) error {
+	if !b.owns(cd) {
+		return b.Controller.SetStatusTransientChecks(cd, val)
+	}
+	b.pending = append(b.pending, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.TransientChecks = val
+	})
+	return nil
+}
+
+func (b *StatusBatch) SetStatusWeight(cd *flaggerv1.Canary, val int) error {
+	if !b.owns(cd) {
+		return b.Controller.SetStatusWeight(cd, val)
+	}
+	b.pending = append(b.pending, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.CanaryWeight = val
+	})
+	return nil
+}
+
+func (b *StatusBatch) SetStatusIterations(cd *flaggerv1.Canary, val int) error {
+	if !b.owns(cd) {
+		return b.Controller.SetStatusIterations(cd, val)
+	}
+	b.pending = append(b.pending, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.Iterations = val
+	})
+	return nil
+}
+
+func (b *StatusBatch) SetStatusStep(cd *flaggerv1.Canary, step int, iterations int) error {
+	if !b.owns(cd) {
+		return b.Controller.SetStatusStep(cd, step, iterations)
+	}
+	b.pending = append(b.pending, func(cdCopy *flaggerv1.Canary) {
+		applyStatusStep(cdCopy, step, iterations)
+	})
+	return nil
+}
+
+func (b *StatusBatch) SetStatusQueuePosition(cd *flaggerv1.Canary, position int) error {
+	if !b.owns(cd) {
+		return b.Controller.SetStatusQueuePosition(cd, position)
+	}
+	b.pending = append(b.pending, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.QueuePosition = position
+	})
+	return nil
+}
+
+func (b *StatusBatch) SetStatusPendingRevision(cd *flaggerv1.Canary, revision string) error {
+	if !b.owns(cd) {
+		return b.Controller.SetStatusPendingRevision(cd, revision)
+	}
+	b.pending = append(b.pending, func(cdCopy *flaggerv1.Canary) {
+		cdCopy.Status.PendingRevision = revision
+	})
+	return nil
+}
+
+func (b *StatusBatch) SetStatusPhase(cd *flaggerv1.Canary, phase flaggerv1.CanaryPhase) error {
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	if b.owns(cd) {
+		b.written = true
+	}
+	return b.Controller.SetStatusPhase(cd, phase)
+}
+
+func (b *StatusBatch) SyncStatus(cd *flaggerv1.Canary, status flaggerv1.CanaryStatus) error {
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	if b.owns(cd) {
+		b.written = true
+	}
+	return b.Controller.SyncStatus(cd, status)
+}
+
+// Flush writes the buffered status fields with a single status update,
+// on conflict the canary is fetched from the API and the fields are applied again
+func (b *StatusBatch) Flush() error {
+	if len(b.pending) == 0 {
+		return nil
+	}
+	pending := b.pending
+	b.pending = nil
+
+	firstTry := true
+	cd := b.canary
+	name, ns := cd.GetName(), cd.GetNamespace()
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
+		if !firstTry {
+			cd, err = b.flaggerClient.FlaggerV1beta1().Canaries(ns).Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("canary %s.%s get query failed: %w", name, ns, err)
+			}
+		}
+
+		cdCopy := cd.DeepCopy()
+		for _, set := range pending {
+			set(cdCopy)
+		}
+		cdCopy.Status.LastTransitionTime = metav1.Now()
+
+		err = updateStatusWithUpgrade(b.flaggerClient, cdCopy)
+		firstTry = false
+		return
+	})
+	if err != nil {
+		return fmt.Errorf("failed after retries: %w", err)
+	}
+	b.written = true
+	return nil
+}
+
+// owns returns true if the status update targets the canary of this batch
+func (b *StatusBatch) owns(cd *flaggerv1.Canary) bool {
+	return cd != nil && cd.GetName() == b.canary.GetName() && cd.GetNamespace() == b.canary.GetNamespace()
+}
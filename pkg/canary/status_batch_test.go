@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
+)
+
+func TestStatusBatch_Flush(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	flaggerClient := mocks.flaggerClient.(*fakeFlagger.Clientset)
+	flaggerClient.ClearActions()
+
+	batch := NewStatusBatch(&mocks.controller, mocks.flaggerClient, mocks.canary)
+	require.NoError(t, batch.SetStatusWeight(mocks.canary, 10))
+	require.NoError(t, batch.SetStatusIterations(mocks.canary, 2))
+	require.NoError(t, batch.SetStatusFailedChecks(mocks.canary, 1))
+	require.NoError(t, batch.SetStatusStep(mocks.canary, 1, 0))
+	assert.Empty(t, flaggerClient.Actions())
+	assert.False(t, batch.Written())
+
+	require.NoError(t, batch.Flush())
+	assert.True(t, batch.Written())
+
+	updates := 0
+	for _, action := range flaggerClient.Actions() {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			updates++
+		}
+	}
+	assert.Equal(t, 1, updates)
+
+	res, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 10, res.Status.CanaryWeight)
+	assert.Equal(t, 2, res.Status.Iterations)
+	assert.Equal(t, 1, res.Status.FailedChecks)
+	assert.Equal(t, 1, res.Status.CurrentStep)
+	assert.NotNil(t, res.Status.StepStartTime)
+
+	// the in-memory canary is left untouched
+	assert.Equal(t, 0, mocks.canary.Status.CanaryWeight)
+
+	// nothing left to write
+	flaggerClient.ClearActions()
+	require.NoError(t, batch.Flush())
+	assert.Empty(t, flaggerClient.Actions())
+}
+
+func TestStatusBatch_FlushBeforePhase(t *testing.T) {
+	dc := deploymentConfigs{name: "podinfo", label: "name", labelValue: "podinfo"}
+	mocks := newDeploymentFixture(dc)
+	mocks.initializeCanary(t)
+
+	batch := NewStatusBatch(&mocks.controller, mocks.flaggerClient, mocks.canary)
+	require.NoError(t, batch.SetStatusWeight(mocks.canary, 50))
+	require.NoError(t, batch.SetStatusPhase(mocks.canary, flaggerv1.CanaryPhaseSucceeded))
+	require.NoError(t, batch.Flush())
+
+	res, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, res.Status.Phase)
+	assert.Equal(t, 0, res.Status.CanaryWeight)
+}
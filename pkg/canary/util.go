@@ -140,6 +140,15 @@ func makePrimaryLabels(labels map[string]string, labelValue string, label string
 	return res
 }
 
+// makeApexLabels adds the apex label to the pod labels when the canary traffic is shifted with replica ratios
+func makeApexLabels(cd *flaggerv1.Canary, labels map[string]string) map[string]string {
+	if cd.UsesReplicaRatio() {
+		apexName, _, _ := cd.GetServiceNames()
+		labels[flaggerv1.ApexLabel] = apexName
+	}
+	return labels
+}
+
 func int32p(i int32) *int32 {
 	return &i
 }
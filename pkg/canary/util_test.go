@@ -20,6 +20,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
 func TestIncludeLabelsByPrefix(t *testing.T) {
@@ -82,3 +86,29 @@ func TestMakePrimaryLabels(t *testing.T) {
 		"foo":   "new-bar", // overriden value for a specific label
 	})
 }
+
+func TestMakeApexLabels(t *testing.T) {
+	cd := &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo"},
+		Spec: flaggerv1.CanarySpec{
+			TargetRef: flaggerv1.LocalObjectReference{Name: "podinfo"},
+			Analysis:  &flaggerv1.CanaryAnalysis{},
+		},
+	}
+	assert.Equal(t, map[string]string{"app": "podinfo-primary"}, makeApexLabels(cd, map[string]string{"app": "podinfo-primary"}))
+
+	cd.Spec.Analysis.ReplicaRatio = true
+	assert.Equal(t, map[string]string{"app": "podinfo-primary", flaggerv1.ApexLabel: "podinfo"},
+		makeApexLabels(cd, map[string]string{"app": "podinfo-primary"}))
+}
+
+func TestTemplateSpec_IgnoresApexLabel(t *testing.T) {
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "podinfo"}},
+	}
+	labeled := *template.DeepCopy()
+	labeled.Labels[flaggerv1.ApexLabel] = "podinfo"
+
+	assert.Equal(t, ComputeHash(templateSpec(template)), ComputeHash(templateSpec(labeled)))
+	assert.Equal(t, "podinfo", labeled.Labels[flaggerv1.ApexLabel])
+}
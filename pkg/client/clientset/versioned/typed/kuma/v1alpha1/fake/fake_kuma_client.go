@@ -28,6 +28,10 @@ type FakeKumaV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeKumaV1alpha1) MeshHTTPRoutes(namespace string) v1alpha1.MeshHTTPRouteInterface {
+	return newFakeMeshHTTPRoutes(c, namespace)
+}
+
 func (c *FakeKumaV1alpha1) TrafficRoutes() v1alpha1.TrafficRouteInterface {
 	return newFakeTrafficRoutes(c)
 }
@@ -28,6 +28,10 @@ type FakeTraefikV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeTraefikV1alpha1) IngressRoutes(namespace string) v1alpha1.IngressRouteInterface {
+	return newFakeIngressRoutes(c, namespace)
+}
+
 func (c *FakeTraefikV1alpha1) TraefikServices(namespace string) v1alpha1.TraefikServiceInterface {
 	return newFakeTraefikServices(c, namespace)
 }
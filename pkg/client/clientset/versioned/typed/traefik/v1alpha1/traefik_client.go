@@ -28,6 +28,7 @@ import (
 
 type TraefikV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	IngressRoutesGetter
 	TraefikServicesGetter
 }
 
@@ -36,6 +37,10 @@ type TraefikV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *TraefikV1alpha1Client) IngressRoutes(namespace string) IngressRouteInterface {
+	return newIngressRoutes(c, namespace)
+}
+
 func (c *TraefikV1alpha1Client) TraefikServices(namespace string) TraefikServiceInterface {
 	return newTraefikServices(c, namespace)
 }
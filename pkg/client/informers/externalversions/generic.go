@@ -116,6 +116,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Keda().V1alpha1().ScaledObjects().Informer()}, nil
 
 		// Group=kuma.io, Version=v1alpha1
+	case kumav1alpha1.SchemeGroupVersion.WithResource("meshhttproutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Kuma().V1alpha1().MeshHTTPRoutes().Informer()}, nil
 	case kumav1alpha1.SchemeGroupVersion.WithResource("trafficroutes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Kuma().V1alpha1().TrafficRoutes().Informer()}, nil
 
@@ -142,6 +144,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Split().V1alpha3().TrafficSplits().Informer()}, nil
 
 		// Group=traefik.io, Version=v1alpha1
+	case traefikv1alpha1.SchemeGroupVersion.WithResource("ingressroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Traefik().V1alpha1().IngressRoutes().Informer()}, nil
 	case traefikv1alpha1.SchemeGroupVersion.WithResource("traefikservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Traefik().V1alpha1().TraefikServices().Informer()}, nil
 
@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// IngressRoutes returns a IngressRouteInformer.
+	IngressRoutes() IngressRouteInformer
 	// TraefikServices returns a TraefikServiceInformer.
 	TraefikServices() TraefikServiceInformer
 }
@@ -39,6 +41,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// IngressRoutes returns a IngressRouteInformer.
+func (v *version) IngressRoutes() IngressRouteInformer {
+	return &ingressRouteInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // TraefikServices returns a TraefikServiceInformer.
 func (v *version) TraefikServices() TraefikServiceInformer {
 	return &traefikServiceInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1alpha1
 
+// MeshHTTPRouteListerExpansion allows custom methods to be added to
+// MeshHTTPRouteLister.
+type MeshHTTPRouteListerExpansion interface{}
+
+// MeshHTTPRouteNamespaceListerExpansion allows custom methods to be added to
+// MeshHTTPRouteNamespaceLister.
+type MeshHTTPRouteNamespaceListerExpansion interface{}
+
 // TrafficRouteListerExpansion allows custom methods to be added to
 // TrafficRouteLister.
 type TrafficRouteListerExpansion interface{}
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1alpha1
 
+// IngressRouteListerExpansion allows custom methods to be added to
+// IngressRouteLister.
+type IngressRouteListerExpansion interface{}
+
+// IngressRouteNamespaceListerExpansion allows custom methods to be added to
+// IngressRouteNamespaceLister.
+type IngressRouteNamespaceListerExpansion interface{}
+
 // TraefikServiceListerExpansion allows custom methods to be added to
 // TraefikServiceLister.
 type TraefikServiceListerExpansion interface{}
@@ -0,0 +1,197 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// analysisJitterFactor spreads the analysis runs of the canaries that share the same interval
+const analysisJitterFactor = 0.1
+
+const (
+	// analysisBackoffBase is the delay after the first failed analysis run of a canary
+	analysisBackoffBase = 5 * time.Second
+	// analysisBackoffMax caps the delay between the failed analysis runs of a canary
+	analysisBackoffMax = 5 * time.Minute
+)
+
+// analysisQueue schedules the canary analysis runs on a delaying workqueue.
+// The canaries that are due are handed out round-robin across namespaces,
+// so that a namespace with many canaries can't starve the others.
+// A canary key is never handed out twice at the same time, a key added
+// while its analysis is running is handed out again once it's done.
+// A canary that failed its last run is backed off exponentially, the keys
+// added before its backoff has passed are delayed until then.
+type analysisQueue struct {
+	delaying workqueue.DelayingInterface
+	limiter  workqueue.RateLimiter
+
+	mu         sync.Mutex
+	cond       *sync.Cond
+	namespaces []string
+	pending    map[string][]string
+	queued     map[string]bool
+	active     map[string]bool
+	requeue    map[string]bool
+	notBefore  map[string]time.Time
+	shutdown   bool
+}
+
+func newAnalysisQueue(name string) *analysisQueue {
+	q := &analysisQueue{
+		delaying:  workqueue.NewNamedDelayingQueue(name),
+		limiter:   workqueue.NewItemExponentialFailureRateLimiter(analysisBackoffBase, analysisBackoffMax),
+		pending:   make(map[string][]string),
+		queued:    make(map[string]bool),
+		active:    make(map[string]bool),
+		requeue:   make(map[string]bool),
+		notBefore: make(map[string]time.Time),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	go q.dispatch()
+	return q
+}
+
+// dispatch moves the keys from the delaying queue to the namespace queues once their delay has passed
+func (q *analysisQueue) dispatch() {
+	for {
+		item, shutdown := q.delaying.Get()
+		if shutdown {
+			return
+		}
+		q.Add(item.(string))
+		q.delaying.Done(item)
+	}
+}
+
+// Add makes the canary key available to the workers immediately,
+// or once its backoff has passed if the last analysis run failed
+func (q *analysisQueue) Add(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.shutdown || q.queued[key] {
+		return
+	}
+	if q.active[key] {
+		q.requeue[key] = true
+		return
+	}
+	if wait := time.Until(q.notBefore[key]); wait > 0 {
+		q.delaying.AddAfter(key, wait)
+		return
+	}
+
+	namespace, _, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+	if len(q.pending[namespace]) == 0 {
+		q.namespaces = append(q.namespaces, namespace)
+	}
+	q.pending[namespace] = append(q.pending[namespace], key)
+	q.queued[key] = true
+	q.cond.Signal()
+}
+
+// AddAfter makes the canary key available to the workers after the given delay,
+// if the key is already waiting the earliest of the two delays is kept
+func (q *analysisQueue) AddAfter(key string, delay time.Duration) {
+	q.delaying.AddAfter(key, delay)
+}
+
+// Get blocks until a canary key is available, the keys are taken from each namespace in turn
+func (q *analysisQueue) Get() (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.namespaces) == 0 && !q.shutdown {
+		q.cond.Wait()
+	}
+	if len(q.namespaces) == 0 {
+		return "", true
+	}
+
+	namespace := q.namespaces[0]
+	q.namespaces = q.namespaces[1:]
+	keys := q.pending[namespace]
+	key := keys[0]
+	if len(keys) > 1 {
+		q.pending[namespace] = keys[1:]
+		q.namespaces = append(q.namespaces, namespace)
+	} else {
+		delete(q.pending, namespace)
+	}
+
+	delete(q.queued, key)
+	q.active[key] = true
+	return key, false
+}
+
+// Done marks the analysis of the canary key as finished
+func (q *analysisQueue) Done(key string) {
+	q.mu.Lock()
+	delete(q.active, key)
+	requeue := q.requeue[key]
+	delete(q.requeue, key)
+	q.mu.Unlock()
+
+	if requeue {
+		q.Add(key)
+	}
+}
+
+// Failed backs off the canary key after a failed analysis run and returns the backoff delay
+func (q *analysisQueue) Failed(key string) time.Duration {
+	delay := q.limiter.When(key)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.notBefore[key] = time.Now().Add(delay)
+	return delay
+}
+
+// Forget resets the backoff of the canary key after a successful analysis run
+func (q *analysisQueue) Forget(key string) {
+	q.limiter.Forget(key)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.notBefore, key)
+}
+
+// Len returns the number of canary keys waiting for a worker
+func (q *analysisQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.queued)
+}
+
+// ShutDown stops the dispatching of canary keys and releases the workers
+func (q *analysisQueue) ShutDown() {
+	q.delaying.ShutDown()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.shutdown = true
+	q.cond.Broadcast()
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAnalysisQueue_NamespaceFairness(t *testing.T) {
+	q := newAnalysisQueue("test")
+	defer q.ShutDown()
+
+	for _, key := range []string{"ns1/a", "ns1/b", "ns1/c", "ns2/x"} {
+		q.Add(key)
+	}
+
+	var order []string
+	for i := 0; i < 4; i++ {
+		key, shutdown := q.Get()
+		require.False(t, shutdown)
+		order = append(order, key)
+		q.Done(key)
+	}
+	assert.Equal(t, []string{"ns1/a", "ns2/x", "ns1/b", "ns1/c"}, order)
+}
+
+func TestAnalysisQueue_Requeue(t *testing.T) {
+	q := newAnalysisQueue("test")
+	defer q.ShutDown()
+
+	q.Add("default/podinfo")
+	q.Add("default/podinfo")
+	assert.Equal(t, 1, q.Len())
+
+	key, _ := q.Get()
+	assert.Equal(t, "default/podinfo", key)
+
+	// adding a key while its analysis is running defers it until done
+	q.Add(key)
+	assert.Equal(t, 0, q.Len())
+
+	q.Done(key)
+	assert.Equal(t, 1, q.Len())
+}
+
+func TestAnalysisQueue_AddAfter(t *testing.T) {
+	q := newAnalysisQueue("test")
+	defer q.ShutDown()
+
+	q.AddAfter("default/podinfo", 10*time.Millisecond)
+	assert.Equal(t, 0, q.Len())
+
+	got := make(chan string)
+	go func() {
+		key, _ := q.Get()
+		got <- key
+	}()
+
+	select {
+	case key := <-got:
+		assert.Equal(t, "default/podinfo", key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the delayed key")
+	}
+}
+
+func TestAnalysisQueue_Backoff(t *testing.T) {
+	q := newAnalysisQueue("test")
+	defer q.ShutDown()
+
+	assert.Equal(t, analysisBackoffBase, q.Failed("default/podinfo"))
+	assert.Equal(t, 2*analysisBackoffBase, q.Failed("default/podinfo"))
+
+	// a failing canary is not handed out before its backoff has passed
+	q.Add("default/podinfo")
+	assert.Equal(t, 0, q.Len())
+
+	q.Forget("default/podinfo")
+	q.Add("default/podinfo")
+	assert.Equal(t, 1, q.Len())
+	assert.Equal(t, analysisBackoffBase, q.Failed("default/podinfo"))
+}
+
+func TestScheduler_EnqueueTargetCanaries(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// canaries that are not synced yet are left to the sync handler
+	mocks.ctrl.enqueueTargetCanaries("Deployment", "default", "podinfo")
+	assert.Equal(t, 0, mocks.ctrl.analysisQueue.Len())
+
+	mocks.ctrl.canaries.Store("podinfo.default", mocks.canary)
+	mocks.ctrl.enqueueTargetCanaries("DaemonSet", "default", "podinfo")
+	mocks.ctrl.enqueueTargetCanaries("Deployment", "default", "other")
+	assert.Equal(t, 0, mocks.ctrl.analysisQueue.Len())
+
+	mocks.ctrl.enqueueTargetCanaries("Deployment", "default", "podinfo")
+	assert.Equal(t, 1, mocks.ctrl.analysisQueue.Len())
+}
+
+func TestScheduler_ProcessNextAnalysis(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.canaries.Store("podinfo.default", mocks.canary)
+
+	mocks.ctrl.analysisQueue.Add("default/podinfo")
+	assert.True(t, mocks.ctrl.processNextAnalysis())
+
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// the next run waits for the analysis interval
+	assert.Equal(t, 0, mocks.ctrl.analysisQueue.Len())
+
+	// deleted canaries are not analysed
+	mocks.ctrl.canaries.Delete("podinfo.default")
+	require.NoError(t, mocks.kubeClient.AppsV1().Deployments("default").Delete(context.TODO(), "podinfo-primary", metav1.DeleteOptions{}))
+	mocks.ctrl.analysisQueue.Add("default/podinfo")
+	assert.True(t, mocks.ctrl.processNextAnalysis())
+
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+}
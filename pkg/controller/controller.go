@@ -25,10 +25,16 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
+	appsinformers "k8s.io/client-go/informers/apps/v1"
+	autoscalinginformers "k8s.io/client-go/informers/autoscaling/v2"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -43,9 +49,12 @@ import (
 	flaggerinformers "github.com/fluxcd/flagger/pkg/client/informers/externalversions/flagger/v1beta1"
 	"github.com/fluxcd/flagger/pkg/metrics"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
 	"github.com/fluxcd/flagger/pkg/notifier"
 	"github.com/fluxcd/flagger/pkg/router"
+	"github.com/fluxcd/flagger/pkg/sharding"
 	knative "knative.dev/serving/pkg/client/clientset/versioned"
+	servinginformers "knative.dev/serving/pkg/client/informers/externalversions/serving/v1"
 )
 
 const controllerAgentName = "flagger"
@@ -60,15 +69,25 @@ type Controller struct {
 	flaggerSynced        cache.InformerSynced
 	flaggerWindow        time.Duration
 	workqueue            workqueue.RateLimitingInterface
+	validationQueue      workqueue.RateLimitingInterface
 	eventRecorder        record.EventRecorder
 	logger               *zap.SugaredLogger
 	canaries             *sync.Map
-	jobs                 map[string]CanaryJob
+	canaryWrites         sync.Map
+	shard                *sharding.Shard
+	shardRevision        int64
+	progressingLimit     int
+	progressingNsLimit   int
+	progressingMu        sync.Mutex
+	progressingAdmitted  map[string]time.Time
+	analysisQueue        *analysisQueue
+	analysisConcurrency  int
 	recorder             metrics.Recorder
 	notifier             notifier.Interface
 	canaryFactory        *canary.Factory
 	routerFactory        *router.Factory
 	observerFactory      *observers.Factory
+	providerPool         *providers.Pool
 	meshProvider         string
 	eventWebhook         string
 	clusterName          string
@@ -76,9 +95,18 @@ type Controller struct {
 }
 
 type Informers struct {
-	CanaryInformer flaggerinformers.CanaryInformer
-	MetricInformer flaggerinformers.MetricTemplateInformer
-	AlertInformer  flaggerinformers.AlertProviderInformer
+	CanaryInformer     flaggerinformers.CanaryInformer
+	MetricInformer     flaggerinformers.MetricTemplateInformer
+	AlertInformer      flaggerinformers.AlertProviderInformer
+	SecretInformer     coreinformers.SecretInformer
+	DeploymentInformer appsinformers.DeploymentInformer
+	DaemonSetInformer  appsinformers.DaemonSetInformer
+	ServiceInformer    coreinformers.ServiceInformer
+	HPAInformer        autoscalinginformers.HorizontalPodAutoscalerInformer
+	ConfigMapInformer  coreinformers.ConfigMapInformer
+
+	// KnativeServiceInformer is only set when Knative is the mesh provider
+	KnativeServiceInformer servinginformers.ServiceInformer
 }
 
 func NewController(
@@ -97,6 +125,10 @@ func NewController(
 	eventWebhook string,
 	clusterName string,
 	noCrossNamespaceRefs bool,
+	maxConcurrentAnalyses int,
+	maxProgressing int,
+	maxProgressingPerNamespace int,
+	shard *sharding.Shard,
 	kubeConfig *rest.Config,
 ) *Controller {
 	logger.Debug("Creating event broadcaster")
@@ -119,12 +151,19 @@ func NewController(
 		flaggerInformers:     flaggerInformers,
 		flaggerSynced:        flaggerInformers.CanaryInformer.Informer().HasSynced,
 		workqueue:            workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerAgentName),
+		validationQueue:      workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerAgentName+"-validation"),
 		eventRecorder:        eventRecorder,
 		logger:               logger,
 		canaries:             new(sync.Map),
-		jobs:                 map[string]CanaryJob{},
+		shard:                shard,
+		progressingLimit:     maxProgressing,
+		progressingNsLimit:   maxProgressingPerNamespace,
+		progressingAdmitted:  make(map[string]time.Time),
+		analysisQueue:        newAnalysisQueue(controllerAgentName + "-analysis"),
+		analysisConcurrency:  maxConcurrentAnalyses,
 		flaggerWindow:        flaggerWindow,
 		observerFactory:      observerFactory,
+		providerPool:         providers.NewPool(providers.NewFactory(logger)),
 		recorder:             recorder,
 		notifier:             notifier,
 		canaryFactory:        canaryFactory,
@@ -163,10 +202,13 @@ func NewController(
 				// If this was marked for deletion and has finalizers enqueue for finalizing or
 				// if this canary doesn't have finalizers and RevertOnDeletion is true updated speck enqueue
 				ctrl.enqueue(new)
+			} else if ctrl.shard != nil && !labels.Equals(newCanary.Labels, oldCanary.Labels) {
+				// label changes can move the canary to another shard
+				ctrl.enqueue(new)
 			}
 
 			// If canary no longer desires reverting, finalizers should be removed
-			if oldCanary.Spec.RevertOnDeletion && !newCanary.Spec.RevertOnDeletion {
+			if oldCanary.Spec.RevertOnDeletion && !newCanary.Spec.RevertOnDeletion && ctrl.ownsCanary(&newCanary) {
 				ctrl.logger.Infof("%s.%s opting out, deleting finalizers", newCanary.Name, newCanary.Namespace)
 				err := ctrl.removeFinalizer(&newCanary)
 				if err != nil {
@@ -184,13 +226,150 @@ func NewController(
 		},
 	})
 
+	flaggerInformers.MetricInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ctrl.enqueueValidation(flaggerv1.MetricTemplateKind, obj)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			oldTemplate, ok := old.(*flaggerv1.MetricTemplate)
+			if !ok {
+				return
+			}
+			newTemplate, ok := new.(*flaggerv1.MetricTemplate)
+			if !ok {
+				return
+			}
+			if oldTemplate.Generation != newTemplate.Generation {
+				ctrl.providerPool.InvalidateTemplate(newTemplate.Namespace, newTemplate.Name)
+				ctrl.enqueueValidation(flaggerv1.MetricTemplateKind, new)
+			}
+		},
+		DeleteFunc: func(old interface{}) {
+			if namespace, name, ok := splitDeletedObjectKey(old); ok {
+				ctrl.providerPool.InvalidateTemplate(namespace, name)
+			}
+		},
+	})
+
+	flaggerInformers.AlertInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			ctrl.enqueueValidation(flaggerv1.AlertProviderKind, obj)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			oldProvider, ok := old.(*flaggerv1.AlertProvider)
+			if !ok {
+				return
+			}
+			newProvider, ok := new.(*flaggerv1.AlertProvider)
+			if !ok {
+				return
+			}
+			if oldProvider.Generation != newProvider.Generation {
+				ctrl.enqueueValidation(flaggerv1.AlertProviderKind, new)
+			}
+		},
+	})
+
+	if flaggerInformers.SecretInformer != nil {
+		flaggerInformers.SecretInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(old, new interface{}) {
+				oldSecret, ok := old.(*corev1.Secret)
+				if !ok {
+					return
+				}
+				newSecret, ok := new.(*corev1.Secret)
+				if !ok {
+					return
+				}
+				if oldSecret.ResourceVersion != newSecret.ResourceVersion {
+					ctrl.providerPool.InvalidateSecret(newSecret.Namespace, newSecret.Name)
+					ctrl.enqueueSecretReferences(newSecret.Namespace, newSecret.Name)
+				}
+			},
+			DeleteFunc: func(old interface{}) {
+				if namespace, name, ok := splitDeletedObjectKey(old); ok {
+					ctrl.providerPool.InvalidateSecret(namespace, name)
+					ctrl.enqueueSecretReferences(namespace, name)
+				}
+			},
+		})
+	}
+
+	// run the analysis of the canaries right away when their target has a new revision
+	if flaggerInformers.DeploymentInformer != nil {
+		flaggerInformers.DeploymentInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(old, new interface{}) {
+				oldDep, ok := old.(*appsv1.Deployment)
+				if !ok {
+					return
+				}
+				newDep, ok := new.(*appsv1.Deployment)
+				if !ok {
+					return
+				}
+				if !equality.Semantic.DeepEqual(oldDep.Spec.Template, newDep.Spec.Template) {
+					ctrl.enqueueTargetCanaries("Deployment", newDep.Namespace, newDep.Name)
+				}
+			},
+		})
+	}
+
+	if flaggerInformers.DaemonSetInformer != nil {
+		flaggerInformers.DaemonSetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(old, new interface{}) {
+				oldDae, ok := old.(*appsv1.DaemonSet)
+				if !ok {
+					return
+				}
+				newDae, ok := new.(*appsv1.DaemonSet)
+				if !ok {
+					return
+				}
+				if !equality.Semantic.DeepEqual(oldDae.Spec.Template, newDae.Spec.Template) {
+					ctrl.enqueueTargetCanaries("DaemonSet", newDae.Namespace, newDae.Name)
+				}
+			},
+		})
+	}
+
 	return ctrl
 }
 
+// enqueueTargetCanaries schedules the analysis of the canaries that target the given workload
+func (c *Controller) enqueueTargetCanaries(kind, namespace, name string) {
+	canaries, err := c.flaggerInformers.CanaryInformer.Lister().Canaries(namespace).List(labels.Everything())
+	if err != nil {
+		return
+	}
+	for _, cd := range canaries {
+		if cd.Spec.TargetRef.Kind != kind || cd.Spec.TargetRef.Name != name || !c.ownsCanary(cd) {
+			continue
+		}
+		if _, ok := c.canaries.Load(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)); ok {
+			c.analysisQueue.Add(fmt.Sprintf("%s/%s", cd.Namespace, cd.Name))
+		}
+	}
+}
+
+// splitDeletedObjectKey returns the namespace and name of a deleted object or tombstone
+func splitDeletedObjectKey(obj interface{}) (string, string, bool) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return "", "", false
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return "", "", false
+	}
+	return namespace, name, true
+}
+
 // Run starts the K8s workers and the canary scheduler
 func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 	defer c.workqueue.ShutDown()
+	defer c.validationQueue.ShutDown()
+	defer c.analysisQueue.ShutDown()
 
 	c.logger.Info("Starting operator")
 
@@ -201,13 +380,30 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 		}, time.Second, stopCh)
 	}
 
+	// validate the metric templates and alert providers
+	go wait.Until(func() {
+		for c.processNextValidation() {
+		}
+	}, time.Second, stopCh)
+
+	// run the canary analysis with a bounded concurrency
+	for i := 0; i < c.analysisConcurrency; i++ {
+		go wait.Until(func() {
+			for c.processNextAnalysis() {
+			}
+		}, time.Second, stopCh)
+	}
+
 	c.logger.Info("Started operator workers")
 
 	tickChan := time.NewTicker(c.flaggerWindow).C
+	validationChan := time.NewTicker(validationResyncInterval).C
 	for {
 		select {
 		case <-tickChan:
 			c.scheduleCanaries()
+		case <-validationChan:
+			c.enqueueAllValidations()
 		case <-stopCh:
 			c.logger.Info("Shutting down operator workers")
 			return nil
@@ -262,6 +458,14 @@ func (c *Controller) syncHandler(key string) error {
 		return nil
 	}
 
+	// leave the canary to the replica that owns it
+	if !c.ownsCanary(cd) {
+		if _, ok := c.canaries.LoadAndDelete(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)); ok {
+			c.logger.Infof("Canary %s moved to another shard", key)
+		}
+		return nil
+	}
+
 	if err := c.verifyCanary(cd); err != nil {
 		return fmt.Errorf("invalid canary spec: %s", err)
 	}
@@ -306,6 +510,9 @@ func (c *Controller) syncHandler(key string) error {
 
 	c.canaries.Store(fmt.Sprintf("%s.%s", cd.Name, cd.Namespace), cd)
 
+	// run the analysis right away to pick up the spec changes
+	c.analysisQueue.Add(key)
+
 	// If opt in for revertOnDeletion add finalizer if not present
 	if cd.Spec.RevertOnDeletion && !hasFinalizer(cd) {
 		if err := c.addFinalizer(cd); err != nil {
@@ -318,6 +525,36 @@ func (c *Controller) syncHandler(key string) error {
 	return nil
 }
 
+// ownsCanary returns true if the canary is reconciled by this replica
+func (c *Controller) ownsCanary(cd *flaggerv1.Canary) bool {
+	if c.shard == nil {
+		return true
+	}
+	return c.shard.Owns(cd.Namespace, cd.Name, cd.Labels)
+}
+
+// resyncShard enqueues all canaries when the shard members change,
+// so that the canaries are picked up by their new owners
+func (c *Controller) resyncShard() {
+	if c.shard == nil {
+		return
+	}
+	revision := c.shard.Revision()
+	if revision == c.shardRevision {
+		return
+	}
+	c.shardRevision = revision
+
+	canaries, err := c.flaggerInformers.CanaryInformer.Lister().List(labels.Everything())
+	if err != nil {
+		c.logger.Errorf("Canary list query failed: %v", err)
+		return
+	}
+	for _, cd := range canaries {
+		c.enqueue(cd)
+	}
+}
+
 func (c *Controller) enqueue(obj interface{}) {
 	var key string
 	var err error
@@ -340,6 +577,20 @@ func (c *Controller) verifyCanary(canary *flaggerv1.Canary) error {
 	if err := verifySessionAffinity(canary); err != nil {
 		return err
 	}
+	if err := verifyAnalysisSteps(canary); err != nil {
+		return err
+	}
+
+	provider := c.meshProvider
+	if canary.Spec.Provider != "" {
+		provider = canary.Spec.Provider
+	}
+	if err := router.ValidateABTesting(provider, canary); err != nil {
+		return err
+	}
+	if err := router.ValidateSessionAffinity(provider, canary); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -368,6 +619,20 @@ func verifyNoCrossNamespaceRefs(canary *flaggerv1.Canary) error {
 				}
 			}
 		}
+		for i, step := range canary.Spec.Analysis.Steps {
+			for _, metric := range step.Metrics {
+				if metric.TemplateRef != nil {
+					// Default to canary namespace if templateRef namespace is empty
+					namespace := metric.TemplateRef.Namespace
+					if namespace == "" {
+						namespace = canary.Namespace
+					}
+					if namespace != canary.Namespace {
+						return fmt.Errorf("can't access metric template %s.%s in step %d, cross-namespace references are blocked", metric.TemplateRef.Name, metric.TemplateRef.Namespace, i)
+					}
+				}
+			}
+		}
 		for _, alert := range canary.Spec.Analysis.Alerts {
 			// Default to canary namespace if providerRef namespace is empty
 			namespace := alert.ProviderRef.Namespace
@@ -410,6 +675,41 @@ func verifySessionAffinity(canary *flaggerv1.Canary) error {
 	return nil
 }
 
+func verifyAnalysisSteps(canary *flaggerv1.Canary) error {
+	analysis := canary.GetAnalysis()
+	if analysis == nil || len(analysis.Steps) == 0 {
+		return nil
+	}
+
+	if analysis.StepWeight > 0 || len(analysis.StepWeights) > 0 {
+		return fmt.Errorf("can't use steps together with stepWeight or stepWeights")
+	}
+
+	previous := 0
+	for i, step := range analysis.Steps {
+		if step.Weight <= previous {
+			return fmt.Errorf("step %d weight %d must be greater than %d", i, step.Weight, previous)
+		}
+		previous = step.Weight
+
+		if step.Duration != "" {
+			if _, err := time.ParseDuration(step.Duration); err != nil {
+				return fmt.Errorf("step %d duration %s is invalid: %w", i, step.Duration, err)
+			}
+		}
+
+		for _, webhook := range step.Webhooks {
+			if webhook.Type != "" && webhook.Type != flaggerv1.RolloutHook &&
+				webhook.Type != flaggerv1.ConfirmTrafficIncreaseHook {
+				return fmt.Errorf("step %d webhook %s type %s is not supported, use rollout or confirm-traffic-increase",
+					i, webhook.Name, webhook.Type)
+			}
+		}
+	}
+
+	return nil
+}
+
 func checkCustomResourceType(obj interface{}, logger *zap.SugaredLogger) (flaggerv1.Canary, bool) {
 	var roll *flaggerv1.Canary
 	var ok bool
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -208,6 +210,106 @@ func TestController_verifyCanary(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "steps with decreasing weights should return an error",
+			canary: flaggerv1.Canary{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cd-1",
+					Namespace: "default",
+				},
+				Spec: flaggerv1.CanarySpec{
+					Analysis: &flaggerv1.CanaryAnalysis{
+						Steps: []flaggerv1.CanaryStep{
+							{Weight: 50},
+							{Weight: 10},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "steps combined with step weights should return an error",
+			canary: flaggerv1.Canary{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cd-1",
+					Namespace: "default",
+				},
+				Spec: flaggerv1.CanarySpec{
+					Analysis: &flaggerv1.CanaryAnalysis{
+						StepWeights: []int{10, 50},
+						Steps: []flaggerv1.CanaryStep{
+							{Weight: 10},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "steps with pre-rollout webhook should return an error",
+			canary: flaggerv1.Canary{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cd-1",
+					Namespace: "default",
+				},
+				Spec: flaggerv1.CanarySpec{
+					Analysis: &flaggerv1.CanaryAnalysis{
+						Steps: []flaggerv1.CanaryStep{
+							{
+								Weight: 10,
+								Webhooks: []flaggerv1.CanaryWebhook{
+									{Name: "hook", Type: flaggerv1.PreRolloutHook},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "steps with increasing weights are okay",
+			canary: flaggerv1.Canary{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cd-1",
+					Namespace: "default",
+				},
+				Spec: flaggerv1.CanarySpec{
+					Analysis: &flaggerv1.CanaryAnalysis{
+						Steps: []flaggerv1.CanaryStep{
+							{Weight: 1, Duration: "1h"},
+							{Weight: 50, Iterations: 2},
+							{Weight: 100},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "A/B testing with a provider that can't match headers should return an error",
+			canary: flaggerv1.Canary{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cd-1",
+					Namespace: "default",
+				},
+				Spec: flaggerv1.CanarySpec{
+					Provider: flaggerv1.KumaProvider,
+					Analysis: &flaggerv1.CanaryAnalysis{
+						Iterations: 10,
+						Match: []istiov1beta1.HTTPMatchRequest{
+							{
+								Headers: map[string]istiov1alpha1.StringMatch{
+									"x-canary": {Exact: "insider"},
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	ctrl := &Controller{
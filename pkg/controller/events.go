@@ -130,32 +130,11 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 			continue
 		}
 
-		// set hook URL address
-		url := provider.Spec.Address
-
-		// set the token which will be sent in the header
-		// https://datatracker.ietf.org/doc/html/rfc6750
-		token := ""
-
-		// extract address from secret
-		if provider.Spec.SecretRef != nil {
-			secret, err := c.kubeClient.CoreV1().Secrets(providerNamespace).Get(context.TODO(), provider.Spec.SecretRef.Name, metav1.GetOptions{})
-			if err != nil {
-				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-					Errorf("alert provider %s.%s secretRef error: %v", alert.ProviderRef.Name, providerNamespace, err)
-				continue
-			}
-			if address, ok := secret.Data["address"]; ok {
-				url = string(address)
-			} else {
-				c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
-					Errorf("alert provider %s.%s secret does not contain an address", alert.ProviderRef.Name, providerNamespace)
-				continue
-			}
-
-			if tokenFromSecret, ok := secret.Data["token"]; ok {
-				token = string(tokenFromSecret)
-			}
+		url, token, err := c.getAlertProviderAddress(provider)
+		if err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+				Errorf("alert provider %s.%s %v", alert.ProviderRef.Name, providerNamespace, err)
+			continue
 		}
 
 		// set defaults
@@ -191,6 +170,25 @@ func (c *Controller) alert(canary *flaggerv1.Canary, message string, metadata bo
 	}
 }
 
+// getAlertProviderAddress returns the hook URL address of the provider and the token
+// sent in the header (https://datatracker.ietf.org/doc/html/rfc6750),
+// the address from the secret takes precedence over the spec address
+func (c *Controller) getAlertProviderAddress(provider *flaggerv1.AlertProvider) (string, string, error) {
+	if provider.Spec.SecretRef == nil {
+		return provider.Spec.Address, "", nil
+	}
+
+	secret, err := c.kubeClient.CoreV1().Secrets(provider.Namespace).Get(context.TODO(), provider.Spec.SecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("secretRef error: %w", err)
+	}
+	address, ok := secret.Data["address"]
+	if !ok {
+		return "", "", fmt.Errorf("secret does not contain an address")
+	}
+	return string(address), string(secret.Data["token"]), nil
+}
+
 func alertMetadata(canary *flaggerv1.Canary) []notifier.Field {
 	var fields []notifier.Field
 
@@ -98,8 +98,13 @@ func (c *Controller) finalize(old interface{}) error {
 		return fmt.Errorf("failed to get metadata for router finalizing: %w", err)
 	}
 
+	provider := c.meshProvider
+	if canary.Spec.Provider != "" {
+		provider = canary.Spec.Provider
+	}
+
 	// Revert the Kubernetes service
-	router := c.routerFactory.KubernetesRouter(canary.Spec.TargetRef.Kind, labelSelector, labelValue, ports)
+	router := c.routerFactory.KubernetesRouter(canary.Spec.TargetRef.Kind, labelSelector, labelValue, ports, provider)
 	if err := router.Finalize(canary); err != nil {
 		return fmt.Errorf("failed revert router: %w", err)
 	}
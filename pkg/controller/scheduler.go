@@ -23,6 +23,9 @@ import (
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/retry"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -39,6 +42,10 @@ func (c *Controller) min(a int, b int) int {
 }
 
 func (c *Controller) maxWeight(canary *flaggerv1.Canary) int {
+	var stepsLen = len(canary.GetAnalysis().Steps)
+	if stepsLen > 0 {
+		return c.min(c.totalWeight(canary), canary.GetAnalysis().Steps[stepsLen-1].Weight)
+	}
 	var stepWeightsLen = len(canary.GetAnalysis().StepWeights)
 	if stepWeightsLen > 0 {
 		return c.min(c.totalWeight(canary), canary.GetAnalysis().StepWeights[stepWeightsLen-1])
@@ -56,6 +63,10 @@ func (c *Controller) totalWeight(canary *flaggerv1.Canary) int {
 }
 
 func (c *Controller) nextStepWeight(canary *flaggerv1.Canary, canaryWeight int) int {
+	if len(canary.GetAnalysis().Steps) > 0 {
+		return c.nextAnalysisStepWeight(canary, canaryWeight)
+	}
+
 	var stepWeightsLen = len(canary.GetAnalysis().StepWeights)
 	if canary.GetAnalysis().StepWeight > 0 || stepWeightsLen == 0 {
 		return canary.GetAnalysis().StepWeight
@@ -87,10 +98,85 @@ func (c *Controller) nextStepWeight(canary *flaggerv1.Canary, canaryWeight int)
 	return maxStep
 }
 
-// scheduleCanaries synchronises the canary map with the jobs map,
-// for new canaries new jobs are created and started
-// for the removed canaries the jobs are stopped and deleted
+func (c *Controller) nextAnalysisStepWeight(canary *flaggerv1.Canary, canaryWeight int) int {
+	steps := canary.GetAnalysis().Steps
+	maxStep := c.totalWeight(canary) - canaryWeight
+
+	// If maxStep is zero we need to promote, so any non zero step weight will move the canary to promotion.
+	if maxStep == 0 {
+		return 1
+	}
+
+	// initial step
+	if canary.Status.StepStartTime == nil {
+		return c.min(maxStep, steps[0].Weight)
+	}
+
+	// return the weight difference to the next step
+	if next := canary.Status.CurrentStep + 1; next < len(steps) {
+		return c.min(maxStep, steps[next].Weight-canaryWeight)
+	}
+
+	return maxStep
+}
+
+// holdAnalysisStep returns true if the canary should stay at the current step,
+// either because the step bake time and iterations aren't satisfied or because
+// the step is paused and hasn't been resumed yet
+func (c *Controller) holdAnalysisStep(canary *flaggerv1.Canary, canaryController canary.Controller) bool {
+	step := canary.GetAnalysisStep()
+	if step == nil {
+		return false
+	}
+
+	iterations := canary.Status.StepIterations + 1
+	elapsed := time.Since(canary.Status.StepStartTime.Time)
+	if iterations < step.GetIterations() || elapsed < step.GetDuration() {
+		if err := canaryController.SetStatusStep(canary, canary.Status.CurrentStep, iterations); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+			return true
+		}
+		c.recordEventInfof(canary, "Holding %s.%s at step %v/%v canary weight %v iteration %v/%v elapsed %v/%v",
+			canary.Name, canary.Namespace, canary.Status.CurrentStep+1, len(canary.GetAnalysis().Steps),
+			step.Weight, iterations, step.GetIterations(), elapsed.Round(time.Second), step.GetDuration())
+		return true
+	}
+
+	if step.Pause && !canary.IsAnalysisStepResumed() {
+		c.recordEventInfof(canary, "Halt %s.%s advancement step %v/%v is paused, set the %s annotation to %v to resume",
+			canary.Name, canary.Namespace, canary.Status.CurrentStep+1, len(canary.GetAnalysis().Steps),
+			flaggerv1.ResumeStepAnnotation, canary.Status.CurrentStep)
+		return true
+	}
+
+	return false
+}
+
+// clearResumeStep removes the flagger.app/resume-step annotation, so that it doesn't
+// resume the paused steps of another analysis, the canary must not be a cached object
+func (c *Controller) clearResumeStep(canary *flaggerv1.Canary) {
+	if _, ok := canary.Annotations[flaggerv1.ResumeStepAnnotation]; !ok {
+		return
+	}
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, flaggerv1.ResumeStepAnnotation))
+	patched, err := c.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Patch(context.TODO(), canary.Name,
+		types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		c.recordEventWarningf(canary, "removing annotation %s failed: %v", flaggerv1.ResumeStepAnnotation, err)
+		return
+	}
+
+	// keep the status writes of this run from conflicting with the patch
+	delete(canary.Annotations, flaggerv1.ResumeStepAnnotation)
+	canary.ResourceVersion = patched.ResourceVersion
+}
+
+// scheduleCanaries checks the canaries for conflicting targets and records the number of canaries per namespace,
+// the analysis runs are scheduled by the analysis queue
 func (c *Controller) scheduleCanaries() {
+	c.resyncShard()
+
 	current := make(map[string]string)
 	stats := make(map[string]int)
 
@@ -101,26 +187,6 @@ func (c *Controller) scheduleCanaries() {
 		name := key.(string)
 		current[name] = fmt.Sprintf("%s.%s", cn.Spec.TargetRef.Name, cn.Namespace)
 
-		job, exists := c.jobs[name]
-		// schedule new job for existing job with different analysis interval or non-existing job
-		if (exists && job.GetCanaryAnalysisInterval() != cn.GetAnalysisInterval()) || !exists {
-			if exists {
-				job.Stop()
-			}
-
-			newJob := CanaryJob{
-				Name:             cn.Name,
-				Namespace:        cn.Namespace,
-				function:         c.advanceCanary,
-				done:             make(chan bool),
-				ticker:           time.NewTicker(cn.GetAnalysisInterval()),
-				analysisInterval: cn.GetAnalysisInterval(),
-			}
-
-			c.jobs[name] = newJob
-			newJob.Start()
-		}
-
 		// compute canaries per namespace total
 		t, ok := stats[cn.Namespace]
 		if !ok {
@@ -131,14 +197,6 @@ func (c *Controller) scheduleCanaries() {
 		return true
 	})
 
-	// cleanup deleted jobs
-	for job := range c.jobs {
-		if _, exists := current[job]; !exists {
-			c.jobs[job].Stop()
-			delete(c.jobs, job)
-		}
-	}
-
 	// check if multiple canaries have the same target
 	for canaryName, targetName := range current {
 		for name, target := range current {
@@ -155,14 +213,74 @@ func (c *Controller) scheduleCanaries() {
 	}
 }
 
-func (c *Controller) advanceCanary(name string, namespace string) {
+// processNextAnalysis runs the analysis of the next canary that is due
+// and schedules its next run after the analysis interval
+func (c *Controller) processNextAnalysis() bool {
+	key, shutdown := c.analysisQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.analysisQueue.Done(key)
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return true
+	}
+
+	// stop scheduling the canaries that have been deleted or moved to another shard
+	value, ok := c.canaries.Load(fmt.Sprintf("%s.%s", name, namespace))
+	if !ok || !c.ownsCanary(value.(*flaggerv1.Canary)) {
+		return true
+	}
+
+	err = c.advanceCanary(name, namespace)
+
+	value, ok = c.canaries.Load(fmt.Sprintf("%s.%s", name, namespace))
+	if !ok {
+		c.analysisQueue.Forget(key)
+		return true
+	}
+	next := wait.Jitter(value.(*flaggerv1.Canary).GetAnalysisInterval(), analysisJitterFactor)
+
+	// back off the canaries that keep failing to reconcile
+	if err != nil {
+		if backoff := c.analysisQueue.Failed(key); backoff > next {
+			next = backoff
+		}
+	} else {
+		c.analysisQueue.Forget(key)
+	}
+	c.analysisQueue.AddAfter(key, next)
+	return true
+}
+
+// getCanary returns a copy of the canary from the informer cache, the canary is fetched from the API
+// when the cache hasn't synced yet or when it still holds the object seen before the last status update
+func (c *Controller) getCanary(name string, namespace string) (*flaggerv1.Canary, error) {
+	informer := c.flaggerInformers.CanaryInformer
+	if informer.Informer().HasSynced() {
+		key := fmt.Sprintf("%s.%s", name, namespace)
+		cd, err := informer.Lister().Canaries(namespace).Get(name)
+		if err == nil {
+			if version, ok := c.canaryWrites.Load(key); !ok || version.(string) != cd.ResourceVersion {
+				c.canaryWrites.Delete(key)
+				return cd.DeepCopy(), nil
+			}
+		}
+	}
+	return c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// advanceCanary runs one analysis step of the canary, the returned error
+// means the canary objects could not be reconciled and the run should be retried later
+func (c *Controller) advanceCanary(name string, namespace string) error {
 	begin := time.Now()
 	// check if the canary exists
-	cd, err := c.flaggerClient.FlaggerV1beta1().Canaries(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	cd, err := c.getCanary(name, namespace)
 	if err != nil {
 		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
 			Errorf("Canary %s.%s not found", name, namespace)
-		return
+		return err
 	}
 
 	if cd.Spec.Suspend {
@@ -170,7 +288,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).
 			Debug(msg)
 		c.recordEventInfof(cd, "%s", msg)
-		return
+		return nil
 	}
 
 	// override the global provider if one is specified in the canary spec
@@ -179,13 +297,21 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		provider = cd.Spec.Provider
 	}
 
-	// init controller based on target kind
-	canaryController := c.canaryFactory.Controller(cd.Spec.TargetRef)
+	// init controller based on target kind, the status updates of this run are written with a single request
+	canaryController := canary.NewStatusBatch(c.canaryFactory.Controller(cd.Spec.TargetRef), c.flaggerClient, cd)
+	defer func() {
+		if err := canaryController.Flush(); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+		if canaryController.Written() {
+			c.canaryWrites.Store(fmt.Sprintf("%s.%s", name, namespace), cd.ResourceVersion)
+		}
+	}()
 
 	labelSelector, labelValue, ports, err := canaryController.GetMetadata(cd)
 	if err != nil {
 		c.recordEventWarningf(cd, "%v", err)
-		return
+		return err
 	}
 
 	var scalerReconciler canary.ScalerReconciler
@@ -194,12 +320,12 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	}
 
 	// init Kubernetes router
-	kubeRouter := c.routerFactory.KubernetesRouter(cd.Spec.TargetRef.Kind, labelSelector, labelValue, ports)
+	kubeRouter := c.routerFactory.KubernetesRouter(cd.Spec.TargetRef.Kind, labelSelector, labelValue, ports, provider)
 
 	// reconcile the canary/primary services
 	if err := kubeRouter.Initialize(cd); err != nil {
 		c.recordEventWarningf(cd, "%v", err)
-		return
+		return err
 	}
 
 	// check metric servers' availability
@@ -217,7 +343,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	if strings.HasPrefix(provider, flaggerv1.AppMeshProvider) {
 		if err := meshRouter.Reconcile(cd); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
-			return
+			return err
 		}
 	}
 
@@ -228,20 +354,20 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		if !retriable {
 			c.rollback(cd, canaryController, meshRouter, scalerReconciler)
 		}
-		return
+		return nil
 	}
 
 	if scalerReconciler != nil {
 		err = scalerReconciler.ReconcilePrimaryScaler(cd, true)
 		if err != nil {
 			c.recordEventWarningf(cd, "%v", err)
-			return
+			return err
 		}
 		if cd.Status.Phase == "" || cd.Status.Phase == flaggerv1.CanaryPhaseInitializing {
 			err = scalerReconciler.PauseTargetScaler(cd)
 			if err != nil {
 				c.recordEventWarningf(cd, "%v", err)
-				return
+				return err
 			}
 		}
 	}
@@ -249,7 +375,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	// change the apex service pod selector to primary
 	if err := kubeRouter.Reconcile(cd); err != nil {
 		c.recordEventWarningf(cd, "%v", err)
-		return
+		return err
 	}
 
 	// scale down the canary target to 0 replicas after the service is pointing to the primary target
@@ -258,7 +384,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			Infof("Scaling down %s %s.%s", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace)
 		if err := canaryController.ScaleToZero(cd); err != nil {
 			c.recordEventWarningf(cd, "scaling down canary %s %s.%s failed: %v", cd.Spec.TargetRef.Kind, cd.Spec.TargetRef.Name, cd.Namespace, err)
-			return
+			return err
 		}
 	}
 
@@ -267,30 +393,40 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	if !strings.HasPrefix(provider, flaggerv1.AppMeshProvider) {
 		if err := meshRouter.Reconcile(cd); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
-			return
+			return err
 		}
 	}
 
 	// set canary phase to initialized and sync the status
 	if err = c.setPhaseInitialized(cd, canaryController); err != nil {
 		c.recordEventWarningf(cd, "%v", err)
-		return
+		return err
 	}
 
+	// resume the target if a pin outlived its analysis
+	c.releaseStalePin(cd)
+
 	// check for changes
 	shouldAdvance, err := c.shouldAdvance(cd, canaryController)
 	if err != nil {
 		c.recordEventWarningf(cd, "%v", err)
-		return
+		return err
 	}
 
 	if !shouldAdvance {
 		c.recorder.SetStatus(cd, cd.Status.Phase)
-		return
+		return nil
 	}
 
 	maxWeight := c.maxWeight(cd)
 
+	// check if the kubernetes provider can shift traffic by scaling the canary
+	replicaRatio, err := router.UsesReplicaRatio(c.kubeClient, provider, cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return err
+	}
+
 	// check primary status
 	if !cd.SkipAnalysis() {
 		retriable, err := canaryController.IsPrimaryReady(cd)
@@ -306,7 +442,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 					c.rollback(cd, canaryController, meshRouter, scalerReconciler)
 				}
 			}
-			return
+			return nil
 		}
 	}
 
@@ -314,18 +450,18 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	primaryWeight, canaryWeight, mirrored, err := meshRouter.GetRoutes(cd)
 	if err != nil {
 		c.recordEventWarningf(cd, "%v", err)
-		return
+		return err
 	}
 
 	c.recorder.SetWeight(cd, primaryWeight, canaryWeight)
 
 	// check if canary analysis should start (canary revision has changes) or continue
-	if ok := c.checkCanaryStatus(cd, canaryController, scalerReconciler, shouldAdvance); !ok {
-		return
+	if ok := c.checkCanaryStatus(cd, canaryController, meshRouter, scalerReconciler, shouldAdvance, replicaRatio); !ok {
+		return nil
 	}
 
 	// check if canary revision changed during analysis
-	if restart := c.hasCanaryRevisionChanged(cd, canaryController); restart {
+	if restart := c.hasCanaryRevisionChanged(cd, canaryController); restart && !c.deferNewRevision(cd, canaryController) {
 		c.recordEventInfof(cd, "New revision detected! Restarting analysis for %s.%s",
 			cd.Spec.TargetRef.Name, cd.Namespace)
 
@@ -334,10 +470,17 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		canaryWeight = 0
 		if err := meshRouter.SetRoutes(cd, primaryWeight, canaryWeight, false); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
-			return
+			return err
+		}
+
+		// roll out the new revision
+		if err := c.unpinRevision(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return err
 		}
 
 		// reset status
+		c.clearResumeStep(cd)
 		status := flaggerv1.CanaryStatus{
 			Phase:        flaggerv1.CanaryPhaseProgressing,
 			CanaryWeight: 0,
@@ -346,8 +489,9 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		}
 		if err := canaryController.SyncStatus(cd, status); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
+			return err
 		}
-		return
+		return nil
 	}
 
 	// check canary status
@@ -357,12 +501,18 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		if !retriable {
 			c.rollback(cd, canaryController, meshRouter, scalerReconciler)
 		}
-		return
+		return nil
+	}
+
+	// hold the canary pods on the analysed revision
+	if cd.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
+		cd.Status.Phase == flaggerv1.CanaryPhaseWaitingPromotion {
+		c.pinRevision(cd)
 	}
 
 	// check if analysis should be skipped
 	if skip := c.shouldSkipAnalysis(cd, canaryController, meshRouter, scalerReconciler, err, retriable); skip {
-		return
+		return nil
 	}
 
 	// check if we should rollback
@@ -373,7 +523,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			c.recordEventWarningf(cd, "Rolling back %s.%s manual webhook invoked", cd.Name, cd.Namespace)
 			c.alert(cd, "Rolling back manual webhook invoked", false, flaggerv1.SeverityWarn)
 			c.rollback(cd, canaryController, meshRouter, scalerReconciler)
-			return
+			return nil
 		}
 	}
 
@@ -382,11 +532,10 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		if scalerReconciler != nil {
 			if err := scalerReconciler.ReconcilePrimaryScaler(cd, false); err != nil {
 				c.recordEventWarningf(cd, "%v", err)
-				return
+				return err
 			}
 		}
-		c.runPromotionTrafficShift(cd, canaryController, meshRouter, provider, canaryWeight, primaryWeight)
-		return
+		return c.runPromotionTrafficShift(cd, canaryController, meshRouter, provider, canaryWeight, primaryWeight)
 	}
 
 	// scale canary to zero if promotion has finished
@@ -394,18 +543,23 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		if scalerReconciler != nil {
 			if err := scalerReconciler.PauseTargetScaler(cd); err != nil {
 				c.recordEventWarningf(cd, "%v", err)
-				return
+				return err
 			}
 		}
 		if err := canaryController.ScaleToZero(cd); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
-			return
+			return err
 		}
+		if err := c.unpinRevision(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return err
+		}
+		c.clearResumeStep(cd)
 
 		// set status to succeeded
 		if err := canaryController.SetStatusPhase(cd, flaggerv1.CanaryPhaseSucceeded); err != nil {
 			c.recordEventWarningf(cd, "%v", err)
-			return
+			return err
 		}
 
 		c.recorder.SetStatus(cd, flaggerv1.CanaryPhaseSucceeded)
@@ -423,7 +577,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 		c.alert(canarySucceeded, "Canary analysis completed successfully, promotion finished.",
 			false, flaggerv1.SeverityInfo)
 
-		return
+		return nil
 	}
 
 	// check if the number of failed checks reached the threshold
@@ -436,7 +590,7 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 				false, flaggerv1.SeverityError)
 		}
 		c.rollback(cd, canaryController, meshRouter, scalerReconciler)
-		return
+		return nil
 	}
 
 	// record analysis duration
@@ -455,25 +609,53 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
 				c.recordEventWarningf(cd, "%v", err)
 			}
-			return
+			return nil
 		}
 	} else {
-		if ok := c.runAnalysis(cd); !ok {
+		result := c.runAnalysis(context.TODO(), cd)
+		if result != checkTransient && cd.Status.TransientChecks > 0 {
+			if err := canaryController.SetStatusTransientChecks(cd, 0); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+			}
+		}
+		switch result {
+		case checkFailed:
 			if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
 				c.recordEventWarningf(cd, "%v", err)
 			}
-			return
+			return nil
+		case checkTransient:
+			// retry the analysis on the next tick, a transient error that persists
+			// for threshold consecutive iterations counts as a failed check
+			transientChecks := cd.Status.TransientChecks + 1
+			if transientChecks >= cd.GetAnalysisThreshold() {
+				c.recordEventWarningf(cd, "Halt %s.%s advancement transient errors persisted for %d checks",
+					cd.Name, cd.Namespace, transientChecks)
+				if err := canaryController.SetStatusFailedChecks(cd, cd.Status.FailedChecks+1); err != nil {
+					c.recordEventWarningf(cd, "%v", err)
+				}
+			}
+			if err := canaryController.SetStatusTransientChecks(cd, transientChecks); err != nil {
+				c.recordEventWarningf(cd, "%v", err)
+			}
+			return nil
 		}
 	}
 
-	// use blue/green strategy for kubernetes provider
+	// use blue/green strategy for kubernetes provider when the traffic can't be shifted with replica ratios
 	if provider == flaggerv1.KubernetesProvider {
 		if len(cd.GetAnalysis().Match) > 0 {
 			c.recordEventWarningf(cd, "A/B testing is not supported when using the kubernetes provider")
 			cd.GetAnalysis().Match = nil
 		}
-		if cd.GetAnalysis().Iterations < 1 {
-			c.recordEventWarningf(cd, "Progressive traffic is not supported when using the kubernetes provider")
+		if cd.GetAnalysis().Iterations < 1 && !replicaRatio {
+			if cd.UsesReplicaRatio() {
+				c.recordEventWarningf(cd, "Progressive traffic with the kubernetes provider starts once the primary "+
+					"has been promoted with the %s label", flaggerv1.ApexLabel)
+			} else {
+				c.recordEventWarningf(cd, "Progressive traffic with the kubernetes provider requires a Deployment "+
+					"and analysis.replicaRatio enabled")
+			}
 			c.recordEventWarningf(cd, "Setting canaryAnalysis.iterations: 10")
 			cd.GetAnalysis().Iterations = 10
 		}
@@ -482,37 +664,41 @@ func (c *Controller) advanceCanary(name string, namespace string) {
 	strategy := cd.DeploymentStrategy()
 	switch strategy {
 	case flaggerv1.DeploymentStrategyABTesting:
-		c.runAB(cd, canaryController, meshRouter)
-		return
+		return c.runAB(cd, canaryController, meshRouter)
 	case flaggerv1.DeploymentStrategyBlueGreen:
-		c.runBlueGreen(cd, canaryController, meshRouter, provider, mirrored)
-		return
+		return c.runBlueGreen(cd, canaryController, meshRouter, provider, mirrored)
 	}
 
 	// strategy: Canary progressive traffic increase
 	if c.nextStepWeight(cd, canaryWeight) > 0 {
+		// stay at the current step until its bake time and checks are done
+		if hold := c.holdAnalysisStep(cd, canaryController); hold {
+			return nil
+		}
+
 		// run hook only if traffic is not mirrored
 		if !mirrored &&
 			(cd.Status.Phase != flaggerv1.CanaryPhasePromoting &&
 				cd.Status.Phase != flaggerv1.CanaryPhaseWaitingPromotion &&
 				cd.Status.Phase != flaggerv1.CanaryPhaseFinalising) {
 			if promote := c.runConfirmTrafficIncreaseHooks(cd); !promote {
-				return
+				return nil
 			}
 		}
-		c.runCanary(cd, canaryController, meshRouter, mirrored, canaryWeight, primaryWeight, maxWeight)
+		return c.runCanary(cd, canaryController, meshRouter, mirrored, canaryWeight, primaryWeight, maxWeight)
 	}
 
+	return nil
 }
 
 func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryController canary.Controller,
-	meshRouter router.Interface, provider string, canaryWeight int, primaryWeight int) {
+	meshRouter router.Interface, provider string, canaryWeight int, primaryWeight int) error {
 	// finalize promotion since no traffic shifting is possible for Kubernetes CNI
 	if provider == flaggerv1.KubernetesProvider {
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseFinalising); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
 		}
-		return
+		return nil
 	}
 
 	// route all traffic to primary in one go when promotion step wight is not set
@@ -520,13 +706,13 @@ func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryCo
 		c.recordEventInfof(canary, "Routing all traffic to primary")
 		if err := meshRouter.SetRoutes(canary, c.totalWeight(canary), 0, false); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 		c.recorder.SetWeight(canary, c.totalWeight(canary), 0)
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseFinalising); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
 		}
-		return
+		return nil
 	}
 
 	// increment the primary traffic weight until it reaches total weight
@@ -541,7 +727,7 @@ func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryCo
 		}
 		if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 		c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
 		c.recordEventInfof(canary, "Advance %s.%s primary weight %v", canary.Name, canary.Namespace, primaryWeight)
@@ -558,12 +744,11 @@ func (c *Controller) runPromotionTrafficShift(canary *flaggerv1.Canary, canaryCo
 		}
 	}
 
-	return
-
+	return nil
 }
 
 func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary.Controller,
-	meshRouter router.Interface, mirrored bool, canaryWeight int, primaryWeight int, maxWeight int) {
+	meshRouter router.Interface, mirrored bool, canaryWeight int, primaryWeight int, maxWeight int) error {
 	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
 
 	// increase traffic weight
@@ -600,24 +785,42 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 
 		if err := meshRouter.SetRoutes(canary, primaryWeight, canaryWeight, mirrored); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 
 		if err := canaryController.SetStatusWeight(canary, canaryWeight); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
+		}
+
+		// enter the next analysis step once traffic has been shifted
+		if len(canary.GetAnalysis().Steps) > 0 && !mirrored {
+			step := 0
+			if canary.Status.StepStartTime != nil {
+				step = canary.Status.CurrentStep + 1
+			}
+			// the resume annotation is consumed when leaving the paused step
+			if canary.Status.StepStartTime != nil && canary.IsAnalysisStepResumed() {
+				c.clearResumeStep(canary)
+			}
+			// keep the in-memory status in sync with the weight update above
+			canary.Status.CanaryWeight = canaryWeight
+			if err := canaryController.SetStatusStep(canary, step, 0); err != nil {
+				c.recordEventWarningf(canary, "%v", err)
+				return err
+			}
 		}
 
 		c.recorder.SetWeight(canary, primaryWeight, canaryWeight)
 		c.recordEventInfof(canary, "Advance %s.%s canary weight %v", canary.Name, canary.Namespace, canaryWeight)
-		return
+		return nil
 	}
 
 	// promote canary - max weight reached
 	if canaryWeight >= maxWeight {
 		// check promotion gate
 		if promote := c.runConfirmPromotionHooks(canary, canaryController); !promote {
-			return
+			return nil
 		}
 
 		// update primary spec
@@ -625,41 +828,42 @@ func (c *Controller) runCanary(canary *flaggerv1.Canary, canaryController canary
 			canary.Spec.TargetRef.Name, canary.Namespace, primaryName, canary.Namespace)
 		if err := canaryController.Promote(canary); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 
 		// update status phase
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhasePromoting); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 	}
+	return nil
 }
 
 func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Controller,
-	meshRouter router.Interface) {
+	meshRouter router.Interface) error {
 	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
 
 	// route traffic to canary and increment iterations
 	if canary.GetAnalysis().Iterations > canary.Status.Iterations {
 		if err := meshRouter.SetRoutes(canary, 0, c.totalWeight(canary), false); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 		c.recorder.SetWeight(canary, 0, c.totalWeight(canary))
 
 		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v",
 			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations)
-		return
+		return nil
 	}
 
 	// check promotion gate
 	if promote := c.runConfirmPromotionHooks(canary, canaryController); !promote {
-		return
+		return nil
 	}
 
 	// promote canary - max iterations reached
@@ -668,19 +872,20 @@ func (c *Controller) runAB(canary *flaggerv1.Canary, canaryController canary.Con
 			canary.Spec.TargetRef.Name, canary.Namespace, primaryName, canary.Namespace)
 		if err := canaryController.Promote(canary); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 
 		// update status phase
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhasePromoting); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 	}
+	return nil
 }
 
 func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController canary.Controller,
-	meshRouter router.Interface, provider string, mirrored bool) {
+	meshRouter router.Interface, provider string, mirrored bool) error {
 	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
 
 	// increment iterations
@@ -690,22 +895,23 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 			canary.GetAnalysis().Mirror && !mirrored {
 			if err := meshRouter.SetRoutes(canary, c.totalWeight(canary), 0, true); err != nil {
 				c.recordEventWarningf(canary, "%v", err)
+				return err
 			}
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 				Infof("Start traffic mirroring")
 		}
 		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 		c.recordEventInfof(canary, "Advance %s.%s canary iteration %v/%v",
 			canary.Name, canary.Namespace, canary.Status.Iterations+1, canary.GetAnalysis().Iterations)
-		return
+		return nil
 	}
 
 	// check promotion gate
 	if promote := c.runConfirmPromotionHooks(canary, canaryController); !promote {
-		return
+		return nil
 	}
 
 	// route all traffic to canary - max iterations reached
@@ -718,7 +924,7 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 			}
 			if err := meshRouter.SetRoutes(canary, 0, c.totalWeight(canary), false); err != nil {
 				c.recordEventWarningf(canary, "%v", err)
-				return
+				return err
 			}
 			c.recorder.SetWeight(canary, 0, c.totalWeight(canary))
 		}
@@ -726,9 +932,9 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 		// increment iterations
 		if err := canaryController.SetStatusIterations(canary, canary.Status.Iterations+1); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
-		return
+		return nil
 	}
 
 	// promote canary - max iterations reached
@@ -737,42 +943,53 @@ func (c *Controller) runBlueGreen(canary *flaggerv1.Canary, canaryController can
 			canary.Spec.TargetRef.Name, canary.Namespace, primaryName, canary.Namespace)
 		if err := canaryController.Promote(canary); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 
 		// update status phase
 		if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhasePromoting); err != nil {
 			c.recordEventWarningf(canary, "%v", err)
-			return
+			return err
 		}
 	}
-
+	return nil
 }
 
-func (c *Controller) runAnalysis(canary *flaggerv1.Canary) bool {
+// runAnalysis runs the rollout webhooks and the metric checks of an iteration in parallel
+func (c *Controller) runAnalysis(ctx context.Context, canary *flaggerv1.Canary) checkResult {
+	webhooks := canary.GetAnalysis().Webhooks
+	if step := canary.GetAnalysisStep(); step != nil {
+		webhooks = append(webhooks[:len(webhooks):len(webhooks)], step.Webhooks...)
+	}
+
 	// run external checks
-	for _, webhook := range canary.GetAnalysis().Webhooks {
+	var checks []analysisCheck
+	for _, webhook := range webhooks {
 		if webhook.Type == "" || webhook.Type == flaggerv1.RolloutHook {
-			err := CallWebhook(*canary, flaggerv1.CanaryPhaseProgressing, webhook)
-			if err != nil {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement external check %s failed %v",
-					canary.Name, canary.Namespace, webhook.Name, err)
-				return false
-			}
+			checks = append(checks, analysisCheck{name: webhook.Name, run: func(ctx context.Context, rec *checkRecorder) checkResult {
+				err := CallWebhook(*canary, flaggerv1.CanaryPhaseProgressing, webhook)
+				if err != nil {
+					rec.warningf("Halt %s.%s advancement external check %s failed %v",
+						canary.Name, canary.Namespace, webhook.Name, err)
+					return checkFailed
+				}
+				return checkPassed
+			}})
 		}
 	}
 
-	ok := c.runBuiltinMetricChecks(canary)
+	builtinChecks, ok := c.builtinMetricChecks(canary)
 	if !ok {
-		return ok
+		return checkFailed
 	}
-
-	ok = c.runMetricChecks(canary)
+	metricChecks, ok := c.metricChecks(canary)
 	if !ok {
-		return ok
+		return checkFailed
 	}
+	checks = append(checks, builtinChecks...)
+	checks = append(checks, metricChecks...)
 
-	return true
+	return c.runChecks(ctx, canary, checks)
 }
 
 func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryController canary.Controller, meshRouter router.Interface, scalerReconciler canary.ScalerReconciler, err error, retriable bool) bool {
@@ -822,6 +1039,10 @@ func (c *Controller) shouldSkipAnalysis(canary *flaggerv1.Canary, canaryControll
 		c.recordEventWarningf(canary, "%v", err)
 		return true
 	}
+	if err := c.unpinRevision(canary); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return true
+	}
 
 	// update status phase
 	if err := canaryController.SetStatusPhase(canary, flaggerv1.CanaryPhaseSucceeded); err != nil {
@@ -866,6 +1087,11 @@ func (c *Controller) shouldAdvance(canary *flaggerv1.Canary, canaryController ca
 		}
 	}
 
+	// keep the rejected revision out of the analysis
+	if c.isRevisionRejected(canary) {
+		return false, nil
+	}
+
 	newTarget, err := canaryController.HasTargetChanged(canary)
 	if err != nil {
 		return false, err
@@ -883,7 +1109,8 @@ func (c *Controller) shouldAdvance(canary *flaggerv1.Canary, canaryController ca
 
 }
 
-func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryController canary.Controller, scalerReconciler canary.ScalerReconciler, shouldAdvance bool) bool {
+func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryController *canary.StatusBatch, meshRouter router.Interface,
+	scalerReconciler canary.ScalerReconciler, shouldAdvance bool, replicaRatio bool) bool {
 	c.recorder.SetStatus(canary, canary.Status.Phase)
 	if canary.Status.Phase == flaggerv1.CanaryPhaseProgressing ||
 		canary.Status.Phase == flaggerv1.CanaryPhaseWaitingPromotion ||
@@ -892,10 +1119,15 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 		return true
 	}
 
+	// the cached canary is stale when this run has already updated its status
+	name, namespace := canary.Name, canary.Namespace
+	if canaryController.Written() {
+		c.canaryWrites.Store(fmt.Sprintf("%s.%s", name, namespace), canary.ResourceVersion)
+	}
 	var err error
-	canary, err = c.flaggerClient.FlaggerV1beta1().Canaries(canary.Namespace).Get(context.TODO(), canary.Name, metav1.GetOptions{})
+	canary, err = c.getCanary(name, namespace)
 	if err != nil {
-		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+		c.logger.With("canary", fmt.Sprintf("%s.%s", name, namespace)).Errorf("%v", err)
 		return false
 	}
 
@@ -905,23 +1137,44 @@ func (c *Controller) checkCanaryStatus(canary *flaggerv1.Canary, canaryControlle
 			return false
 		}
 
+		// wait for a free slot when the number of progressing canaries is limited
+		if admitted, position := c.admitProgressing(canary); !admitted {
+			c.queueCanary(canary, canaryController, position)
+			return false
+		}
+
+		// make sure the new revision is rolled out
+		if err := c.unpinRevision(canary); err != nil {
+			c.recordEventWarningf(canary, "%v", err)
+			return false
+		}
+
 		canaryPhaseProgressing := canary.DeepCopy()
 		canaryPhaseProgressing.Status.Phase = flaggerv1.CanaryPhaseProgressing
 		c.recordEventInfof(canaryPhaseProgressing, "New revision detected! Scaling up %s.%s", canaryPhaseProgressing.Spec.TargetRef.Name, canaryPhaseProgressing.Namespace)
 		c.alert(canaryPhaseProgressing, "New revision detected, progressing canary analysis.",
 			true, flaggerv1.SeverityInfo)
 
-		if scalerReconciler != nil {
-			err = scalerReconciler.ResumeTargetScaler(canary)
-			if err != nil {
-				c.recordEventWarningf(canary, "%v", err)
+		// the canary autoscaler stays paused when the replicas are driven by the canary weight
+		if replicaRatio {
+			if err := meshRouter.SetRoutes(canary, c.totalWeight(canary), 0, false); err != nil {
+				c.recordEventErrorf(canary, "%v", err)
+				return false
+			}
+		} else {
+			if scalerReconciler != nil {
+				err = scalerReconciler.ResumeTargetScaler(canary)
+				if err != nil {
+					c.recordEventWarningf(canary, "%v", err)
+					return false
+				}
+			}
+			if err := canaryController.ScaleFromZero(canary); err != nil {
+				c.recordEventErrorf(canary, "%v", err)
 				return false
 			}
 		}
-		if err := canaryController.ScaleFromZero(canary); err != nil {
-			c.recordEventErrorf(canary, "%v", err)
-			return false
-		}
+		c.clearResumeStep(canary)
 		if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseProgressing}); err != nil {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 			return false
@@ -980,9 +1233,15 @@ func (c *Controller) rollback(canary *flaggerv1.Canary, canaryController canary.
 		c.recordEventWarningf(canary, "%v", err)
 		return
 	}
+	if err := c.unpinRevision(canary); err != nil {
+		c.recordEventWarningf(canary, "%v", err)
+		return
+	}
+	c.clearResumeStep(canary)
 
 	// mark canary as failed
-	if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{Phase: flaggerv1.CanaryPhaseFailed, CanaryWeight: 0}); err != nil {
+	if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{
+		Phase: flaggerv1.CanaryPhaseFailed, CanaryWeight: 0, PendingRevision: canary.Status.PendingRevision}); err != nil {
 		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 		return
 	}
@@ -1019,7 +1278,7 @@ func (c *Controller) handleFailedPromotion(canary *flaggerv1.Canary, canaryContr
 
 	// mark as failed while reporting the weight that matches the routing
 	if err := canaryController.SyncStatus(canary, flaggerv1.CanaryStatus{
-		Phase: flaggerv1.CanaryPhaseFailed, CanaryWeight: canaryWeight}); err != nil {
+		Phase: flaggerv1.CanaryPhaseFailed, CanaryWeight: canaryWeight, PendingRevision: canary.Status.PendingRevision}); err != nil {
 		c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
 		return
 	}
@@ -0,0 +1,148 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// maxConcurrentChecks is the number of checks run in parallel within an analysis iteration
+const maxConcurrentChecks = 10
+
+// checkResult is the outcome of the analysis checks
+type checkResult int
+
+const (
+	checkPassed checkResult = iota
+	checkFailed
+	// checkTransient means that a check could not be evaluated due to a transient
+	// provider error, the iteration is retried without counting as a failed check
+	checkTransient
+)
+
+// analysisCheck is a webhook or metric check of an analysis iteration
+type analysisCheck struct {
+	name string
+	run  func(ctx context.Context, rec *checkRecorder) checkResult
+}
+
+// checkRecorder buffers the events of a check, so that the events of
+// the checks running in parallel are recorded in the analysis order
+type checkRecorder struct {
+	canary *flaggerv1.Canary
+	events []checkEvent
+}
+
+type checkEvent struct {
+	isError bool
+	message string
+}
+
+func (r *checkRecorder) warningf(template string, args ...interface{}) {
+	r.events = append(r.events, checkEvent{message: fmt.Sprintf(template, args...)})
+}
+
+func (r *checkRecorder) errorf(template string, args ...interface{}) {
+	r.events = append(r.events, checkEvent{isError: true, message: fmt.Sprintf(template, args...)})
+}
+
+// transientError records a warning for a metric check that could not be evaluated
+func (r *checkRecorder) transientError(metric string, err error) checkResult {
+	r.warningf("Halt %s.%s advancement %s query failed with a transient error: %v",
+		r.canary.Name, r.canary.Namespace, metric, err)
+	return checkTransient
+}
+
+// runChecks runs the checks in parallel, bounded by maxConcurrentChecks, and waits for
+// the results until the iteration deadline derived from the analysis interval.
+// All the checks are evaluated and their events are recorded in order,
+// the iteration fails if any check fails or doesn't complete before the deadline.
+func (c *Controller) runChecks(ctx context.Context, canary *flaggerv1.Canary, checks []analysisCheck) checkResult {
+	if len(checks) == 0 {
+		return checkPassed
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, canary.GetAnalysisInterval())
+	defer cancel()
+
+	type outcome struct {
+		index  int
+		result checkResult
+		rec    *checkRecorder
+	}
+
+	done := make(chan outcome, len(checks))
+	workers := make(chan struct{}, maxConcurrentChecks)
+	for i, check := range checks {
+		go func() {
+			select {
+			case workers <- struct{}{}:
+				defer func() { <-workers }()
+			case <-ctx.Done():
+				return
+			}
+			rec := &checkRecorder{canary: canary}
+			done <- outcome{index: i, result: check.run(ctx, rec), rec: rec}
+		}()
+	}
+
+	outcomes := make([]*outcome, len(checks))
+	for received := 0; received < len(checks); received++ {
+		select {
+		case o := <-done:
+			outcomes[o.index] = &o
+		case <-ctx.Done():
+			received = len(checks)
+		}
+	}
+
+	// collect the checks that completed at the deadline
+	for drained := false; !drained; {
+		select {
+		case o := <-done:
+			outcomes[o.index] = &o
+		default:
+			drained = true
+		}
+	}
+
+	result := checkPassed
+	for i, o := range outcomes {
+		if o == nil {
+			c.recordEventWarningf(canary, "Halt %s.%s advancement check %s did not complete before the iteration deadline",
+				canary.Name, canary.Namespace, checks[i].name)
+			result = checkFailed
+			continue
+		}
+
+		for _, event := range o.rec.events {
+			if event.isError {
+				c.recordEventErrorf(canary, "%s", event.message)
+			} else {
+				c.recordEventWarningf(canary, "%s", event.message)
+			}
+		}
+
+		if o.result == checkFailed || o.result == checkTransient && result == checkPassed {
+			result = o.result
+		}
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/record"
+)
+
+func newCheck(name string, delay time.Duration, result checkResult) analysisCheck {
+	return analysisCheck{name: name, run: func(ctx context.Context, rec *checkRecorder) checkResult {
+		time.Sleep(delay)
+		if result != checkPassed {
+			rec.warningf("check %s result %d", name, result)
+		}
+		return result
+	}}
+}
+
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestController_runChecks(t *testing.T) {
+	t.Run("parallel", func(t *testing.T) {
+		mocks := newDeploymentFixture(nil)
+		recorder := record.NewFakeRecorder(10)
+		mocks.ctrl.eventRecorder = recorder
+
+		var checks []analysisCheck
+		for i := 0; i < 5; i++ {
+			checks = append(checks, newCheck(fmt.Sprintf("check-%d", i), 200*time.Millisecond, checkPassed))
+		}
+
+		start := time.Now()
+		assert.Equal(t, checkPassed, mocks.ctrl.runChecks(context.TODO(), mocks.canary, checks))
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Empty(t, drainEvents(recorder))
+	})
+
+	t.Run("all failures reported in order", func(t *testing.T) {
+		mocks := newDeploymentFixture(nil)
+		recorder := record.NewFakeRecorder(10)
+		mocks.ctrl.eventRecorder = recorder
+
+		checks := []analysisCheck{
+			newCheck("slow", 100*time.Millisecond, checkFailed),
+			newCheck("transient", 0, checkTransient),
+			newCheck("passed", 0, checkPassed),
+			newCheck("fast", 0, checkFailed),
+		}
+
+		assert.Equal(t, checkFailed, mocks.ctrl.runChecks(context.TODO(), mocks.canary, checks))
+
+		events := drainEvents(recorder)
+		if assert.Len(t, events, 3) {
+			assert.Contains(t, events[0], "check slow")
+			assert.Contains(t, events[1], "check transient")
+			assert.Contains(t, events[2], "check fast")
+		}
+	})
+
+	t.Run("transient", func(t *testing.T) {
+		mocks := newDeploymentFixture(nil)
+
+		checks := []analysisCheck{
+			newCheck("passed", 0, checkPassed),
+			newCheck("transient", 0, checkTransient),
+		}
+
+		assert.Equal(t, checkTransient, mocks.ctrl.runChecks(context.TODO(), mocks.canary, checks))
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		mocks := newDeploymentFixture(nil)
+		recorder := record.NewFakeRecorder(10)
+		mocks.ctrl.eventRecorder = recorder
+
+		checks := []analysisCheck{
+			newCheck("passed", 0, checkPassed),
+			newCheck("stuck", 2*time.Second, checkPassed),
+		}
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, checkFailed, mocks.ctrl.runChecks(ctx, mocks.canary, checks))
+
+		events := drainEvents(recorder)
+		if assert.Len(t, events, 1) {
+			assert.Contains(t, events[0], "check stuck did not complete before the iteration deadline")
+		}
+	})
+}
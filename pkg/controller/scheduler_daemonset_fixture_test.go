@@ -17,6 +17,7 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
@@ -40,6 +42,7 @@ import (
 	"github.com/fluxcd/flagger/pkg/logger"
 	"github.com/fluxcd/flagger/pkg/metrics"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
 	"github.com/fluxcd/flagger/pkg/notifier"
 	"github.com/fluxcd/flagger/pkg/router"
 )
@@ -89,10 +92,11 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
 		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
 		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		SecretInformer: kubeinformers.NewSharedInformerFactory(kubeClient, 0).Core().V1().Secrets(),
 	}
 
 	// init router
-	rf := router.NewFactory(nil, kubeClient, flaggerClient, nil, "annotationsPrefix", "", logger, flaggerClient, true)
+	rf := router.NewFactory(nil, kubeClient, flaggerClient, nil, "annotationsPrefix", "", logger, flaggerClient, true, false)
 
 	// init observer
 	observerFactory, _ := observers.NewFactory(testMetricsServerURL)
@@ -103,7 +107,7 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		KubeClient:    kubeClient,
 		FlaggerClient: flaggerClient,
 	}
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, nil, configTracker, []string{"app", "name"}, []string{""}, logger)
+	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, nil, configTracker, []string{"app", "name"}, []string{""}, nil, logger)
 
 	ctrl := &Controller{
 		kubeClient:       kubeClient,
@@ -114,14 +118,20 @@ func newDaemonSetFixture(c *flaggerv1.Canary) daemonSetFixture {
 		eventRecorder:    &record.FakeRecorder{},
 		logger:           logger,
 		canaries:         new(sync.Map),
+		analysisQueue:    newAnalysisQueue(controllerAgentName + "-analysis"),
 		flaggerWindow:    time.Second,
 		canaryFactory:    canaryFactory,
 		observerFactory:  observerFactory,
+		providerPool:     providers.NewPool(providers.NewFactory(logger)),
 		recorder:         metrics.NewRecorder(controllerAgentName, false),
 		routerFactory:    rf,
 		notifier:         &notifier.NopNotifier{},
 	}
 	ctrl.flaggerSynced = alwaysReady
+	secrets, _ := kubeClient.CoreV1().Secrets("").List(context.TODO(), metav1.ListOptions{})
+	for i := range secrets.Items {
+		ctrl.flaggerInformers.SecretInformer.Informer().GetIndexer().Add(&secrets.Items[i])
+	}
 	ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(c)
 	ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(newDaemonSetTestMetricTemplate())
 	ctrl.flaggerInformers.AlertInformer.Informer().GetIndexer().Add(newDaemonSetTestAlertProvider())
@@ -30,6 +30,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
@@ -45,6 +46,7 @@ import (
 	"github.com/fluxcd/flagger/pkg/logger"
 	"github.com/fluxcd/flagger/pkg/metrics"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
 	"github.com/fluxcd/flagger/pkg/notifier"
 	"github.com/fluxcd/flagger/pkg/router"
 )
@@ -82,6 +84,34 @@ func (f fixture) makeReady(t *testing.T, name string) {
 	require.NoError(t, err)
 }
 
+// makePinnedReplicaSet creates the replica set running the current canary template
+func (f fixture) makePinnedReplicaSet(t *testing.T) {
+	dep, err := f.kubeClient.AppsV1().
+		Deployments("default").
+		Get(context.TODO(), f.canary.Spec.TargetRef.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	template := dep.Spec.Template.DeepCopy()
+	template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = "pinned"
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: dep.Namespace,
+			Name:      fmt.Sprintf("%s-pinned", dep.Name),
+			Labels:    template.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(dep, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+			},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Selector: dep.Spec.Selector,
+			Template: *template,
+		},
+	}
+
+	_, err = f.kubeClient.AppsV1().ReplicaSets("default").Create(context.TODO(), rs, metav1.CreateOptions{})
+	require.NoError(t, err)
+}
+
 // makePrimaryNotReady puts the primary into a stuck rollout (progress deadline exceeded).
 func (f fixture) makePrimaryNotReady(t *testing.T) {
 	primaryName := fmt.Sprintf("%s-primary", f.canary.Spec.TargetRef.Name)
@@ -151,10 +181,11 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		CanaryInformer: flaggerInformerFactory.Flagger().V1beta1().Canaries(),
 		MetricInformer: flaggerInformerFactory.Flagger().V1beta1().MetricTemplates(),
 		AlertInformer:  flaggerInformerFactory.Flagger().V1beta1().AlertProviders(),
+		SecretInformer: kubeinformers.NewSharedInformerFactory(kubeClient, 0).Core().V1().Secrets(),
 	}
 
 	// init router
-	rf := router.NewFactory(nil, kubeClient, flaggerClient, nil, "annotationsPrefix", "", logger, flaggerClient, true)
+	rf := router.NewFactory(nil, kubeClient, flaggerClient, nil, "annotationsPrefix", "", logger, flaggerClient, true, false)
 
 	// init observer
 	observerFactory, _ := observers.NewFactory(testMetricsServerURL)
@@ -165,7 +196,7 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		KubeClient:    kubeClient,
 		FlaggerClient: flaggerClient,
 	}
-	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, nil, configTracker, []string{"app", "name"}, []string{""}, logger)
+	canaryFactory := canary.NewFactory(kubeClient, flaggerClient, nil, configTracker, []string{"app", "name"}, []string{""}, nil, logger)
 
 	ctrl := &Controller{
 		kubeClient:       kubeClient,
@@ -176,14 +207,20 @@ func newDeploymentFixture(c *flaggerv1.Canary) fixture {
 		eventRecorder:    &record.FakeRecorder{},
 		logger:           logger,
 		canaries:         new(sync.Map),
+		analysisQueue:    newAnalysisQueue(controllerAgentName + "-analysis"),
 		flaggerWindow:    time.Second,
 		canaryFactory:    canaryFactory,
 		observerFactory:  observerFactory,
+		providerPool:     providers.NewPool(providers.NewFactory(logger)),
 		recorder:         metrics.NewRecorder(controllerAgentName, false),
 		routerFactory:    rf,
 		notifier:         &notifier.NopNotifier{},
 	}
 	ctrl.flaggerSynced = alwaysReady
+	secrets, _ := kubeClient.CoreV1().Secrets("").List(context.TODO(), metav1.ListOptions{})
+	for i := range secrets.Items {
+		ctrl.flaggerInformers.SecretInformer.Informer().GetIndexer().Add(&secrets.Items[i])
+	}
 	ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Add(c)
 	ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(newDeploymentTestMetricTemplate())
 	ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(newDeploymentTestMetricTemplateCustomVars())
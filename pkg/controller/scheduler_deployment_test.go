@@ -24,14 +24,22 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sTesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+	fakeFlagger "github.com/fluxcd/flagger/pkg/client/clientset/versioned/fake"
 	"github.com/fluxcd/flagger/pkg/notifier"
+	"github.com/fluxcd/flagger/pkg/sharding"
 )
 
 func TestScheduler_DeploymentInit(t *testing.T) {
@@ -329,6 +337,157 @@ func TestScheduler_DeploymentAnalysisPhases(t *testing.T) {
 	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 0))
 }
 
+func TestScheduler_DeploymentAnalysisSteps(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis = &flaggerv1.CanaryAnalysis{
+		Interval:  "1m",
+		Threshold: 10,
+		Steps: []flaggerv1.CanaryStep{
+			{Weight: 10, Iterations: 2},
+			{Weight: 50, Pause: true},
+			{Weight: 100},
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+	mocks.makeCanaryReady(t)
+
+	// enter first step
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 10))
+
+	// hold first step until two checks passed
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 10))
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, c.Status.CurrentStep)
+	assert.Equal(t, 1, c.Status.StepIterations)
+
+	// enter paused step
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 50))
+
+	// hold paused step
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 50))
+
+	// resume paused step
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Status.CurrentStep)
+	c.Annotations = map[string]string{flaggerv1.ResumeStepAnnotation: "1"}
+	_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// enter last step
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 100))
+
+	// start promotion
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
+}
+
+func TestScheduler_DeploymentAnalysisStepsResumeOnce(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis = &flaggerv1.CanaryAnalysis{
+		Interval:  "1m",
+		Threshold: 10,
+		Steps: []flaggerv1.CanaryStep{
+			{Weight: 50, Pause: true},
+			{Weight: 100},
+		},
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	resume := func() {
+		c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		c.Annotations = map[string]string{flaggerv1.ResumeStepAnnotation: "0"}
+		_, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Update(context.TODO(), c, metav1.UpdateOptions{})
+		require.NoError(t, err)
+	}
+
+	// first revision
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// enter and hold the paused step
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 50))
+
+	// the annotation is removed once the paused step is resumed
+	resume()
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 100))
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, c.Annotations, flaggerv1.ResumeStepAnnotation)
+
+	// complete the analysis
+	for i := 0; i < 10 && c.Status.Phase != flaggerv1.CanaryPhaseSucceeded; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+		c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+	}
+	require.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+
+	// a leftover annotation doesn't resume the next analysis
+	resume()
+
+	// second revision
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Template.Spec.ServiceAccountName = "test"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+	mocks.makeCanaryReady(t)
+
+	// the paused step holds again
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 50))
+
+	// until it is resumed for this revision
+	resume()
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 100))
+}
+
 func TestScheduler_DeploymentBlueGreenAnalysisPhases(t *testing.T) {
 	cd := newDeploymentTestCanary()
 	cd.Spec.Analysis = &flaggerv1.CanaryAnalysis{
@@ -378,6 +537,81 @@ func TestScheduler_DeploymentBlueGreenAnalysisPhases(t *testing.T) {
 	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseSucceeded))
 }
 
+func TestScheduler_DeploymentReplicaRatioAnalysisPhases(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Provider = flaggerv1.KubernetesProvider
+	cd.Spec.Analysis = &flaggerv1.CanaryAnalysis{
+		Interval:     "1m",
+		StepWeight:   50,
+		MaxWeight:    50,
+		ReplicaRatio: true,
+	}
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the primary pods carry the apex label
+	p, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "podinfo", p.Spec.Template.Labels[flaggerv1.ApexLabel])
+
+	// make primary ready
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseInitialized))
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 0))
+	mocks.makeCanaryReady(t)
+
+	// the apex label injected in the canary pods is not a new revision
+	c, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *c.Spec.Replicas)
+	assert.Equal(t, "podinfo", c.Spec.Template.Labels[flaggerv1.ApexLabel])
+
+	// progressing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseProgressing))
+	require.NoError(t, assertCanaryWeight(mocks.flaggerClient, "podinfo", 50))
+
+	apex, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{flaggerv1.ApexLabel: "podinfo"}, apex.Spec.Selector)
+
+	// promoting
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhasePromoting))
+
+	// finalising
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseFinalising))
+
+	// succeeded
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	require.NoError(t, assertPhase(mocks.flaggerClient, "podinfo", flaggerv1.CanaryPhaseSucceeded))
+
+	c, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *c.Spec.Replicas)
+
+	// route traffic back to primary
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	apex, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "podinfo-primary"}, apex.Spec.Selector)
+}
+
 func TestScheduler_DeploymentNewRevisionReset(t *testing.T) {
 	mocks := newDeploymentFixture(nil)
 	// init
@@ -773,3 +1007,370 @@ func TestScheduler_DeploymentAlerts(t *testing.T) {
 	// initialization done - now send alert
 	mocks.ctrl.advanceCanary("podinfo", "default")
 }
+
+func TestScheduler_DeploymentCanaryCacheRead(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informer := mocks.ctrl.flaggerInformers.CanaryInformer.Informer()
+	go informer.Run(stopCh)
+	require.True(t, cache.WaitForCacheSync(stopCh, informer.HasSynced))
+
+	// serve the canary from the informer cache
+	cached, err := mocks.ctrl.getCanary("podinfo", "default")
+	require.NoError(t, err)
+	stale := cached.DeepCopy()
+	stale.ResourceVersion = "1"
+	stale.Spec.Suspend = true
+	require.NoError(t, informer.GetIndexer().Update(stale))
+
+	cd, err := mocks.ctrl.getCanary("podinfo", "default")
+	require.NoError(t, err)
+	assert.True(t, cd.Spec.Suspend)
+
+	// the cache still holds the object seen before the last status update
+	mocks.ctrl.canaryWrites.Store("podinfo.default", "1")
+	cd, err = mocks.ctrl.getCanary("podinfo", "default")
+	require.NoError(t, err)
+	assert.False(t, cd.Spec.Suspend)
+
+	// the cache caught up with the status update
+	mocks.ctrl.canaryWrites.Store("podinfo.default", "0")
+	cd, err = mocks.ctrl.getCanary("podinfo", "default")
+	require.NoError(t, err)
+	assert.True(t, cd.Spec.Suspend)
+	_, ok := mocks.ctrl.canaryWrites.Load("podinfo.default")
+	assert.False(t, ok)
+}
+
+func TestScheduler_DeploymentNewRevisionCacheRead(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informer := mocks.ctrl.flaggerInformers.CanaryInformer.Informer()
+	go informer.Run(stopCh)
+	require.True(t, cache.WaitForCacheSync(stopCh, informer.HasSynced))
+
+	// wait for the cache to hold the last status update, the fake clientset doesn't set
+	// resource versions so the write recorded by the previous run is dropped by hand
+	current, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		cd, err := mocks.ctrl.flaggerInformers.CanaryInformer.Lister().Canaries("default").Get("podinfo")
+		return err == nil && cd.Status.Phase == current.Status.Phase && cd.Status.LastAppliedSpec == current.Status.LastAppliedSpec
+	}, 5*time.Second, 10*time.Millisecond)
+	mocks.ctrl.canaryWrites.Delete("podinfo.default")
+
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// the run that starts the analysis reads the canary from the cache
+	fakeClient := mocks.flaggerClient.(*fakeFlagger.Clientset)
+	fakeClient.ClearActions()
+	require.NoError(t, mocks.ctrl.advanceCanary("podinfo", "default"))
+	for _, action := range fakeClient.Actions() {
+		assert.False(t, action.GetVerb() == "get" && action.GetResource().Resource == "canaries",
+			"canary read from the API")
+	}
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+}
+
+func TestScheduler_DeploymentShard(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	selector, err := labels.Parse("shard=blue")
+	require.NoError(t, err)
+	mocks.ctrl.shard = sharding.NewShard(mocks.kubeClient, "flagger-system", "flagger", "flagger-0", selector, false, 15*time.Second, mocks.logger)
+
+	// canaries outside the shard are not reconciled
+	require.NoError(t, mocks.ctrl.syncHandler("default/podinfo"))
+	_, ok := mocks.ctrl.canaries.Load("podinfo.default")
+	assert.False(t, ok)
+
+	mocks.ctrl.canaries.Store("podinfo.default", mocks.canary)
+	mocks.ctrl.analysisQueue.Add("default/podinfo")
+	assert.True(t, mocks.ctrl.processNextAnalysis())
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	// the canary moves to the shard when it is labeled
+	cd := mocks.canary.DeepCopy()
+	cd.Labels = map[string]string{"shard": "blue"}
+	require.NoError(t, mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer().Update(cd))
+	require.NoError(t, mocks.ctrl.syncHandler("default/podinfo"))
+	_, ok = mocks.ctrl.canaries.Load("podinfo.default")
+	assert.True(t, ok)
+
+	assert.True(t, mocks.ctrl.processNextAnalysis())
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestScheduler_DeploymentRevisionPolicyFinish(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.RevisionPolicy = flaggerv1.RevisionPolicyFinish
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// first update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance and pin the analysed revision
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, dep.Spec.Paused)
+	mocks.makePinnedReplicaSet(t)
+
+	// second update
+	dep.Spec.Template.Spec.ServiceAccountName = "test"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes without restarting the analysis
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, canary.ComputeHash(dep.Spec.Template), c.Status.PendingRevision)
+	_, canaryWeight, _, err := mocks.router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 20, canaryWeight)
+
+	// complete the analysis
+	for i := 0; i < 10 && c.Status.Phase != flaggerv1.CanaryPhaseSucceeded; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+		c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+	}
+	require.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+
+	// the analysed revision is promoted
+	primaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dep2.Spec.Template.Spec.Containers[0].Image, primaryDep.Spec.Template.Spec.Containers[0].Image)
+	assert.Empty(t, primaryDep.Spec.Template.Spec.ServiceAccountName)
+
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, dep.Spec.Paused)
+
+	// start the analysis of the latest revision
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Empty(t, c.Status.PendingRevision)
+	assert.Equal(t, canary.ComputeHash(dep.Spec.Template), c.Status.LastAppliedSpec)
+}
+
+func TestScheduler_DeploymentRevisionPolicyReject(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.RevisionPolicy = flaggerv1.RevisionPolicyReject
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// first update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance and pin the analysed revision
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePinnedReplicaSet(t)
+
+	// second update
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Template.Spec.ServiceAccountName = "test"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// complete the analysis
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	for i := 0; i < 10 && c.Status.Phase != flaggerv1.CanaryPhaseSucceeded; i++ {
+		mocks.ctrl.advanceCanary("podinfo", "default")
+		c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+	}
+	require.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+	assert.Equal(t, canary.ComputeHash(dep.Spec.Template), c.Status.PendingRevision)
+
+	// the rejected revision doesn't start an analysis
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseSucceeded, c.Status.Phase)
+
+	// a newer revision starts an analysis
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Spec.Template.Spec.ServiceAccountName = "test2"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Empty(t, c.Status.PendingRevision)
+}
+
+func TestScheduler_DeploymentRouterError(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	require.NoError(t, mocks.ctrl.advanceCanary("podinfo", "default"))
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	require.NoError(t, mocks.ctrl.advanceCanary("podinfo", "default"))
+	mocks.makeCanaryReady(t)
+
+	// the failed traffic shift is returned to the analysis queue backoff
+	mocks.flaggerClient.(*fakeFlagger.Clientset).PrependReactor("update", "virtualservices",
+		func(action k8sTesting.Action) (bool, runtime.Object, error) {
+			return true, nil, fmt.Errorf("virtual service update failed")
+		})
+	err = mocks.ctrl.advanceCanary("podinfo", "default")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "virtual service update failed")
+}
+
+func TestScheduler_DeploymentStalePin(t *testing.T) {
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.RevisionPolicy = flaggerv1.RevisionPolicyFinish
+	mocks := newDeploymentFixture(cd)
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// leave the deployment pinned as if Flagger was stopped during an analysis
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	dep.Annotations = map[string]string{"flagger.app/pinned-revision": "stale"}
+	dep.Spec.Paused = true
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// the pin is released outside of an analysis
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, dep.Spec.Paused)
+	assert.NotContains(t, dep.Annotations, "flagger.app/pinned-revision")
+}
+
+func TestScheduler_DeploymentTransientChecks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	cd := newDeploymentTestCanary()
+	cd.Spec.Analysis.Threshold = 2
+	cd.Spec.Analysis.Metrics = []flaggerv1.CanaryMetric{{
+		Name:        "unavailable",
+		Interval:    "1m",
+		Threshold:   1,
+		TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "unavailable"},
+	}}
+	mocks := newDeploymentFixture(cd)
+	template := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unavailable"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+			Query:    "vector(1)",
+		},
+	}
+	require.NoError(t, mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makeCanaryReady(t)
+
+	// advance to the first step
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// the transient error is not counted as a failed check
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Status.TransientChecks)
+	assert.Equal(t, 0, c.Status.FailedChecks)
+
+	// the transient error persisted for threshold iterations
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.Status.TransientChecks)
+	assert.Equal(t, 1, c.Status.FailedChecks)
+
+	// roll back once the failed checks threshold is reached
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseFailed, c.Status.Phase)
+	assert.Equal(t, 0, c.Status.TransientChecks)
+}
@@ -24,7 +24,12 @@ import (
 )
 
 func (c *Controller) runConfirmTrafficIncreaseHooks(canary *flaggerv1.Canary) bool {
-	for _, webhook := range canary.GetAnalysis().Webhooks {
+	webhooks := canary.GetAnalysis().Webhooks
+	if step := canary.GetAnalysisStep(); step != nil {
+		webhooks = append(webhooks[:len(webhooks):len(webhooks)], step.Webhooks...)
+	}
+
+	for _, webhook := range webhooks {
 		if webhook.Type == flaggerv1.ConfirmTrafficIncreaseHook {
 			err := CallWebhook(*canary, flaggerv1.CanaryPhaseProgressing, webhook)
 			if err != nil {
@@ -55,6 +60,11 @@ func (c *Controller) runConfirmRolloutHooks(canary *flaggerv1.Canary, canaryCont
 					if !webhook.MuteAlert {
 						c.alert(canary, "Canary is waiting for approval.", false, flaggerv1.SeverityWarn)
 					}
+				} else if canary.Status.QueuePosition > 0 {
+					// leave the progressing queue until the rollout is approved again
+					if err := canaryController.SetStatusQueuePosition(canary, 0); err != nil {
+						c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).Errorf("%v", err)
+					}
 				}
 				return false
 			}
@@ -0,0 +1,150 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+)
+
+// progressingAdmissionTTL is how long an admitted canary holds its slot
+// while the informer cache hasn't observed its progressing phase yet
+const progressingAdmissionTTL = time.Minute
+
+// holdsProgressingSlot returns true if the canary phase counts towards the progressing limits
+func holdsProgressingSlot(phase flaggerv1.CanaryPhase) bool {
+	return phase == flaggerv1.CanaryPhaseProgressing ||
+		phase == flaggerv1.CanaryPhaseWaitingPromotion ||
+		phase == flaggerv1.CanaryPhasePromoting ||
+		phase == flaggerv1.CanaryPhaseFinalising
+}
+
+// admitProgressing decides if the canary can start progressing without exceeding the global and
+// per namespace limits. The canaries waiting for a slot are ordered by priority, then by their
+// position in the queue. When the canary has to wait, its 1-based queue position is returned.
+// In sharding mode the limits apply to the canaries owned by this replica, as the admissions
+// of the other replicas aren't visible until their status reaches the informer cache.
+func (c *Controller) admitProgressing(cd *flaggerv1.Canary) (bool, int) {
+	if c.progressingLimit <= 0 && c.progressingNsLimit <= 0 {
+		return true, 0
+	}
+
+	c.progressingMu.Lock()
+	defer c.progressingMu.Unlock()
+	if c.progressingAdmitted == nil {
+		c.progressingAdmitted = make(map[string]time.Time)
+	}
+
+	canaries, err := c.flaggerInformers.CanaryInformer.Lister().List(labels.Everything())
+	if err != nil {
+		c.logger.Errorf("Canary list query failed: %v", err)
+		return true, 0
+	}
+
+	now := time.Now()
+	self := canaryKey(cd)
+	global := 0
+	namespaced := make(map[string]int)
+	queue := []*flaggerv1.Canary{cd}
+	for _, item := range canaries {
+		key := canaryKey(item)
+		if key == self || !c.ownsCanary(item) {
+			continue
+		}
+		if holdsProgressingSlot(item.Status.Phase) {
+			delete(c.progressingAdmitted, key)
+			global++
+			namespaced[item.Namespace]++
+			continue
+		}
+		if admitted, ok := c.progressingAdmitted[key]; ok {
+			if now.Sub(admitted) < progressingAdmissionTTL {
+				global++
+				namespaced[item.Namespace]++
+				continue
+			}
+			delete(c.progressingAdmitted, key)
+		}
+		if item.Status.Phase == flaggerv1.CanaryPhaseWaiting && item.Status.QueuePosition > 0 && !item.Spec.Suspend {
+			queue = append(queue, item)
+		}
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		a, b := queue[i], queue[j]
+		if a.Spec.Priority != b.Spec.Priority {
+			return a.Spec.Priority > b.Spec.Priority
+		}
+		// the queued canaries keep their order, the new ones are added at the end
+		if (a.Status.QueuePosition > 0) != (b.Status.QueuePosition > 0) {
+			return a.Status.QueuePosition > 0
+		}
+		if a.Status.QueuePosition != b.Status.QueuePosition {
+			return a.Status.QueuePosition < b.Status.QueuePosition
+		}
+		return canaryKey(a) < canaryKey(b)
+	})
+
+	for i, item := range queue {
+		fits := (c.progressingLimit <= 0 || global < c.progressingLimit) &&
+			(c.progressingNsLimit <= 0 || namespaced[item.Namespace] < c.progressingNsLimit)
+		if canaryKey(item) == self {
+			if !fits {
+				return false, i + 1
+			}
+			c.progressingAdmitted[self] = now
+			return true, 0
+		}
+		// the canaries ahead in the queue take the free slots first
+		if fits {
+			global++
+			namespaced[item.Namespace]++
+		}
+	}
+	return true, 0
+}
+
+// queueCanary sets the canary phase to waiting and records its position in the progressing queue
+func (c *Controller) queueCanary(cd *flaggerv1.Canary, canaryController canary.Controller, position int) {
+	if cd.Status.Phase != flaggerv1.CanaryPhaseWaiting {
+		queued := cd.DeepCopy()
+		queued.Status.QueuePosition = position
+		if err := canaryController.SetStatusPhase(queued, flaggerv1.CanaryPhaseWaiting); err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("%v", err)
+			return
+		}
+		c.recordEventInfof(cd, "Halt %s.%s advancement waiting for a progressing slot, queue position %d",
+			cd.Name, cd.Namespace, position)
+		return
+	}
+
+	if cd.Status.QueuePosition != position {
+		if err := canaryController.SetStatusQueuePosition(cd, position); err != nil {
+			c.logger.With("canary", fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)).Errorf("%v", err)
+		}
+	}
+}
+
+func canaryKey(cd *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s.%s", cd.Name, cd.Namespace)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/sharding"
+)
+
+func newLimitsTestCanary(namespace string, name string, phase flaggerv1.CanaryPhase, position int, priority int) *flaggerv1.Canary {
+	return &flaggerv1.Canary{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       flaggerv1.CanarySpec{Priority: priority},
+		Status:     flaggerv1.CanaryStatus{Phase: phase, QueuePosition: position},
+	}
+}
+
+func TestScheduler_AdmitProgressing(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.progressingLimit = 2
+	indexer := mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer()
+	require.NoError(t, indexer.Delete(mocks.canary))
+	require.NoError(t, indexer.Add(newLimitsTestCanary("ns1", "a", flaggerv1.CanaryPhaseProgressing, 0, 0)))
+	require.NoError(t, indexer.Add(newLimitsTestCanary("ns1", "b", flaggerv1.CanaryPhaseWaiting, 1, 0)))
+	require.NoError(t, indexer.Add(newLimitsTestCanary("ns2", "c", flaggerv1.CanaryPhaseWaiting, 2, 0)))
+
+	// the new canary waits behind the queued ones
+	admitted, position := mocks.ctrl.admitProgressing(newLimitsTestCanary("ns3", "d", flaggerv1.CanaryPhaseSucceeded, 0, 0))
+	assert.False(t, admitted)
+	assert.Equal(t, 3, position)
+
+	// the queued canaries keep their order
+	admitted, position = mocks.ctrl.admitProgressing(newLimitsTestCanary("ns2", "c", flaggerv1.CanaryPhaseWaiting, 2, 0))
+	assert.False(t, admitted)
+	assert.Equal(t, 2, position)
+
+	// a higher priority jumps the queue and takes the free slot
+	e := newLimitsTestCanary("ns3", "e", flaggerv1.CanaryPhaseSucceeded, 0, 10)
+	require.NoError(t, indexer.Add(e))
+	admitted, _ = mocks.ctrl.admitProgressing(e)
+	assert.True(t, admitted)
+
+	// the admitted canary holds its slot until the cache observes its progressing phase
+	admitted, position = mocks.ctrl.admitProgressing(newLimitsTestCanary("ns1", "b", flaggerv1.CanaryPhaseWaiting, 1, 0))
+	assert.False(t, admitted)
+	assert.Equal(t, 1, position)
+}
+
+func TestScheduler_AdmitProgressingPerNamespace(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.progressingNsLimit = 1
+	indexer := mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer()
+	require.NoError(t, indexer.Delete(mocks.canary))
+	require.NoError(t, indexer.Add(newLimitsTestCanary("ns1", "a", flaggerv1.CanaryPhasePromoting, 0, 0)))
+	require.NoError(t, indexer.Add(newLimitsTestCanary("ns1", "b", flaggerv1.CanaryPhaseWaiting, 1, 0)))
+
+	admitted, position := mocks.ctrl.admitProgressing(newLimitsTestCanary("ns1", "c", flaggerv1.CanaryPhaseSucceeded, 0, 0))
+	assert.False(t, admitted)
+	assert.Equal(t, 2, position)
+
+	// a canary queued in a full namespace doesn't block the other namespaces
+	admitted, _ = mocks.ctrl.admitProgressing(newLimitsTestCanary("ns2", "d", flaggerv1.CanaryPhaseSucceeded, 0, 0))
+	assert.True(t, admitted)
+}
+
+func TestScheduler_AdmitProgressingPerShard(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.progressingLimit = 1
+	selector, err := labels.Parse("shard=blue")
+	require.NoError(t, err)
+	mocks.ctrl.shard = sharding.NewShard(mocks.kubeClient, "flagger-system", "flagger", "flagger-0", selector, false, 15*time.Second, mocks.logger)
+
+	blue := func(cd *flaggerv1.Canary) *flaggerv1.Canary {
+		cd.Labels = map[string]string{"shard": "blue"}
+		return cd
+	}
+	indexer := mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer()
+	require.NoError(t, indexer.Delete(mocks.canary))
+	require.NoError(t, indexer.Add(newLimitsTestCanary("ns1", "a", flaggerv1.CanaryPhaseProgressing, 0, 0)))
+
+	// the canaries of the other shards don't take a slot
+	admitted, _ := mocks.ctrl.admitProgressing(blue(newLimitsTestCanary("ns1", "b", flaggerv1.CanaryPhaseSucceeded, 0, 0)))
+	assert.True(t, admitted)
+
+	require.NoError(t, indexer.Add(blue(newLimitsTestCanary("ns1", "c", flaggerv1.CanaryPhaseProgressing, 0, 0))))
+	admitted, position := mocks.ctrl.admitProgressing(blue(newLimitsTestCanary("ns2", "d", flaggerv1.CanaryPhaseSucceeded, 0, 0)))
+	assert.False(t, admitted)
+	assert.Equal(t, 1, position)
+}
+
+func TestScheduler_DeploymentProgressingQueue(t *testing.T) {
+	mocks := newDeploymentFixture(nil)
+	mocks.ctrl.progressingLimit = 1
+	other := newLimitsTestCanary("default", "other", flaggerv1.CanaryPhaseProgressing, 0, 0)
+	indexer := mocks.ctrl.flaggerInformers.CanaryInformer.Informer().GetIndexer()
+	require.NoError(t, indexer.Add(other))
+
+	// initializing
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	mocks.makePrimaryReady(t)
+
+	// initialized
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	// update
+	dep2 := newDeploymentTestDeploymentV2()
+	_, err := mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), dep2, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// detect changes, the only slot is taken
+	mocks.ctrl.advanceCanary("podinfo", "default")
+	c, err := mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseWaiting, c.Status.Phase)
+	assert.Equal(t, 1, c.Status.QueuePosition)
+	require.Len(t, c.Status.Conditions, 1)
+	assert.Equal(t, string(flaggerv1.CanaryPhaseWaiting), c.Status.Conditions[0].Reason)
+	assert.Contains(t, c.Status.Conditions[0].Message, "queue position 1")
+
+	// the canary isn't scaled up while queued
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, dep.Spec.Replicas)
+
+	// the slot is freed
+	other.Status.Phase = flaggerv1.CanaryPhaseSucceeded
+	require.NoError(t, indexer.Update(other))
+	mocks.ctrl.advanceCanary("podinfo", "default")
+
+	c, err = mocks.flaggerClient.FlaggerV1beta1().Canaries("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, flaggerv1.CanaryPhaseProgressing, c.Status.Phase)
+	assert.Equal(t, 0, c.Status.QueuePosition)
+
+	dep, err = mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *dep.Spec.Replicas)
+}
@@ -23,7 +23,7 @@ import (
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/fluxcd/flagger/pkg/metrics/observers"
@@ -37,8 +37,18 @@ const (
 
 // to be called during canary initialization
 func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) error {
-	for _, metric := range canary.GetAnalysis().Metrics {
+	metrics := canary.GetAnalysis().Metrics
+	for _, step := range canary.GetAnalysis().Steps {
+		metrics = append(metrics[:len(metrics):len(metrics)], step.Metrics...)
+	}
+
+	for _, metric := range metrics {
 		if metric.Name == "request-success-rate" || metric.Name == "request-duration" {
+			// the ALB builtin metrics are queried from CloudWatch
+			if canary.Spec.Provider == flaggerv1.ALBProvider ||
+				(canary.Spec.Provider == "" && c.meshProvider == flaggerv1.ALBProvider) {
+				continue
+			}
 			observerFactory := c.observerFactory
 			if canary.Spec.MetricsServer != "" {
 				var err error
@@ -47,7 +57,7 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 					return fmt.Errorf("error building Prometheus client for %s %v", canary.Spec.MetricsServer, err)
 				}
 			}
-			if ok, err := observerFactory.Client.IsOnline(); !ok || err != nil {
+			if ok, err := observerFactory.Client.IsOnline(context.TODO()); !ok || err != nil {
 				return fmt.Errorf("prometheus not avaiable: %v", err)
 			}
 			continue
@@ -64,24 +74,19 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 				return fmt.Errorf("metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
 			}
 
-			var credentials map[string][]byte
-			if template.Spec.Provider.SecretRef != nil {
-				secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
-				if err != nil {
-					return fmt.Errorf("metric template %s.%s secret %s error: %v",
-						metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
-				}
-				credentials = secret.Data
+			secret, err := c.getMetricTemplateSecret(template)
+			if err != nil {
+				return fmt.Errorf("metric template %s.%s secret %s error: %v",
+					metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
 			}
 
-			factory := providers.NewFactory(c.logger)
-			provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials, c.kubeConfig)
+			provider, err := c.providerPool.Provider(metric.Interval, template, secret, c.kubeConfig)
 			if err != nil {
 				return fmt.Errorf("metric template %s.%s provider %s error: %v",
 					metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
 			}
 
-			if ok, err := provider.IsOnline(); !ok || err != nil {
+			if ok, err := provider.IsOnline(context.TODO()); !ok || err != nil {
 				return fmt.Errorf("%v in metric template %s.%s not avaiable: %v", template.Spec.Provider.Type,
 					template.Name, template.Namespace, err)
 			}
@@ -91,7 +96,16 @@ func (c *Controller) checkMetricProviderAvailability(canary *flaggerv1.Canary) e
 	return nil
 }
 
-func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
+func (c *Controller) runBuiltinMetricChecks(ctx context.Context, canary *flaggerv1.Canary) checkResult {
+	checks, ok := c.builtinMetricChecks(canary)
+	if !ok {
+		return checkFailed
+	}
+	return c.runChecks(ctx, canary, checks)
+}
+
+// builtinMetricChecks returns the checks of the builtin, pod and in-line PromQL metrics
+func (c *Controller) builtinMetricChecks(canary *flaggerv1.Canary) ([]analysisCheck, bool) {
 	// override the global provider if one is specified in the canary spec
 	var metricsProvider string
 	// set the metrics provider to Crossover Prometheus when Crossover is the mesh provider
@@ -118,10 +132,10 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 	var knativeService *serving.Service
 	if canary.Spec.Provider == flaggerv1.KnativeProvider || c.meshProvider == flaggerv1.KnativeProvider {
 		var err error
-		knativeService, err = c.knativeClient.ServingV1().Services(canary.Namespace).Get(context.TODO(), canary.Spec.TargetRef.Name, metav1.GetOptions{})
+		knativeService, err = c.canaryFactory.Listers().GetKnativeService(c.knativeClient, canary.Namespace, canary.Spec.TargetRef.Name)
 		if err != nil {
 			c.recordEventErrorf(canary, "Error fetching Knative service %s/%s %v", canary.Namespace, canary.Spec.TargetRef.Name, err)
-			return false
+			return nil, false
 		}
 	}
 
@@ -131,145 +145,217 @@ func (c *Controller) runBuiltinMetricChecks(canary *flaggerv1.Canary) bool {
 	// override the global metrics server if one is specified in the canary spec
 	if canary.Spec.MetricsServer != "" {
 		var err error
-		observerFactory, err = observers.NewFactory(canary.Spec.MetricsServer)
+		observerFactory, err = c.observerFactory.ForMetricsServer(canary.Spec.MetricsServer)
 		if err != nil {
 			c.recordEventErrorf(canary, "Error building Prometheus client for %s %v", canary.Spec.MetricsServer, err)
-			return false
+			return nil, false
 		}
 	}
 	observer := observerFactory.Observer(metricsProvider)
+	if strings.HasPrefix(metricsProvider, flaggerv1.GatewayAPIProvider) {
+		observer = observerFactory.GatewayAPIObserver(canary.Spec.GatewayImplementation)
+	}
+	podObserver := observers.NewPodObserver(c.kubeClient)
 
 	// run metrics checks
-	for _, metric := range canary.GetAnalysis().Metrics {
+	var checks []analysisCheck
+	for _, metric := range canary.GetAnalysisMetrics() {
 		if metric.Interval == "" {
 			metric.Interval = canary.GetMetricInterval()
 		}
 
-		if metric.Name == "request-success-rate" {
-			model := toMetricModel(canary, metric.Interval, metric.TemplateVariables)
-			if knativeService != nil {
-				model.Route = knativeService.Status.LatestCreatedRevisionName
-			}
-			val, err := observer.GetRequestSuccessRate(model)
-			if err != nil {
-				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary,
-						"Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic: %v",
-						metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace, err)
-				} else {
-					c.recordEventErrorf(canary, "Prometheus query failed: %v", err)
+		if !observers.IsPodMetric(metric.Name) && metric.Name != "request-success-rate" &&
+			metric.Name != "request-duration" && metric.Query == "" {
+			continue
+		}
+
+		checks = append(checks, analysisCheck{name: metric.Name, run: func(ctx context.Context, rec *checkRecorder) checkResult {
+			// Kubernetes pod health checks
+			if observers.IsPodMetric(metric.Name) {
+				selector, err := c.getCanaryPodSelector(canary, knativeService)
+				if err != nil {
+					rec.errorf("Pod metric %s failed: %v", metric.Name, err)
+					return checkFailed
 				}
-				return false
-			}
-			c.recorder.SetAnalysis(canary, metric.Name, val)
-			if metric.ThresholdRange != nil {
-				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
-						canary.Name, canary.Namespace, val, *tr.Min)
-					return false
+				interval, err := time.ParseDuration(metric.Interval)
+				if err != nil {
+					rec.errorf("Pod metric %s interval %s is invalid: %v", metric.Name, metric.Interval, err)
+					return checkFailed
+				}
+				val, err := podObserver.GetPodMetric(metric.Name, canary.Namespace, selector, interval)
+				if err != nil {
+					rec.errorf("Pod metric %s failed: %v", metric.Name, err)
+					return checkFailed
 				}
-				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% > %v%%",
-						canary.Name, canary.Namespace, val, *tr.Max)
-					return false
+				c.recorder.SetAnalysis(canary, metric.Name, val)
+				if metric.ThresholdRange != nil {
+					tr := *metric.ThresholdRange
+					if tr.Min != nil && val < *tr.Min {
+						rec.warningf("Halt %s.%s advancement %s %.0f < %v",
+							canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
+						return checkFailed
+					}
+					if tr.Max != nil && val > *tr.Max {
+						rec.warningf("Halt %s.%s advancement %s %.0f > %v",
+							canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
+						return checkFailed
+					}
+				} else if val > metric.Threshold {
+					rec.warningf("Halt %s.%s advancement %s %.0f > %v",
+						canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
+					return checkFailed
 				}
-			} else if metric.Threshold > val {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement success rate %.2f%% < %v%%",
-					canary.Name, canary.Namespace, val, metric.Threshold)
-				return false
 			}
-		}
 
-		if metric.Name == "request-duration" {
-			model := toMetricModel(canary, metric.Interval, metric.TemplateVariables)
-			if knativeService != nil {
-				model.Route = knativeService.Status.LatestCreatedRevisionName
-			}
-			val, err := observer.GetRequestDuration(model)
-			if err != nil {
-				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary, "Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic",
-						metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace)
-				} else {
-					c.recordEventErrorf(canary, "Prometheus query failed: %v", err)
+			if metric.Name == "request-success-rate" {
+				model := toMetricModel(canary, metric.Interval, metric.TemplateVariables)
+				if metric.Params != nil {
+					model.Quantile = metric.Params.Quantile
+					model.ExcludedStatus = metric.Params.ExcludedStatus
 				}
-				return false
-			}
-			c.recorder.SetAnalysis(canary, metric.Name, val.Seconds())
-			if metric.ThresholdRange != nil {
-				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v < %v",
-						canary.Name, canary.Namespace, val, time.Duration(*tr.Min)*time.Millisecond)
-					return false
+				if knativeService != nil {
+					model.Route = knativeService.Status.LatestCreatedRevisionName
+				}
+				val, err := observer.GetRequestSuccessRate(ctx, model)
+				if err != nil {
+					if providers.IsTransient(err) {
+						return rec.transientError(metric.Name, err)
+					}
+					if errors.Is(err, providers.ErrNoValuesFound) {
+						rec.warningf(
+							"Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic: %v",
+							metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace, err)
+					} else {
+						rec.errorf("Prometheus query failed: %v", err)
+					}
+					return checkFailed
 				}
-				if tr.Max != nil && val > time.Duration(*tr.Max)*time.Millisecond {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
-						canary.Name, canary.Namespace, val, time.Duration(*tr.Max)*time.Millisecond)
-					return false
+				c.recorder.SetAnalysis(canary, metric.Name, val)
+				if metric.ThresholdRange != nil {
+					tr := *metric.ThresholdRange
+					if tr.Min != nil && val < *tr.Min {
+						rec.warningf("Halt %s.%s advancement success rate %.2f%% < %v%%",
+							canary.Name, canary.Namespace, val, *tr.Min)
+						return checkFailed
+					}
+					if tr.Max != nil && val > *tr.Max {
+						rec.warningf("Halt %s.%s advancement success rate %.2f%% > %v%%",
+							canary.Name, canary.Namespace, val, *tr.Max)
+						return checkFailed
+					}
+				} else if metric.Threshold > val {
+					rec.warningf("Halt %s.%s advancement success rate %.2f%% < %v%%",
+						canary.Name, canary.Namespace, val, metric.Threshold)
+					return checkFailed
 				}
-			} else if val > time.Duration(metric.Threshold)*time.Millisecond {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement request duration %v > %v",
-					canary.Name, canary.Namespace, val, time.Duration(metric.Threshold)*time.Millisecond)
-				return false
 			}
-		}
 
-		// in-line PromQL
-		if metric.Query != "" {
-			model := toMetricModel(canary, metric.Interval, metric.TemplateVariables)
-			if knativeService != nil {
-				model.Route = knativeService.Status.LatestCreatedRevisionName
-			}
-			query, err := observers.RenderQuery(metric.Query, model)
-			val, err := observerFactory.Client.RunQuery(query)
-			if err != nil {
-				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary, "Halt advancement no values found for metric: %s",
-						metric.Name)
-				} else {
-					c.recordEventErrorf(canary, "Prometheus query failed for %s: %v", metric.Name, err)
+			if metric.Name == "request-duration" {
+				model := toMetricModel(canary, metric.Interval, metric.TemplateVariables)
+				if metric.Params != nil {
+					model.Quantile = metric.Params.Quantile
+					model.ExcludedStatus = metric.Params.ExcludedStatus
+				}
+				if knativeService != nil {
+					model.Route = knativeService.Status.LatestCreatedRevisionName
+				}
+				val, err := observer.GetRequestDuration(ctx, model)
+				if err != nil {
+					if providers.IsTransient(err) {
+						return rec.transientError(metric.Name, err)
+					}
+					if errors.Is(err, providers.ErrNoValuesFound) {
+						rec.warningf("Halt advancement no values found for %s metric %s probably %s.%s is not receiving traffic",
+							metricsProvider, metric.Name, canary.Spec.TargetRef.Name, canary.Namespace)
+					} else {
+						rec.errorf("Prometheus query failed: %v", err)
+					}
+					return checkFailed
+				}
+				c.recorder.SetAnalysis(canary, metric.Name, val.Seconds())
+				if metric.ThresholdRange != nil {
+					tr := *metric.ThresholdRange
+					if tr.Min != nil && val < time.Duration(*tr.Min)*time.Millisecond {
+						rec.warningf("Halt %s.%s advancement request duration %v < %v",
+							canary.Name, canary.Namespace, val, time.Duration(*tr.Min)*time.Millisecond)
+						return checkFailed
+					}
+					if tr.Max != nil && val > time.Duration(*tr.Max)*time.Millisecond {
+						rec.warningf("Halt %s.%s advancement request duration %v > %v",
+							canary.Name, canary.Namespace, val, time.Duration(*tr.Max)*time.Millisecond)
+						return checkFailed
+					}
+				} else if val > time.Duration(metric.Threshold)*time.Millisecond {
+					rec.warningf("Halt %s.%s advancement request duration %v > %v",
+						canary.Name, canary.Namespace, val, time.Duration(metric.Threshold)*time.Millisecond)
+					return checkFailed
 				}
-				return false
 			}
-			c.recorder.SetAnalysis(canary, metric.Name, val)
-			if metric.ThresholdRange != nil {
-				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
-					return false
+
+			// in-line PromQL
+			if metric.Query != "" {
+				model := toMetricModel(canary, metric.Interval, metric.TemplateVariables)
+				if knativeService != nil {
+					model.Route = knativeService.Status.LatestCreatedRevisionName
+				}
+				query, err := observers.RenderQuery(metric.Query, model)
+				series, err := queryMetric(ctx, observerFactory.Client, metric, query)
+				if err != nil {
+					if providers.IsTransient(err) {
+						return rec.transientError(metric.Name, err)
+					}
+					if errors.Is(err, providers.ErrNoValuesFound) {
+						rec.warningf("Halt advancement no values found for metric: %s",
+							metric.Name)
+					} else {
+						rec.errorf("Prometheus query failed for %s: %v", metric.Name, err)
+					}
+					return checkFailed
 				}
-				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
-					return false
+				if result := c.checkMetricThreshold(canary, rec, metric, series); result != checkPassed {
+					return result
 				}
-			} else if val > metric.Threshold {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
-				return false
 			}
-		}
+
+			return checkPassed
+		}})
 	}
 
-	return true
+	return checks, true
 }
 
-func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
+func (c *Controller) runMetricChecks(ctx context.Context, canary *flaggerv1.Canary) checkResult {
+	checks, ok := c.metricChecks(canary)
+	if !ok {
+		return checkFailed
+	}
+	return c.runChecks(ctx, canary, checks)
+}
+
+// metricChecks returns the checks of the metrics referencing a template
+func (c *Controller) metricChecks(canary *flaggerv1.Canary) ([]analysisCheck, bool) {
 	var knativeService *serving.Service
 	if canary.Spec.Provider == flaggerv1.KnativeProvider || c.meshProvider == flaggerv1.KnativeProvider {
 		var err error
-		knativeService, err = c.knativeClient.ServingV1().Services(canary.Namespace).Get(context.TODO(), canary.Spec.TargetRef.Name, metav1.GetOptions{})
+		knativeService, err = c.canaryFactory.Listers().GetKnativeService(c.knativeClient, canary.Namespace, canary.Spec.TargetRef.Name)
 		if err != nil {
 			c.recordEventErrorf(canary, "Error fetching Knative service %s/%s %v", canary.Namespace, canary.Spec.TargetRef.Name, err)
-			return false
+			return nil, false
 		}
 	}
 
-	for _, metric := range canary.GetAnalysis().Metrics {
-		if metric.TemplateRef != nil {
+	var checks []analysisCheck
+	for _, metric := range canary.GetAnalysisMetrics() {
+		if metric.TemplateRef == nil {
+			if metric.Name != "request-success-rate" && metric.Name != "request-duration" &&
+				!observers.IsPodMetric(metric.Name) && metric.Query == "" {
+				c.recordEventErrorf(canary, "Metric query failed for no usable metrics template and query were configured")
+				return nil, false
+			}
+			continue
+		}
+
+		checks = append(checks, analysisCheck{name: metric.Name, run: func(ctx context.Context, rec *checkRecorder) checkResult {
 			namespace := canary.Namespace
 			if metric.TemplateRef.Namespace != canary.Namespace && metric.TemplateRef.Namespace != "" {
 				namespace = metric.TemplateRef.Namespace
@@ -277,27 +363,22 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 
 			template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(metric.TemplateRef.Name)
 			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
-				return false
+				rec.errorf("Metric template %s.%s error: %v", metric.TemplateRef.Name, namespace, err)
+				return checkFailed
 			}
 
-			var credentials map[string][]byte
-			if template.Spec.Provider.SecretRef != nil {
-				secret, err := c.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), template.Spec.Provider.SecretRef.Name, metav1.GetOptions{})
-				if err != nil {
-					c.recordEventErrorf(canary, "Metric template %s.%s secret %s error: %v",
-						metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
-					return false
-				}
-				credentials = secret.Data
+			secret, err := c.getMetricTemplateSecret(template)
+			if err != nil {
+				rec.errorf("Metric template %s.%s secret %s error: %v",
+					metric.TemplateRef.Name, namespace, template.Spec.Provider.SecretRef.Name, err)
+				return checkFailed
 			}
 
-			factory := providers.NewFactory(c.logger)
-			provider, err := factory.Provider(metric.Interval, template.Spec.Provider, credentials, c.kubeConfig)
+			provider, err := c.providerPool.Provider(metric.Interval, template, secret, c.kubeConfig)
 			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s provider %s error: %v",
+				rec.errorf("Metric template %s.%s provider %s error: %v",
 					metric.TemplateRef.Name, namespace, template.Spec.Provider.Type, err)
-				return false
+				return checkFailed
 			}
 
 			model := toMetricModel(canary, metric.Interval, metric.TemplateVariables)
@@ -308,48 +389,207 @@ func (c *Controller) runMetricChecks(canary *flaggerv1.Canary) bool {
 			c.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, namespace)).
 				Debugf("Metric template %s.%s query: %s", metric.TemplateRef.Name, namespace, query)
 			if err != nil {
-				c.recordEventErrorf(canary, "Metric template %s.%s query render error: %v",
+				rec.errorf("Metric template %s.%s query render error: %v",
 					metric.TemplateRef.Name, namespace, err)
-				return false
+				return checkFailed
+			}
+
+			if alerts, ok := provider.(providers.AlertsInterface); ok {
+				return c.runAlertCheck(ctx, canary, rec, metric, alerts, query)
 			}
 
-			val, err := provider.RunQuery(query)
+			series, err := queryMetric(ctx, provider, metric, query)
 			if err != nil {
+				if providers.IsTransient(err) {
+					return rec.transientError(metric.Name, err)
+				}
 				if errors.Is(err, providers.ErrNoValuesFound) {
-					c.recordEventWarningf(canary, "Halt advancement no values found for custom metric: %s: %v",
+					rec.warningf("Halt advancement no values found for custom metric: %s: %v",
 						metric.Name, err)
 				} else {
-					c.recordEventErrorf(canary, "Metric query failed for %s: %v", metric.Name, err)
+					rec.errorf("Metric query failed for %s: %v", metric.Name, err)
 				}
-				return false
+				return checkFailed
 			}
 
-			c.recorder.SetAnalysis(canary, metric.Name, val)
+			return c.checkMetricThreshold(canary, rec, metric, series)
+		}})
+	}
 
-			if metric.ThresholdRange != nil {
-				tr := *metric.ThresholdRange
-				if tr.Min != nil && val < *tr.Min {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f < %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Min)
-					return false
-				}
-				if tr.Max != nil && val > *tr.Max {
-					c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-						canary.Name, canary.Namespace, metric.Name, val, *tr.Max)
-					return false
-				}
-			} else if val > metric.Threshold {
-				c.recordEventWarningf(canary, "Halt %s.%s advancement %s %.2f > %v",
-					canary.Name, canary.Namespace, metric.Name, val, metric.Threshold)
-				return false
+	return checks, true
+}
+
+// getMetricTemplateSecret returns the secret referenced by the template provider from the informer cache
+func (c *Controller) getMetricTemplateSecret(template *flaggerv1.MetricTemplate) (*corev1.Secret, error) {
+	if template.Spec.Provider.SecretRef == nil {
+		return nil, nil
+	}
+	return c.flaggerInformers.SecretInformer.Lister().Secrets(template.Namespace).Get(template.Spec.Provider.SecretRef.Name)
+}
+
+// runAlertCheck halts the advancement when the number of firing alerts matching
+// the selector exceeds the metric threshold, zero by default
+func (c *Controller) runAlertCheck(ctx context.Context, canary *flaggerv1.Canary, rec *checkRecorder,
+	metric flaggerv1.CanaryMetric, provider providers.AlertsInterface, selector string) checkResult {
+	alerts, err := provider.FiringAlerts(ctx, selector)
+	if err != nil {
+		if providers.IsTransient(err) {
+			return rec.transientError(metric.Name, err)
+		}
+		rec.errorf("Alert query failed for %s: %v", metric.Name, err)
+		return checkFailed
+	}
+
+	val := float64(len(alerts))
+	c.recorder.SetAnalysis(canary, metric.Name, val)
+
+	max := metric.Threshold
+	if metric.ThresholdRange != nil && metric.ThresholdRange.Max != nil {
+		max = *metric.ThresholdRange.Max
+	}
+	if val > max {
+		rec.warningf("Halt %s.%s advancement %s firing alerts: %s",
+			canary.Name, canary.Namespace, metric.Name, strings.Join(providers.AlertNames(alerts), ", "))
+		return checkFailed
+	}
+	return checkPassed
+}
+
+// metricSeries is a metric value and the label set of the series it was computed from,
+// the label set is empty when the metric has no evaluation mode
+type metricSeries struct {
+	labels string
+	value  float64
+}
+
+// queryMetric runs the metric query and evaluates the returned series according to the metric evaluation mode
+func queryMetric(ctx context.Context, provider providers.Interface, metric flaggerv1.CanaryMetric, query string) ([]metricSeries, error) {
+	if metric.Evaluation == nil {
+		val, err := provider.RunQuery(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return []metricSeries{{value: val}}, nil
+	}
+
+	vector, ok := provider.(providers.VectorInterface)
+	if !ok {
+		return nil, fmt.Errorf("provider doesn't support the %s evaluation of vector results", metric.Evaluation.Mode)
+	}
+	samples, err := vector.RunVectorQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateSamples(samples, *metric.Evaluation)
+}
+
+// evaluateSamples returns every series in all mode, otherwise the single series or aggregate to be checked
+func evaluateSamples(samples []providers.Sample, evaluation flaggerv1.CanaryMetricEvaluation) ([]metricSeries, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("%w", providers.ErrNoValuesFound)
+	}
+
+	switch evaluation.Mode {
+	case flaggerv1.MetricEvaluationAll:
+		series := make([]metricSeries, 0, len(samples))
+		for _, sample := range samples {
+			series = append(series, metricSeries{labels: sample.LabelSet(), value: sample.Value})
+		}
+		return series, nil
+	case flaggerv1.MetricEvaluationMax, flaggerv1.MetricEvaluationMin:
+		selected := samples[0]
+		for _, sample := range samples[1:] {
+			if evaluation.Mode == flaggerv1.MetricEvaluationMax && sample.Value > selected.Value ||
+				evaluation.Mode == flaggerv1.MetricEvaluationMin && sample.Value < selected.Value {
+				selected = sample
+			}
+		}
+		return []metricSeries{{labels: selected.LabelSet(), value: selected.Value}}, nil
+	case flaggerv1.MetricEvaluationAvg:
+		var sum float64
+		for _, sample := range samples {
+			sum += sample.Value
+		}
+		return []metricSeries{{
+			labels: fmt.Sprintf("avg of %d series", len(samples)),
+			value:  sum / float64(len(samples)),
+		}}, nil
+	case flaggerv1.MetricEvaluationSeries:
+		var matched []providers.Sample
+		for _, sample := range samples {
+			if sample.Matches(evaluation.Series) {
+				matched = append(matched, sample)
 			}
-		} else if metric.Name != "request-success-rate" && metric.Name != "request-duration" && metric.Query == "" {
-			c.recordEventErrorf(canary, "Metric query failed for no usable metrics template and query were configured")
-			return false
 		}
+		selector := providers.Sample{Labels: evaluation.Series}.LabelSet()
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("no series matching %s: %w", selector, providers.ErrNoValuesFound)
+		}
+		if len(matched) > 1 {
+			return nil, fmt.Errorf("%d series matching %s: %w", len(matched), selector, providers.ErrMultipleValuesReturned)
+		}
+		return []metricSeries{{labels: matched[0].LabelSet(), value: matched[0].Value}}, nil
+	default:
+		return nil, fmt.Errorf("evaluation mode %s is not supported", evaluation.Mode)
 	}
+}
 
-	return true
+// checkMetricThreshold records the metric value and reports each series outside the threshold range,
+// the value of the first failing series is recorded when there are many
+func (c *Controller) checkMetricThreshold(canary *flaggerv1.Canary, rec *checkRecorder,
+	metric flaggerv1.CanaryMetric, series []metricSeries) checkResult {
+	result := checkPassed
+	recorded := series[0].value
+	for _, s := range series {
+		val := s.value
+		suffix := ""
+		if s.labels != "" {
+			suffix = " for " + s.labels
+		}
+
+		failed := true
+		if metric.ThresholdRange != nil {
+			tr := *metric.ThresholdRange
+			if tr.Min != nil && val < *tr.Min {
+				rec.warningf("Halt %s.%s advancement %s %.2f < %v%s",
+					canary.Name, canary.Namespace, metric.Name, val, *tr.Min, suffix)
+			} else if tr.Max != nil && val > *tr.Max {
+				rec.warningf("Halt %s.%s advancement %s %.2f > %v%s",
+					canary.Name, canary.Namespace, metric.Name, val, *tr.Max, suffix)
+			} else {
+				failed = false
+			}
+		} else if val > metric.Threshold {
+			rec.warningf("Halt %s.%s advancement %s %.2f > %v%s",
+				canary.Name, canary.Namespace, metric.Name, val, metric.Threshold, suffix)
+		} else {
+			failed = false
+		}
+
+		if failed && result == checkPassed {
+			recorded = val
+			result = checkFailed
+		}
+	}
+
+	c.recorder.SetAnalysis(canary, metric.Name, recorded)
+	return result
+}
+
+// getCanaryPodSelector returns the label selector matching the canary pods
+func (c *Controller) getCanaryPodSelector(canary *flaggerv1.Canary, knativeService *serving.Service) (string, error) {
+	if knativeService != nil {
+		return fmt.Sprintf("serving.knative.dev/revision=%s", knativeService.Status.LatestCreatedRevisionName), nil
+	}
+
+	label, labelValue, _, err := c.canaryFactory.Controller(canary.Spec.TargetRef).GetMetadata(canary)
+	if err != nil {
+		return "", err
+	}
+	if label == "" {
+		return "", fmt.Errorf("pod metrics are not supported for %s targets", canary.Spec.TargetRef.Kind)
+	}
+	return fmt.Sprintf("%s=%s", label, labelValue), nil
 }
 
 func toMetricModel(r *flaggerv1.Canary, interval string, variables map[string]string) flaggerv1.MetricTemplateModel {
@@ -18,12 +18,15 @@ package controller
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 
@@ -49,6 +52,11 @@ func TestController_checkMetricProviderAvailability(t *testing.T) {
 		// ok
 		canary.Spec.MetricsServer = testMetricsServerURL
 		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
+
+		// ok (ALB metrics are queried from CloudWatch)
+		canary.Spec.MetricsServer = ""
+		canary.Spec.Provider = flaggerv1.ALBProvider
+		require.NoError(t, ctrl.checkMetricProviderAvailability(canary))
 	})
 
 	t.Run("templateRef", func(t *testing.T) {
@@ -108,7 +116,7 @@ func TestController_runMetricChecks(t *testing.T) {
 			ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
 			Spec:       flaggerv1.CanarySpec{Analysis: analysis},
 		}
-		assert.Equal(t, true, ctrl.runMetricChecks(canary))
+		assert.Equal(t, checkPassed, ctrl.runMetricChecks(context.TODO(), canary))
 	})
 
 	t.Run("undefined metric", func(t *testing.T) {
@@ -124,7 +132,7 @@ func TestController_runMetricChecks(t *testing.T) {
 			ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
 			Spec:       flaggerv1.CanarySpec{Analysis: analysis},
 		}
-		assert.Equal(t, false, ctrl.runMetricChecks(canary))
+		assert.Equal(t, checkFailed, ctrl.runMetricChecks(context.TODO(), canary))
 	})
 
 	t.Run("builtinMetric", func(t *testing.T) {
@@ -140,7 +148,7 @@ func TestController_runMetricChecks(t *testing.T) {
 			ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
 			Spec:       flaggerv1.CanarySpec{Analysis: analysis},
 		}
-		assert.Equal(t, true, ctrl.runMetricChecks(canary))
+		assert.Equal(t, checkPassed, ctrl.runMetricChecks(context.TODO(), canary))
 	})
 
 	t.Run("no metric Template is defined, but a query is specified", func(t *testing.T) {
@@ -157,7 +165,7 @@ func TestController_runMetricChecks(t *testing.T) {
 			ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
 			Spec:       flaggerv1.CanarySpec{Analysis: analysis},
 		}
-		assert.Equal(t, true, ctrl.runMetricChecks(canary))
+		assert.Equal(t, checkPassed, ctrl.runMetricChecks(context.TODO(), canary))
 	})
 
 	t.Run("both have metric Template and query", func(t *testing.T) {
@@ -182,7 +190,168 @@ func TestController_runMetricChecks(t *testing.T) {
 			ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
 			Spec:       flaggerv1.CanarySpec{Analysis: analysis},
 		}
-		assert.Equal(t, true, ctrl.runMetricChecks(canary))
+		assert.Equal(t, checkPassed, ctrl.runMetricChecks(context.TODO(), canary))
+	})
+
+	t.Run("alertmanager", func(t *testing.T) {
+		firing := false
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, []string{`namespace="default"`, `app="podinfo"`}, r.URL.Query()["filter"])
+			if firing {
+				w.Write([]byte(`[{"labels":{"alertname":"PodinfoHighErrorRate"},"status":{"state":"active"}}]`))
+				return
+			}
+			w.Write([]byte(`[]`))
+		}))
+		defer ts.Close()
+
+		mocks := newDeploymentFixture(nil)
+		template := &flaggerv1.MetricTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alerts"},
+			Spec: flaggerv1.MetricTemplateSpec{
+				Provider: flaggerv1.MetricTemplateProvider{Type: "alertmanager", Address: ts.URL},
+				Query:    `{namespace="{{ namespace }}", app="{{ target }}"}`,
+			},
+		}
+		require.NoError(t, mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+		canary := mocks.canary.DeepCopy()
+		canary.Spec.Analysis = &flaggerv1.CanaryAnalysis{Metrics: []flaggerv1.CanaryMetric{{
+			Name:        "alerts",
+			TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "alerts"},
+		}}}
+		assert.Equal(t, checkPassed, mocks.ctrl.runMetricChecks(context.TODO(), canary))
+
+		firing = true
+		assert.Equal(t, checkFailed, mocks.ctrl.runMetricChecks(context.TODO(), canary))
+	})
+}
+
+func TestController_runMetricChecksTransientError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	mocks := newDeploymentFixture(nil)
+	template := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unavailable"},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+			Query:    "vector(1)",
+		},
+	}
+	require.NoError(t, mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.Analysis = &flaggerv1.CanaryAnalysis{Metrics: []flaggerv1.CanaryMetric{{
+		Name:        "unavailable",
+		TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "unavailable"},
+		Threshold:   1,
+	}}}
+	assert.Equal(t, checkTransient, mocks.ctrl.runMetricChecks(context.TODO(), canary))
+}
+
+func TestController_runMetricChecksEvaluation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json := `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"pod":"podinfo-1"},"value":[1545905245.458,"1"]},
+			{"metric":{"pod":"podinfo-2"},"value":[1545905245.458,"4"]},
+			{"metric":{"pod":"podinfo-3"},"value":[1545905245.458,"7"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		evaluation *flaggerv1.CanaryMetricEvaluation
+		result     checkResult
+		events     []string
+	}{
+		{name: "all", evaluation: &flaggerv1.CanaryMetricEvaluation{Mode: flaggerv1.MetricEvaluationAll}, result: checkFailed,
+			events: []string{`errors 1.00 < 2 for {pod="podinfo-1"}`, `errors 7.00 > 5 for {pod="podinfo-3"}`}},
+		{name: "max", evaluation: &flaggerv1.CanaryMetricEvaluation{Mode: flaggerv1.MetricEvaluationMax}, result: checkFailed,
+			events: []string{`errors 7.00 > 5 for {pod="podinfo-3"}`}},
+		{name: "min", evaluation: &flaggerv1.CanaryMetricEvaluation{Mode: flaggerv1.MetricEvaluationMin}, result: checkFailed,
+			events: []string{`errors 1.00 < 2 for {pod="podinfo-1"}`}},
+		{name: "avg", evaluation: &flaggerv1.CanaryMetricEvaluation{Mode: flaggerv1.MetricEvaluationAvg}, result: checkPassed},
+		{name: "series", evaluation: &flaggerv1.CanaryMetricEvaluation{
+			Mode:   flaggerv1.MetricEvaluationSeries,
+			Series: map[string]string{"pod": "podinfo-2"},
+		}, result: checkPassed},
+		{name: "series not found", evaluation: &flaggerv1.CanaryMetricEvaluation{
+			Mode:   flaggerv1.MetricEvaluationSeries,
+			Series: map[string]string{"pod": "podinfo-4"},
+		}, result: checkFailed, events: []string{`no series matching {pod="podinfo-4"}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocks := newDeploymentFixture(nil)
+			recorder := record.NewFakeRecorder(10)
+			mocks.ctrl.eventRecorder = recorder
+
+			template := &flaggerv1.MetricTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "errors"},
+				Spec: flaggerv1.MetricTemplateSpec{
+					Provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL},
+					Query:    "sum by (pod) (errors_total)",
+				},
+			}
+			require.NoError(t, mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+			canary := mocks.canary.DeepCopy()
+			canary.Spec.Analysis = &flaggerv1.CanaryAnalysis{Metrics: []flaggerv1.CanaryMetric{{
+				Name:        "errors",
+				TemplateRef: &flaggerv1.CrossNamespaceObjectReference{Name: "errors"},
+				ThresholdRange: &flaggerv1.CanaryThresholdRange{
+					Min: toFloatPtr(2),
+					Max: toFloatPtr(5),
+				},
+				Evaluation: tt.evaluation,
+			}}}
+			assert.Equal(t, tt.result, mocks.ctrl.runMetricChecks(context.TODO(), canary))
+
+			events := drainEvents(recorder)
+			if assert.Len(t, events, len(tt.events)) {
+				for i, event := range tt.events {
+					assert.Contains(t, events[i], event)
+				}
+			}
+		})
+	}
+}
+
+func TestController_runBuiltinMetricChecks(t *testing.T) {
+	t.Run("podMetrics", func(t *testing.T) {
+		mocks := newDeploymentFixture(nil)
+		analysis := &flaggerv1.CanaryAnalysis{Metrics: []flaggerv1.CanaryMetric{{
+			Name: observers.PodRestartsMetric,
+			ThresholdRange: &flaggerv1.CanaryThresholdRange{
+				Max: toFloatPtr(1),
+			},
+		}}}
+		canary := mocks.canary.DeepCopy()
+		canary.Spec.Analysis = analysis
+		canary.Spec.MetricsServer = testMetricsServerURL
+		assert.Equal(t, checkPassed, mocks.ctrl.runBuiltinMetricChecks(context.TODO(), canary))
+		assert.Equal(t, checkPassed, mocks.ctrl.runMetricChecks(context.TODO(), canary))
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "podinfo-abc",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "podinfo"},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "podinfo", RestartCount: 2},
+				},
+			},
+		}
+		_, err := mocks.kubeClient.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, checkFailed, mocks.ctrl.runBuiltinMetricChecks(context.TODO(), canary))
 	})
 }
 
@@ -344,8 +513,8 @@ func TestController_AnalysisMetricsRecording(t *testing.T) {
 			},
 		}
 
-		result := mocks.ctrl.runMetricChecks(canary)
-		assert.True(t, result)
+		result := mocks.ctrl.runMetricChecks(context.TODO(), canary)
+		assert.Equal(t, checkPassed, result)
 
 		successRateMetric := mocks.ctrl.recorder.GetAnalysisMetric().WithLabelValues("podinfo", "default", "request-success-rate")
 		assert.NotNil(t, successRateMetric)
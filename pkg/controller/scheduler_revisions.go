@@ -0,0 +1,144 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/canary"
+)
+
+// revisionPinner returns the revision pinner of the canary target, nil if the target kind can't be pinned
+func (c *Controller) revisionPinner(cd *flaggerv1.Canary) canary.RevisionPinner {
+	pinner, _ := c.canaryFactory.Controller(cd.Spec.TargetRef).(canary.RevisionPinner)
+	return pinner
+}
+
+// deferNewRevision decides if a revision detected during the analysis waits for the analysis
+// to complete instead of restarting it. A revision can only be deferred when the canary pods
+// are pinned to the analysed revision, config changes are rolled out in place and always restart.
+func (c *Controller) deferNewRevision(cd *flaggerv1.Canary, canaryController canary.Controller) bool {
+	policy := cd.GetAnalysisRevisionPolicy()
+	if policy == flaggerv1.RevisionPolicyRestart {
+		return false
+	}
+
+	pinner := c.revisionPinner(cd)
+	if pinner == nil {
+		c.recordEventWarningf(cd, "Revision policy %s is not supported for %s targets, restarting analysis",
+			policy, cd.Spec.TargetRef.Kind)
+		return false
+	}
+
+	if diff, _ := canaryController.HaveDependenciesChanged(cd); diff {
+		return false
+	}
+
+	pinned, err := pinner.IsRevisionPinned(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+	if !pinned {
+		return false
+	}
+
+	revision, err := pinner.TargetRevision(cd)
+	if err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+		return false
+	}
+
+	if cd.Status.PendingRevision != revision {
+		if err := canaryController.SetStatusPendingRevision(cd, revision); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+			return true
+		}
+		if policy == flaggerv1.RevisionPolicyFinish {
+			c.recordEventInfof(cd, "New revision %s detected! Analysis will start after the current analysis of %s.%s completes",
+				revision, cd.Spec.TargetRef.Name, cd.Namespace)
+		} else {
+			c.recordEventWarningf(cd, "New revision %s detected! Revision rejected until the current analysis of %s.%s completes",
+				revision, cd.Spec.TargetRef.Name, cd.Namespace)
+		}
+	}
+	return true
+}
+
+// pinRevision holds the canary pods on the analysed revision when new revisions are deferred
+func (c *Controller) pinRevision(cd *flaggerv1.Canary) {
+	if cd.GetAnalysisRevisionPolicy() == flaggerv1.RevisionPolicyRestart {
+		return
+	}
+
+	if pinner := c.revisionPinner(cd); pinner != nil {
+		if err := pinner.PinRevision(cd); err != nil {
+			c.recordEventWarningf(cd, "%v", err)
+		}
+	}
+}
+
+// unpinRevision resumes the rollout of the latest revision on the canary target
+func (c *Controller) unpinRevision(cd *flaggerv1.Canary) error {
+	if pinner := c.revisionPinner(cd); pinner != nil {
+		return pinner.UnpinRevision(cd)
+	}
+	return nil
+}
+
+// releaseStalePin resumes a target deployment left paused outside of an analysis,
+// e.g. when Flagger was stopped before the canary was scaled down
+func (c *Controller) releaseStalePin(cd *flaggerv1.Canary) {
+	switch cd.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion,
+		flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		return
+	}
+
+	pinner := c.revisionPinner(cd)
+	if pinner == nil {
+		return
+	}
+
+	pinned, err := pinner.IsRevisionPinned(cd)
+	if err != nil || !pinned {
+		return
+	}
+
+	c.recordEventWarningf(cd, "Deployment %s.%s is paused by Flagger in phase %s, resuming it",
+		cd.Spec.TargetRef.Name, cd.Namespace, cd.Status.Phase)
+	if err := pinner.UnpinRevision(cd); err != nil {
+		c.recordEventWarningf(cd, "%v", err)
+	}
+}
+
+// isRevisionRejected returns true if the canary target runs the revision rejected during the last analysis
+func (c *Controller) isRevisionRejected(cd *flaggerv1.Canary) bool {
+	if cd.GetAnalysisRevisionPolicy() != flaggerv1.RevisionPolicyReject || cd.Status.PendingRevision == "" {
+		return false
+	}
+
+	pinner := c.revisionPinner(cd)
+	if pinner == nil {
+		return false
+	}
+
+	revision, err := pinner.TargetRevision(cd)
+	if err != nil {
+		return false
+	}
+	return revision == cd.Status.PendingRevision
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/observers"
+	"github.com/fluxcd/flagger/pkg/notifier"
+)
+
+const (
+	// validationResyncInterval is the interval at which the metric templates
+	// and alert providers are validated again, to detect providers going offline
+	validationResyncInterval = 5 * time.Minute
+
+	// validationTimeout bounds the online check of a metric template provider
+	validationTimeout = 30 * time.Second
+)
+
+// Reasons of the metric template and alert provider Ready conditions
+const (
+	ValidatedReason       = "Validated"
+	SecretErrorReason     = "SecretError"
+	ProviderErrorReason   = "ProviderError"
+	ProviderOfflineReason = "ProviderOffline"
+	QueryErrorReason      = "QueryError"
+	AddressErrorReason    = "AddressError"
+)
+
+// enqueueValidation adds a metric template or alert provider to the validation queue,
+// the key has the kind/namespace/name format
+func (c *Controller) enqueueValidation(kind string, obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(err)
+		return
+	}
+	c.validationQueue.Add(kind + "/" + key)
+}
+
+// enqueueAllValidations adds every metric template and alert provider to the validation queue
+func (c *Controller) enqueueAllValidations() {
+	templates, err := c.flaggerInformers.MetricInformer.Lister().List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+	}
+	for _, template := range templates {
+		c.enqueueValidation(flaggerv1.MetricTemplateKind, template)
+	}
+
+	alertProviders, err := c.flaggerInformers.AlertInformer.Lister().List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(err)
+	}
+	for _, provider := range alertProviders {
+		c.enqueueValidation(flaggerv1.AlertProviderKind, provider)
+	}
+}
+
+// enqueueSecretReferences adds the metric templates and alert providers referencing the secret to the validation queue
+func (c *Controller) enqueueSecretReferences(namespace, name string) {
+	templates, _ := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).List(labels.Everything())
+	for _, template := range templates {
+		if ref := template.Spec.Provider.SecretRef; ref != nil && ref.Name == name {
+			c.enqueueValidation(flaggerv1.MetricTemplateKind, template)
+		}
+	}
+
+	alertProviders, _ := c.flaggerInformers.AlertInformer.Lister().AlertProviders(namespace).List(labels.Everything())
+	for _, provider := range alertProviders {
+		if ref := provider.Spec.SecretRef; ref != nil && ref.Name == name {
+			c.enqueueValidation(flaggerv1.AlertProviderKind, provider)
+		}
+	}
+}
+
+func (c *Controller) processNextValidation() bool {
+	obj, shutdown := c.validationQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.validationQueue.Done(obj)
+
+	key, ok := obj.(string)
+	if !ok {
+		c.validationQueue.Forget(obj)
+		utilruntime.HandleError(fmt.Errorf("expected string in validation queue but got %#v", obj))
+		return true
+	}
+
+	if err := c.syncValidation(key); err != nil {
+		c.validationQueue.AddRateLimited(key)
+		utilruntime.HandleError(fmt.Errorf("error validating '%s': %w", key, err))
+		return true
+	}
+	c.validationQueue.Forget(obj)
+	return true
+}
+
+func (c *Controller) syncValidation(key string) error {
+	kind, objectKey, _ := strings.Cut(key, "/")
+	namespace, name, err := cache.SplitMetaNamespaceKey(objectKey)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key: %s", key))
+		return nil
+	}
+
+	switch kind {
+	case flaggerv1.MetricTemplateKind:
+		return c.syncMetricTemplate(namespace, name)
+	case flaggerv1.AlertProviderKind:
+		return c.syncAlertProvider(namespace, name)
+	default:
+		utilruntime.HandleError(fmt.Errorf("invalid resource kind: %s", key))
+		return nil
+	}
+}
+
+// syncMetricTemplate validates the template and sets its Ready condition
+func (c *Controller) syncMetricTemplate(namespace, name string) error {
+	template, err := c.flaggerInformers.MetricInformer.Lister().MetricTemplates(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	status, reason, message := c.validateMetricTemplate(template)
+	condition := flaggerv1.MetricTemplateCondition{
+		Type:               flaggerv1.MetricTemplateReadyType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+
+	for _, current := range template.Status.Conditions {
+		if current.Type != condition.Type {
+			continue
+		}
+		if current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+			return nil
+		}
+		if current.Status == condition.Status {
+			condition.LastTransitionTime = current.LastTransitionTime
+		}
+	}
+
+	templateCopy := template.DeepCopy()
+	templateCopy.Status.Conditions = []flaggerv1.MetricTemplateCondition{condition}
+	_, err = c.flaggerClient.FlaggerV1beta1().MetricTemplates(namespace).UpdateStatus(context.TODO(), templateCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("metric template %s.%s status update failed: %w", name, namespace, err)
+	}
+	return nil
+}
+
+// validateMetricTemplate resolves the secret, builds the provider, renders the query
+// with a sample model and checks that the provider is online
+func (c *Controller) validateMetricTemplate(template *flaggerv1.MetricTemplate) (corev1.ConditionStatus, string, string) {
+	secret, err := c.getMetricTemplateSecret(template)
+	if err != nil {
+		return corev1.ConditionFalse, SecretErrorReason,
+			fmt.Sprintf("Secret %s error: %v", template.Spec.Provider.SecretRef.Name, err)
+	}
+
+	provider, err := c.providerPool.Provider(flaggerv1.MetricInterval, template, secret, c.kubeConfig)
+	if err != nil {
+		return corev1.ConditionFalse, ProviderErrorReason,
+			fmt.Sprintf("Provider %s error: %v", template.Spec.Provider.Type, err)
+	}
+
+	model := flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: template.Namespace,
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Ingress:   "podinfo",
+		Route:     "podinfo",
+		Interval:  flaggerv1.MetricInterval,
+		Variables: map[string]string{},
+	}
+	if _, err := observers.RenderQuery(template.Spec.Query, model); err != nil {
+		return corev1.ConditionFalse, QueryErrorReason, fmt.Sprintf("Query render error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), validationTimeout)
+	defer cancel()
+	if ok, err := provider.IsOnline(ctx); err != nil {
+		return corev1.ConditionFalse, ProviderOfflineReason,
+			fmt.Sprintf("Provider %s is offline: %v", template.Spec.Provider.Type, err)
+	} else if !ok {
+		return corev1.ConditionFalse, ProviderOfflineReason,
+			fmt.Sprintf("Provider %s is offline", template.Spec.Provider.Type)
+	}
+
+	return corev1.ConditionTrue, ValidatedReason, fmt.Sprintf("Provider %s is online", template.Spec.Provider.Type)
+}
+
+// syncAlertProvider validates the alert provider and sets its Ready condition
+func (c *Controller) syncAlertProvider(namespace, name string) error {
+	provider, err := c.flaggerInformers.AlertInformer.Lister().AlertProviders(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	status, reason, message := c.validateAlertProvider(provider)
+	condition := flaggerv1.AlertProviderCondition{
+		Type:               flaggerv1.AlertProviderReadyType,
+		Status:             status,
+		LastUpdateTime:     metav1.Now(),
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+
+	for _, current := range provider.Status.Conditions {
+		if current.Type != condition.Type {
+			continue
+		}
+		if current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+			return nil
+		}
+		if current.Status == condition.Status {
+			condition.LastTransitionTime = current.LastTransitionTime
+		}
+	}
+
+	providerCopy := provider.DeepCopy()
+	providerCopy.Status.Conditions = []flaggerv1.AlertProviderCondition{condition}
+	_, err = c.flaggerClient.FlaggerV1beta1().AlertProviders(namespace).UpdateStatus(context.TODO(), providerCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("alert provider %s.%s status update failed: %w", name, namespace, err)
+	}
+	return nil
+}
+
+// validateAlertProvider resolves the address and builds the notifier for the provider type
+func (c *Controller) validateAlertProvider(provider *flaggerv1.AlertProvider) (corev1.ConditionStatus, string, string) {
+	address, token, err := c.getAlertProviderAddress(provider)
+	if err != nil {
+		return corev1.ConditionFalse, SecretErrorReason, fmt.Sprintf("Address %v", err)
+	}
+	if address == "" {
+		return corev1.ConditionFalse, AddressErrorReason, "Address is not set"
+	}
+
+	if _, err := notifier.NewFactory(address, token, provider.Spec.Proxy, "flagger", "general").Notifier(provider.Spec.Type); err != nil {
+		return corev1.ConditionFalse, ProviderErrorReason, fmt.Sprintf("Provider %s error: %v", provider.Spec.Type, err)
+	}
+
+	return corev1.ConditionTrue, ValidatedReason, fmt.Sprintf("Provider %s is valid", provider.Spec.Type)
+}
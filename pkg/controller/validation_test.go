@@ -0,0 +1,171 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestController_syncMetricTemplate(t *testing.T) {
+	offline := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer offline.Close()
+
+	tests := []struct {
+		name     string
+		provider flaggerv1.MetricTemplateProvider
+		query    string
+		status   corev1.ConditionStatus
+		reason   string
+	}{
+		{
+			name:     "valid",
+			provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: testMetricsServerURL},
+			query:    `sum(envoy_cluster_upstream_rq{envoy_cluster_name=~"{{ namespace }}_{{ target }}"})`,
+			status:   corev1.ConditionTrue,
+			reason:   ValidatedReason,
+		},
+		{
+			name: "secret not found",
+			provider: flaggerv1.MetricTemplateProvider{
+				Type:      "prometheus",
+				Address:   testMetricsServerURL,
+				SecretRef: &corev1.LocalObjectReference{Name: "not-found"},
+			},
+			query:  "vector(1)",
+			status: corev1.ConditionFalse,
+			reason: SecretErrorReason,
+		},
+		{
+			name:     "invalid address",
+			provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: "::invalid"},
+			query:    "vector(1)",
+			status:   corev1.ConditionFalse,
+			reason:   ProviderErrorReason,
+		},
+		{
+			name:     "query render error",
+			provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: testMetricsServerURL},
+			query:    "sum({{ unknown }})",
+			status:   corev1.ConditionFalse,
+			reason:   QueryErrorReason,
+		},
+		{
+			name:     "offline",
+			provider: flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: offline.URL},
+			query:    "vector(1)",
+			status:   corev1.ConditionFalse,
+			reason:   ProviderOfflineReason,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocks := newDeploymentFixture(nil)
+			template := &flaggerv1.MetricTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "validation"},
+				Spec:       flaggerv1.MetricTemplateSpec{Provider: tt.provider, Query: tt.query},
+			}
+			_, err := mocks.flaggerClient.FlaggerV1beta1().MetricTemplates("default").Create(context.TODO(), template, metav1.CreateOptions{})
+			require.NoError(t, err)
+			require.NoError(t, mocks.ctrl.flaggerInformers.MetricInformer.Informer().GetIndexer().Add(template))
+
+			require.NoError(t, mocks.ctrl.syncMetricTemplate("default", "validation"))
+
+			result, err := mocks.flaggerClient.FlaggerV1beta1().MetricTemplates("default").Get(context.TODO(), "validation", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Len(t, result.Status.Conditions, 1)
+			assert.Equal(t, flaggerv1.MetricTemplateReadyType, result.Status.Conditions[0].Type)
+			assert.Equal(t, tt.status, result.Status.Conditions[0].Status)
+			assert.Equal(t, tt.reason, result.Status.Conditions[0].Reason)
+			assert.NotContains(t, result.Status.Conditions[0].Message, "<nil>")
+		})
+	}
+}
+
+func TestController_syncAlertProvider(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   flaggerv1.AlertProviderSpec
+		status corev1.ConditionStatus
+		reason string
+	}{
+		{
+			name:   "address from secret",
+			spec:   flaggerv1.AlertProviderSpec{Type: "slack", SecretRef: &corev1.LocalObjectReference{Name: "alert-secret"}},
+			status: corev1.ConditionTrue,
+			reason: ValidatedReason,
+		},
+		{
+			name:   "secret not found",
+			spec:   flaggerv1.AlertProviderSpec{Type: "slack", SecretRef: &corev1.LocalObjectReference{Name: "not-found"}},
+			status: corev1.ConditionFalse,
+			reason: SecretErrorReason,
+		},
+		{
+			name:   "no address",
+			spec:   flaggerv1.AlertProviderSpec{Type: "slack"},
+			status: corev1.ConditionFalse,
+			reason: AddressErrorReason,
+		},
+		{
+			name:   "invalid address",
+			spec:   flaggerv1.AlertProviderSpec{Type: "msteams", Address: "::invalid"},
+			status: corev1.ConditionFalse,
+			reason: ProviderErrorReason,
+		},
+		{
+			name:   "unsupported type",
+			spec:   flaggerv1.AlertProviderSpec{Type: "pager", Address: "http://mock.pager"},
+			status: corev1.ConditionFalse,
+			reason: ProviderErrorReason,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocks := newDeploymentFixture(nil)
+			provider := &flaggerv1.AlertProvider{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "validation"},
+				Spec:       tt.spec,
+			}
+			_, err := mocks.flaggerClient.FlaggerV1beta1().AlertProviders("default").Create(context.TODO(), provider, metav1.CreateOptions{})
+			require.NoError(t, err)
+			require.NoError(t, mocks.ctrl.flaggerInformers.AlertInformer.Informer().GetIndexer().Add(provider))
+
+			require.NoError(t, mocks.ctrl.syncAlertProvider("default", "validation"))
+
+			result, err := mocks.flaggerClient.FlaggerV1beta1().AlertProviders("default").Get(context.TODO(), "validation", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Len(t, result.Status.Conditions, 1)
+			assert.Equal(t, flaggerv1.AlertProviderReadyType, result.Status.Conditions[0].Type)
+			assert.Equal(t, tt.status, result.Status.Conditions[0].Status)
+			assert.Equal(t, tt.reason, result.Status.Conditions[0].Reason)
+		})
+	}
+}
@@ -0,0 +1,463 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Mismatch types
+const (
+	StatusMismatch = "status"
+	HeaderMismatch = "header"
+	BodyMismatch   = "body"
+	ErrorMismatch  = "error"
+)
+
+// hopHeaders are removed from the proxied requests and responses
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// DiffProxyOptions holds the diff proxy configuration
+type DiffProxyOptions struct {
+	// PrimaryURL is the address of the primary service
+	PrimaryURL string
+	// CanaryURL is the address of the canary service
+	CanaryURL string
+	// Timeout of the requests sent to the primary and canary
+	Timeout time.Duration
+	// IgnoreHeaders is the list of response headers excluded from the comparison
+	IgnoreHeaders []string
+	// IgnoreFields is the list of JSON fields excluded from the comparison,
+	// the fields are dot separated paths where * matches any key or array index
+	IgnoreFields []string
+	// NoiseDetection sends the GET and HEAD requests twice to the primary and excludes from
+	// the comparison the headers and fields that differ between the primary responses,
+	// the other methods are sent once to avoid duplicating writes
+	NoiseDetection bool
+}
+
+// DiffProxy sends the requests to the primary and canary, compares the responses
+// and replies with the response of the primary
+type DiffProxy struct {
+	primary        *url.URL
+	canary         *url.URL
+	client         *http.Client
+	ignoreHeaders  map[string]bool
+	ignoreFields   [][]string
+	noiseDetection bool
+	logger         *zap.SugaredLogger
+
+	requests   prometheus.Counter
+	mismatched prometheus.Counter
+	mismatches *prometheus.CounterVec
+	errors     prometheus.Counter
+}
+
+// diffResponse holds the response of an upstream
+type diffResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// DiffResult holds the differences between the primary and canary responses
+type DiffResult struct {
+	Status  bool
+	Headers []string
+	Fields  []string
+}
+
+// Mismatched returns true if the responses differ
+func (r DiffResult) Mismatched() bool {
+	return r.Status || len(r.Headers) > 0 || len(r.Fields) > 0
+}
+
+// NewDiffProxy creates a diff proxy and registers the Prometheus metrics
+func NewDiffProxy(opts DiffProxyOptions, logger *zap.SugaredLogger, register bool) (*DiffProxy, error) {
+	primary, err := url.Parse(opts.PrimaryURL)
+	if err != nil || primary.Host == "" {
+		return nil, fmt.Errorf("invalid primary URL %q", opts.PrimaryURL)
+	}
+	canary, err := url.Parse(opts.CanaryURL)
+	if err != nil || canary.Host == "" {
+		return nil, fmt.Errorf("invalid canary URL %q", opts.CanaryURL)
+	}
+
+	ignoreHeaders := make(map[string]bool)
+	for _, h := range opts.IgnoreHeaders {
+		if h = strings.TrimSpace(h); h != "" {
+			ignoreHeaders[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+	var ignoreFields [][]string
+	for _, f := range opts.IgnoreFields {
+		if f = strings.TrimSpace(f); f != "" {
+			ignoreFields = append(ignoreFields, strings.Split(f, "."))
+		}
+	}
+
+	requests := prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "diff_requests_total",
+		Help:      "Total number of requests compared by the diff proxy",
+	})
+
+	mismatched := prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "diff_mismatched_requests_total",
+		Help:      "Total number of requests for which the canary response differs from the primary",
+	})
+
+	mismatches := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "diff_mismatches_total",
+		Help:      "Total number of differences between the primary and canary responses by type",
+	}, []string{"type"})
+
+	errors := prometheus.NewCounter(prometheus.CounterOpts{
+		Subsystem: "flagger_loadtester",
+		Name:      "diff_primary_errors_total",
+		Help:      "Total number of requests that couldn't be compared because the primary failed",
+	})
+
+	if register {
+		prometheus.MustRegister(requests)
+		prometheus.MustRegister(mismatched)
+		prometheus.MustRegister(mismatches)
+		prometheus.MustRegister(errors)
+	}
+
+	return &DiffProxy{
+		primary:        primary,
+		canary:         canary,
+		client:         &http.Client{Timeout: opts.Timeout},
+		ignoreHeaders:  ignoreHeaders,
+		ignoreFields:   ignoreFields,
+		noiseDetection: opts.NoiseDetection,
+		logger:         logger,
+		requests:       requests,
+		mismatched:     mismatched,
+		mismatches:     mismatches,
+		errors:         errors,
+	}, nil
+}
+
+// ListenAndServe starts the diff proxy server and waits for SIGTERM
+func (p *DiffProxy) ListenAndServe(port string, stopCh <-chan struct{}) {
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: p,
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			p.logger.Fatalf("Diff proxy server crashed %v", err)
+		}
+	}()
+
+	<-stopCh
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		p.logger.Errorf("Diff proxy graceful shutdown failed %v", err)
+	} else {
+		p.logger.Info("Diff proxy stopped")
+	}
+}
+
+// ServeHTTP sends the request to the primary and canary, records the differences
+// between the responses and replies with the response of the primary
+func (p *DiffProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		p.logger.Error("reading the request body failed", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	// only the safe methods are replayed, a second write could change the primary state
+	noiseDetection := p.noiseDetection && (r.Method == http.MethodGet || r.Method == http.MethodHead)
+	targets := []*url.URL{p.primary, p.canary}
+	if noiseDetection {
+		targets = append(targets, p.primary)
+	}
+
+	responses := make([]*diffResponse, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target *url.URL) {
+			defer wg.Done()
+			responses[i], errs[i] = p.forward(r, body, target)
+		}(i, target)
+	}
+	wg.Wait()
+
+	primary, canary := responses[0], responses[1]
+	if errs[0] != nil {
+		p.errors.Inc()
+		p.logger.Errorf("primary request %s %s failed: %v", r.Method, r.URL.RequestURI(), errs[0])
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	p.requests.Inc()
+	if errs[1] != nil {
+		p.mismatched.Inc()
+		p.mismatches.WithLabelValues(ErrorMismatch).Inc()
+		p.logger.Infof("canary request %s %s failed: %v", r.Method, r.URL.RequestURI(), errs[1])
+	} else {
+		var noise *diffResponse
+		if noiseDetection && errs[2] == nil {
+			noise = responses[2]
+		}
+		if result := p.Compare(primary, canary, noise); result.Mismatched() {
+			p.record(result)
+			p.logger.With("status", result.Status, "headers", result.Headers, "fields", result.Fields).
+				Infof("canary response differs for %s %s", r.Method, r.URL.RequestURI())
+		}
+	}
+
+	for k, values := range primary.header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(primary.status)
+	w.Write(primary.body)
+}
+
+// record increments the mismatch counters
+func (p *DiffProxy) record(result DiffResult) {
+	p.mismatched.Inc()
+	if result.Status {
+		p.mismatches.WithLabelValues(StatusMismatch).Inc()
+	}
+	if len(result.Headers) > 0 {
+		p.mismatches.WithLabelValues(HeaderMismatch).Inc()
+	}
+	if len(result.Fields) > 0 {
+		p.mismatches.WithLabelValues(BodyMismatch).Inc()
+	}
+}
+
+// forward sends a copy of the request to the target
+func (p *DiffProxy) forward(r *http.Request, body []byte, target *url.URL) (*diffResponse, error) {
+	u := *target
+	u.Path = strings.TrimSuffix(target.Path, "/") + r.URL.Path
+	u.RawQuery = r.URL.RawQuery
+
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Header.Clone()
+	for _, h := range hopHeaders {
+		req.Header.Del(h)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	header := resp.Header.Clone()
+	for _, h := range hopHeaders {
+		header.Del(h)
+	}
+	return &diffResponse{status: resp.StatusCode, header: header, body: respBody}, nil
+}
+
+// Compare returns the differences between the primary and canary responses, the headers and
+// fields that differ between the primary and the noise response are excluded from the comparison
+func (p *DiffProxy) Compare(primary, canary, noise *diffResponse) DiffResult {
+	var result DiffResult
+
+	if primary.status != canary.status && (noise == nil || noise.status == primary.status) {
+		result.Status = true
+	}
+
+	for _, name := range headerNames(primary.header, canary.header) {
+		if p.ignoreHeaders[name] {
+			continue
+		}
+		if noise != nil && !slices.Equal(primary.header.Values(name), noise.header.Values(name)) {
+			continue
+		}
+		if !slices.Equal(primary.header.Values(name), canary.header.Values(name)) {
+			result.Headers = append(result.Headers, name)
+		}
+	}
+
+	result.Fields = p.compareBodies(primary, canary, noise)
+	return result
+}
+
+// compareBodies returns the JSON fields that differ, or the body itself if the responses aren't JSON
+func (p *DiffProxy) compareBodies(primary, canary, noise *diffResponse) []string {
+	primaryFields, errPrimary := flattenJSON(primary.body)
+	canaryFields, errCanary := flattenJSON(canary.body)
+	if errPrimary != nil || errCanary != nil {
+		if bytes.Equal(primary.body, canary.body) || (noise != nil && !bytes.Equal(primary.body, noise.body)) {
+			return nil
+		}
+		return []string{"body"}
+	}
+
+	var noiseFields map[string]string
+	if noise != nil {
+		noiseFields, _ = flattenJSON(noise.body)
+	}
+
+	var fields []string
+	for _, path := range fieldPaths(primaryFields, canaryFields) {
+		if p.isIgnoredField(path) {
+			continue
+		}
+		primaryValue, inPrimary := primaryFields[path]
+		if noiseFields != nil {
+			if noiseValue, inNoise := noiseFields[path]; inNoise != inPrimary || noiseValue != primaryValue {
+				continue
+			}
+		}
+		if canaryValue, inCanary := canaryFields[path]; inCanary != inPrimary || canaryValue != primaryValue {
+			fields = append(fields, path)
+		}
+	}
+	return fields
+}
+
+// isIgnoredField returns true if the field path matches one of the ignored fields
+func (p *DiffProxy) isIgnoredField(path string) bool {
+	segments := strings.Split(path, ".")
+	for _, pattern := range p.ignoreFields {
+		if len(pattern) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, s := range pattern {
+			if s != "*" && s != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenJSON returns the leaf values of a JSON document indexed by their dot separated path
+func flattenJSON(data []byte) (map[string]string, error) {
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	var flatten func(path string, value interface{})
+	flatten = func(path string, value interface{}) {
+		join := func(key string) string {
+			if path == "" {
+				return key
+			}
+			return path + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, item := range v {
+				flatten(join(key), item)
+			}
+		case []interface{}:
+			for i, item := range v {
+				flatten(join(strconv.Itoa(i)), item)
+			}
+		default:
+			b, _ := json.Marshal(v)
+			fields[path] = string(b)
+		}
+	}
+	flatten("", doc)
+	return fields, nil
+}
+
+// headerNames returns the sorted union of the header names
+func headerNames(a, b http.Header) []string {
+	var names []string
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fieldPaths returns the sorted union of the field paths
+func fieldPaths(a, b map[string]string) []string {
+	var paths []string
+	for path := range a {
+		paths = append(paths, path)
+	}
+	for path := range b {
+		if _, ok := a[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadtester
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func newDiffProxyFixture(t *testing.T, primary, canary http.HandlerFunc, opts DiffProxyOptions) *DiffProxy {
+	primarySrv := httptest.NewServer(primary)
+	t.Cleanup(primarySrv.Close)
+	canarySrv := httptest.NewServer(canary)
+	t.Cleanup(canarySrv.Close)
+
+	opts.PrimaryURL = primarySrv.URL
+	opts.CanaryURL = canarySrv.URL
+	opts.Timeout = 5 * time.Second
+	logger, _ := logger.NewLogger("info")
+
+	proxy, err := NewDiffProxy(opts, logger, false)
+	require.NoError(t, err)
+	return proxy
+}
+
+func TestDiffProxy_Match(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Date", time.Now().String())
+		fmt.Fprintf(w, `{"path":%q,"items":[1,2]}`, r.URL.Path)
+	}
+	proxy := newDiffProxyFixture(t, handler, handler, DiffProxyOptions{IgnoreHeaders: []string{"date"}})
+
+	resp := httptest.NewRecorder()
+	proxy.ServeHTTP(resp, httptest.NewRequest("GET", "/api/info", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"path":"/api/info","items":[1,2]}`, resp.Body.String())
+	assert.Equal(t, float64(1), testutil.ToFloat64(proxy.requests))
+	assert.Equal(t, float64(0), testutil.ToFloat64(proxy.mismatched))
+}
+
+func TestDiffProxy_Mismatch(t *testing.T) {
+	primary := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Version", "1")
+		fmt.Fprint(w, `{"version":"1","name":"podinfo"}`)
+	}
+	canary := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Version", "2")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"version":"2","name":"podinfo"}`)
+	}
+	proxy := newDiffProxyFixture(t, primary, canary, DiffProxyOptions{})
+
+	resp := httptest.NewRecorder()
+	proxy.ServeHTTP(resp, httptest.NewRequest("POST", "/", strings.NewReader("{}")))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "1", resp.Header().Get("X-Version"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(proxy.mismatched))
+	assert.Equal(t, float64(1), testutil.ToFloat64(proxy.mismatches.WithLabelValues(StatusMismatch)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(proxy.mismatches.WithLabelValues(HeaderMismatch)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(proxy.mismatches.WithLabelValues(BodyMismatch)))
+}
+
+func TestDiffProxy_CanaryError(t *testing.T) {
+	primary := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "OK")
+	}
+	proxy := newDiffProxyFixture(t, primary, primary, DiffProxyOptions{})
+	proxy.canary.Host = "127.0.0.1:1"
+
+	resp := httptest.NewRecorder()
+	proxy.ServeHTTP(resp, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "OK", resp.Body.String())
+	assert.Equal(t, float64(1), testutil.ToFloat64(proxy.mismatches.WithLabelValues(ErrorMismatch)))
+}
+
+func TestDiffProxy_NoiseDetection(t *testing.T) {
+	var counter int64
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&counter, 1)
+		w.Header().Set("X-Request-Count", fmt.Sprint(n))
+		fmt.Fprintf(w, `{"id":%d,"name":"podinfo"}`, n)
+	}
+	proxy := newDiffProxyFixture(t, handler, handler, DiffProxyOptions{NoiseDetection: true})
+
+	resp := httptest.NewRecorder()
+	proxy.ServeHTTP(resp, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, int64(3), atomic.LoadInt64(&counter))
+	assert.Equal(t, float64(1), testutil.ToFloat64(proxy.requests))
+	assert.Equal(t, float64(0), testutil.ToFloat64(proxy.mismatched))
+
+	// writes are not sent twice to the primary
+	for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+		atomic.StoreInt64(&counter, 0)
+		proxy.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/", strings.NewReader("{}")))
+		assert.Equal(t, int64(2), atomic.LoadInt64(&counter), method)
+	}
+}
+
+func TestDiffProxy_Compare(t *testing.T) {
+	proxy := &DiffProxy{
+		ignoreHeaders: map[string]bool{"Date": true},
+		ignoreFields:  [][]string{{"id"}, {"items", "*", "createdAt"}},
+	}
+
+	primary := &diffResponse{
+		status: 200,
+		header: http.Header{"Date": {"1"}, "Content-Type": {"application/json"}},
+		body:   []byte(`{"id":1,"items":[{"name":"a","createdAt":1},{"name":"b","createdAt":2}],"total":2}`),
+	}
+	canary := &diffResponse{
+		status: 200,
+		header: http.Header{"Date": {"2"}, "Content-Type": {"application/json"}},
+		body:   []byte(`{"id":2,"items":[{"name":"a","createdAt":3},{"name":"c","createdAt":4}],"count":2}`),
+	}
+
+	result := proxy.Compare(primary, canary, nil)
+	assert.False(t, result.Status)
+	assert.Empty(t, result.Headers)
+	assert.Equal(t, []string{"count", "items.1.name", "total"}, result.Fields)
+
+	canary.body = []byte("not json")
+	assert.Equal(t, []string{"body"}, proxy.Compare(primary, canary, nil).Fields)
+}
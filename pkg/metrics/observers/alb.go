@@ -0,0 +1,222 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+// albTargetGroupVariable is the query variable holding the TargetGroup dimension of the canary service
+const albTargetGroupVariable = "targetGroup"
+
+// targetGroupBindingGVR is the resource created by the AWS Load Balancer Controller
+// for each service targeted by an ingress
+var targetGroupBindingGVR = schema.GroupVersionResource{
+	Group:    "elbv2.k8s.aws",
+	Version:  "v1beta1",
+	Resource: "targetgroupbindings",
+}
+
+var albQueries = map[string]string{
+	"request-success-rate": `
+	[
+		{
+			"Id": "requests",
+			"Expression": "SEARCH('{AWS/ApplicationELB,LoadBalancer,TargetGroup} MetricName=\"RequestCount\" TargetGroup=\"{{ variables.targetGroup }}\"', 'Sum', 60)",
+			"ReturnData": false
+		},
+		{
+			"Id": "success",
+			"Expression": "SEARCH('{AWS/ApplicationELB,LoadBalancer,TargetGroup} MetricName=(\"HTTPCode_Target_2XX_Count\" OR \"HTTPCode_Target_3XX_Count\" OR \"HTTPCode_Target_4XX_Count\") TargetGroup=\"{{ variables.targetGroup }}\"', 'Sum', 60)",
+			"ReturnData": false
+		},
+		{
+			"Id": "rate",
+			"Expression": "100 * SUM(success) / SUM(requests)",
+			"ReturnData": true
+		}
+	]`,
+	"request-duration": `
+	[
+		{
+			"Id": "latency",
+			"Expression": "SEARCH('{AWS/ApplicationELB,LoadBalancer,TargetGroup} MetricName=\"TargetResponseTime\" TargetGroup=\"{{ variables.targetGroup }}\"', 'Average', 60)",
+			"ReturnData": false
+		},
+		{
+			"Id": "duration",
+			"Expression": "AVG(latency) * 1000",
+			"ReturnData": true
+		}
+	]`,
+}
+
+// ALBObserver implementation for the AWS Load Balancer Controller,
+// the builtin metrics are queried from CloudWatch
+type ALBObserver struct {
+	client     providers.Interface
+	clients    *cloudWatchClients
+	kubeClient dynamic.Interface
+	region     string
+	overrides  map[string]string
+}
+
+func (ob *ALBObserver) GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
+	client, err := ob.cloudWatchClient(model.Interval)
+	if err != nil {
+		return 0, err
+	}
+
+	model, err = ob.withTargetGroup(ctx, model)
+	if err != nil {
+		return 0, err
+	}
+
+	query, err := RenderQuery(builtinQuery(albQueries, ob.overrides, "request-success-rate"), model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := client.RunQuery(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	return value, nil
+}
+
+func (ob *ALBObserver) GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	client, err := ob.cloudWatchClient(model.Interval)
+	if err != nil {
+		return 0, err
+	}
+
+	model, err = ob.withTargetGroup(ctx, model)
+	if err != nil {
+		return 0, err
+	}
+
+	query, err := RenderQuery(builtinQuery(albQueries, ob.overrides, "request-duration"), model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := client.RunQuery(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
+
+// cloudWatchClient returns the CloudWatch client of the metric interval
+func (ob *ALBObserver) cloudWatchClient(interval string) (providers.Interface, error) {
+	if ob.client != nil {
+		return ob.client, nil
+	}
+	return ob.clients.get(ob.region, interval)
+}
+
+// withTargetGroup adds the TargetGroup dimension of the canary service to the query variables,
+// the dimension is resolved from the target group ARN of the canary TargetGroupBinding
+func (ob *ALBObserver) withTargetGroup(ctx context.Context, model flaggerv1.MetricTemplateModel) (flaggerv1.MetricTemplateModel, error) {
+	if ob.kubeClient == nil {
+		return model, fmt.Errorf("resolving the target group failed: Kubernetes client not set")
+	}
+
+	canaryService := fmt.Sprintf("%s-canary", model.Service)
+	list, err := ob.kubeClient.Resource(targetGroupBindingGVR).Namespace(model.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return model, fmt.Errorf("TargetGroupBinding %s list query error: %w", model.Namespace, err)
+	}
+
+	for _, item := range list.Items {
+		service, _, _ := unstructured.NestedString(item.Object, "spec", "serviceRef", "name")
+		if service != canaryService {
+			continue
+		}
+
+		// the dimension is the resource part of the ARN e.g. targetgroup/<name>/<id>
+		arn, _, _ := unstructured.NestedString(item.Object, "spec", "targetGroupARN")
+		if i := strings.Index(arn, ":targetgroup/"); i >= 0 {
+			variables := make(map[string]string, len(model.Variables)+1)
+			for k, v := range model.Variables {
+				variables[k] = v
+			}
+			variables[albTargetGroupVariable] = arn[i+1:]
+			model.Variables = variables
+			return model, nil
+		}
+	}
+
+	return model, fmt.Errorf("TargetGroupBinding of service %s.%s not found", canaryService, model.Namespace)
+}
+
+// cloudWatchClients caches the CloudWatch clients of the ALB observers by region and metric interval
+type cloudWatchClients struct {
+	mu      sync.Mutex
+	clients map[string]providers.Interface
+}
+
+func newCloudWatchClients() *cloudWatchClients {
+	return &cloudWatchClients{clients: make(map[string]providers.Interface)}
+}
+
+// get returns the cached client or builds a new one, a nil cache always builds a new client
+func (c *cloudWatchClients) get(region, interval string) (providers.Interface, error) {
+	if c == nil {
+		return newALBCloudWatchClient(region, interval)
+	}
+
+	key := region + "/" + interval
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[key]; ok {
+		return client, nil
+	}
+
+	client, err := newALBCloudWatchClient(region, interval)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[key] = client
+	return client, nil
+}
+
+func newALBCloudWatchClient(region, interval string) (providers.Interface, error) {
+	client, err := providers.NewCloudWatchProvider(interval, flaggerv1.MetricTemplateProvider{
+		Type:   "cloudwatch",
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("building CloudWatch client failed: %w", err)
+	}
+	return client, nil
+}
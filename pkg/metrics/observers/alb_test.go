@@ -0,0 +1,152 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+type fakeCloudWatchClient struct {
+	query string
+	value float64
+}
+
+func (f *fakeCloudWatchClient) RunQuery(_ context.Context, query string) (float64, error) {
+	f.query = query
+	return f.value, nil
+}
+
+func (f *fakeCloudWatchClient) IsOnline(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+func newTestTargetGroupBinding(namespace, name, service, arn string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "elbv2.k8s.aws/v1beta1",
+		"kind":       "TargetGroupBinding",
+		"metadata": map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		},
+		"spec": map[string]interface{}{
+			"serviceRef":     map[string]interface{}{"name": service, "port": int64(9898)},
+			"targetGroupARN": arn,
+		},
+	}}
+}
+
+func newTestALBDynamicClient(objects ...runtime.Object) *fakedynamic.FakeDynamicClient {
+	return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{targetGroupBindingGVR: "TargetGroupBindingList"}, objects...)
+}
+
+func TestALBObserver_GetRequestSuccessRate(t *testing.T) {
+	client := &fakeCloudWatchClient{value: 99}
+	observer := &ALBObserver{client: client, kubeClient: newTestALBDynamicClient(
+		newTestTargetGroupBinding("test-apps", "k8s-testapps-frontend-1111111111", "frontend-primary",
+			"arn:aws:elasticloadbalancing:eu-west-1:123456789012:targetgroup/k8s-testapps-frontend-1111111111/aaaaaaaaaaaaaaaa"),
+		newTestTargetGroupBinding("test-apps", "k8s-testapps-frontend-2222222222", "frontend-canary",
+			"arn:aws:elasticloadbalancing:eu-west-1:123456789012:targetgroup/k8s-testapps-frontend-2222222222/bbbbbbbbbbbbbbbb"),
+	)}
+
+	val, err := observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{
+		Name:      "frontend",
+		Namespace: "test-apps",
+		Target:    "frontend",
+		Service:   "frontend",
+		Interval:  "1m",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(99), val)
+
+	var queries []*cloudwatch.MetricDataQuery
+	require.NoError(t, json.Unmarshal([]byte(client.query), &queries))
+	require.Len(t, queries, 3)
+	assert.Equal(t, `SEARCH('{AWS/ApplicationELB,LoadBalancer,TargetGroup} MetricName="RequestCount" TargetGroup="targetgroup/k8s-testapps-frontend-2222222222/bbbbbbbbbbbbbbbb"', 'Sum', 60)`, *queries[0].Expression)
+	assert.Equal(t, "rate", *queries[2].Id)
+	assert.True(t, *queries[2].ReturnData)
+}
+
+func TestALBObserver_GetRequestDuration(t *testing.T) {
+	client := &fakeCloudWatchClient{value: 100}
+	observer := &ALBObserver{client: client, kubeClient: newTestALBDynamicClient(
+		newTestTargetGroupBinding("default", "k8s-default-podinfoc-3333333333", "podinfo-canary",
+			"arn:aws:elasticloadbalancing:eu-west-1:123456789012:targetgroup/k8s-default-podinfoc-3333333333/cccccccccccccccc"),
+	)}
+
+	val, err := observer.GetRequestDuration(context.TODO(), flaggerv1.MetricTemplateModel{
+		Name:      "podinfo",
+		Namespace: "default",
+		Target:    "podinfo",
+		Service:   "podinfo",
+		Interval:  "1m",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 100*time.Millisecond, val)
+
+	var queries []*cloudwatch.MetricDataQuery
+	require.NoError(t, json.Unmarshal([]byte(client.query), &queries))
+	require.Len(t, queries, 2)
+	assert.Equal(t, `SEARCH('{AWS/ApplicationELB,LoadBalancer,TargetGroup} MetricName="TargetResponseTime" TargetGroup="targetgroup/k8s-default-podinfoc-3333333333/cccccccccccccccc"', 'Average', 60)`, *queries[0].Expression)
+}
+
+func TestALBObserver_TargetGroupNotFound(t *testing.T) {
+	observer := &ALBObserver{client: &fakeCloudWatchClient{}, kubeClient: newTestALBDynamicClient(
+		newTestTargetGroupBinding("default", "k8s-default-podinfo-4444444444", "podinfo-primary",
+			"arn:aws:elasticloadbalancing:eu-west-1:123456789012:targetgroup/k8s-default-podinfo-4444444444/dddddddddddddddd"),
+	)}
+
+	_, err := observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{
+		Namespace: "default",
+		Service:   "podinfo",
+		Interval:  "1m",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "podinfo-canary.default not found")
+}
+
+func TestALBObserver_Region(t *testing.T) {
+	observer := &ALBObserver{}
+	_, err := observer.GetRequestDuration(context.TODO(), flaggerv1.MetricTemplateModel{Interval: "1m"})
+	require.Error(t, err)
+}
+
+func TestALBObserver_CloudWatchClientCache(t *testing.T) {
+	clients := newCloudWatchClients()
+	first, err := clients.get("eu-west-1", "1m")
+	require.NoError(t, err)
+	second, err := clients.get("eu-west-1", "1m")
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	other, err := clients.get("eu-west-1", "5m")
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+}
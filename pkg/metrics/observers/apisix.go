@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -30,7 +31,7 @@ var apisixQueries = map[string]string{
 		rate(
 			apisix_http_status{
 				route=~"{{ namespace }}_{{ route }}-{{ target }}-canary_.+",
-				code!~"5.."
+				code!~"{{ excludedStatus "5.." }}"
 			}[{{ interval }}]
 		)
 	)
@@ -44,7 +45,7 @@ var apisixQueries = map[string]string{
 	) * 100`,
 	"request-duration": `
 	histogram_quantile(
-		0.99, 
+		{{ quantile "0.99" }}, 
 		sum(
 			rate(
 				apisix_http_latency_bucket{
@@ -57,15 +58,16 @@ var apisixQueries = map[string]string{
 }
 
 type ApisixObserver struct {
-	client providers.Interface
+	client    providers.Interface
+	overrides map[string]string
 }
 
-func (ob *ApisixObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
-	query, err := RenderQuery(apisixQueries["request-success-rate"], model)
+func (ob *ApisixObserver) GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(builtinQuery(apisixQueries, ob.overrides, "request-success-rate"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -73,13 +75,13 @@ func (ob *ApisixObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateMo
 	return value, nil
 }
 
-func (ob *ApisixObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
-	query, err := RenderQuery(apisixQueries["request-duration"], model)
+func (ob *ApisixObserver) GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(builtinQuery(apisixQueries, ob.overrides, "request-duration"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -31,7 +32,7 @@ var appMeshQueries = map[string]string{
 			envoy_cluster_upstream_rq{
 				kubernetes_namespace="{{ namespace }}",
 				kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)",
-				envoy_response_code!~"5.*"
+				envoy_response_code!~"{{ excludedStatus "5.*" }}"
 			}[{{ interval }}]
 		)
 	) 
@@ -47,7 +48,7 @@ var appMeshQueries = map[string]string{
 	* 100`,
 	"request-duration": `
 	histogram_quantile(
-		0.99,
+		{{ quantile "0.99" }},
 		sum(
 			rate(
 				envoy_cluster_upstream_rq_time_bucket{
@@ -60,16 +61,17 @@ var appMeshQueries = map[string]string{
 }
 
 type AppMeshObserver struct {
-	client providers.Interface
+	client    providers.Interface
+	overrides map[string]string
 }
 
-func (ob *AppMeshObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
-	query, err := RenderQuery(appMeshQueries["request-success-rate"], model)
+func (ob *AppMeshObserver) GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(builtinQuery(appMeshQueries, ob.overrides, "request-success-rate"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -77,13 +79,13 @@ func (ob *AppMeshObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateM
 	return value, nil
 }
 
-func (ob *AppMeshObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
-	query, err := RenderQuery(appMeshQueries["request-duration"], model)
+func (ob *AppMeshObserver) GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(builtinQuery(appMeshQueries, ob.overrides, "request-duration"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
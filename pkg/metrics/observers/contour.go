@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -32,7 +33,7 @@ var contourQueries = map[string]string{
 		rate(
 			envoy_cluster_upstream_rq{
 				envoy_cluster_name=~"{{ namespace }}_{{ service }}-canary_[0-9a-zA-Z-]+",
-				envoy_response_code!~"5.*"
+				envoy_response_code!~"{{ excludedStatus "5.*" }}"
 			}[{{ interval }}]
 		)
 	) 
@@ -47,7 +48,7 @@ var contourQueries = map[string]string{
 	* 100`,
 	"request-duration": `
 	histogram_quantile(
-		0.99,
+		{{ quantile "0.99" }},
 		sum(
 			rate(
 				envoy_cluster_upstream_rq_time_bucket{
@@ -59,16 +60,17 @@ var contourQueries = map[string]string{
 }
 
 type ContourObserver struct {
-	client providers.Interface
+	client    providers.Interface
+	overrides map[string]string
 }
 
-func (ob *ContourObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
-	query, err := RenderQuery(contourQueries["request-success-rate"], model)
+func (ob *ContourObserver) GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(builtinQuery(contourQueries, ob.overrides, "request-success-rate"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -76,13 +78,13 @@ func (ob *ContourObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateM
 	return value, nil
 }
 
-func (ob *ContourObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
-	query, err := RenderQuery(contourQueries["request-duration"], model)
+func (ob *ContourObserver) GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(builtinQuery(contourQueries, ob.overrides, "request-duration"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
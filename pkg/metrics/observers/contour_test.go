@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -52,7 +53,7 @@ func TestContourObserver_GetRequestSuccessRate(t *testing.T) {
 		client: client,
 	}
 
-	val, err := observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{
+	val, err := observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{
 		Name:      "podinfo",
 		Namespace: "default",
 		Target:    "podinfo",
@@ -87,7 +88,7 @@ func TestContourObserver_CustomService_GetRequestSuccessRate(t *testing.T) {
 		client: client,
 	}
 
-	val, err := observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{
+	val, err := observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{
 		Name:      "podinfo",
 		Namespace: "default",
 		Target:    "podinfo",
@@ -122,7 +123,7 @@ func TestContourObserver_GetRequestDuration(t *testing.T) {
 		client: client,
 	}
 
-	val, err := observer.GetRequestDuration(flaggerv1.MetricTemplateModel{
+	val, err := observer.GetRequestDuration(context.TODO(), flaggerv1.MetricTemplateModel{
 		Name:      "podinfo",
 		Namespace: "default",
 		Target:    "podinfo",
@@ -157,7 +158,7 @@ func TestContourObserver_CustomService_GetRequestDuration(t *testing.T) {
 		client: client,
 	}
 
-	val, err := observer.GetRequestDuration(flaggerv1.MetricTemplateModel{
+	val, err := observer.GetRequestDuration(context.TODO(), flaggerv1.MetricTemplateModel{
 		Name:      "podinfo",
 		Namespace: "default",
 		Target:    "podinfo",
@@ -17,7 +17,13 @@ limitations under the License.
 package observers
 
 import (
+	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"text/template"
+
+	"k8s.io/client-go/dynamic"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	"github.com/fluxcd/flagger/pkg/metrics/providers"
@@ -25,80 +31,211 @@ import (
 
 type Factory struct {
 	Client providers.Interface
+
+	// Queries overrides the builtin metrics queries, indexed by observer and metric name
+	Queries map[string]map[string]string
+
+	// AWSRegion is the region of the CloudWatch metrics queried by the ALB observer
+	AWSRegion string
+
+	// DynamicClient is used by the ALB observer to look up the canary TargetGroupBinding
+	DynamicClient dynamic.Interface
+
+	cloudWatch *cloudWatchClients
 }
 
 func NewFactory(metricsServer string) (*Factory, error) {
-	client, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
-		Type:      "prometheus",
-		Address:   metricsServer,
-		SecretRef: nil,
-	}, nil)
+	client, err := newMetricsServerClient(metricsServer)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Factory{
-		Client: client,
+		Client:     client,
+		AWSRegion:  os.Getenv("AWS_REGION"),
+		cloudWatch: newCloudWatchClients(),
 	}, nil
 }
 
+// ForMetricsServer returns a copy of the factory that queries the given Prometheus server
+func (factory Factory) ForMetricsServer(metricsServer string) (*Factory, error) {
+	client, err := newMetricsServerClient(metricsServer)
+	if err != nil {
+		return nil, err
+	}
+
+	factory.Client = client
+	return &factory, nil
+}
+
+func newMetricsServerClient(metricsServer string) (providers.Interface, error) {
+	return providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "prometheus",
+		Address:   metricsServer,
+		SecretRef: nil,
+	}, nil)
+}
+
 func (factory Factory) Observer(provider string) Interface {
 	switch {
+	case provider == flaggerv1.ALBProvider:
+		return &ALBObserver{
+			clients:    factory.cloudWatch,
+			kubeClient: factory.DynamicClient,
+			region:     factory.AWSRegion,
+			overrides:  factory.Queries[flaggerv1.ALBProvider],
+		}
 	case strings.HasPrefix(provider, flaggerv1.AppMeshProvider):
 		return &AppMeshObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.AppMeshProvider],
 		}
 	case provider == flaggerv1.LinkerdProvider:
 		return &LinkerdObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.LinkerdProvider],
 		}
 	case provider == flaggerv1.IstioProvider:
 		return &IstioObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.IstioProvider],
 		}
 	case provider == flaggerv1.ContourProvider:
 		return &ContourObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.ContourProvider],
 		}
 	case strings.HasPrefix(provider, flaggerv1.GlooProvider):
 		return &GlooObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.GlooProvider],
 		}
 	case provider == flaggerv1.NGINXProvider:
 		return &NginxObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.NGINXProvider],
 		}
 	case provider == flaggerv1.KubernetesProvider || strings.HasPrefix(provider, flaggerv1.GatewayAPIProvider):
 		return &HttpObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.GatewayAPIProvider],
+		}
+	case provider == flaggerv1.HAProxyProvider:
+		return &HAProxyObserver{
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.HAProxyProvider],
 		}
 	case provider == flaggerv1.SkipperProvider:
 		return &SkipperObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.SkipperProvider],
 		}
 	case provider == flaggerv1.TraefikProvider:
 		return &TraefikObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.TraefikProvider],
 		}
 	case provider == flaggerv1.OsmProvider:
 		return &OsmObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.OsmProvider],
 		}
 	case provider == flaggerv1.KumaProvider:
 		return &KumaObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.KumaProvider],
 		}
 	case provider == flaggerv1.ApisixProvider:
 		return &ApisixObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.ApisixProvider],
 		}
 	case provider == flaggerv1.KnativeProvider:
 		return &KnativeObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.KnativeProvider],
 		}
 	default:
 		return &IstioObserver{
-			client: factory.Client,
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.IstioProvider],
+		}
+	}
+}
+
+// GatewayAPIObserver returns the observer of a Gateway API implementation,
+// the generic HTTP observer is used if the implementation is not set
+func (factory Factory) GatewayAPIObserver(implementation string) Interface {
+	switch implementation {
+	case flaggerv1.CiliumGatewayImplementation:
+		return &CiliumObserver{
+			client:    factory.Client,
+			overrides: factory.Queries[ciliumObserver],
+		}
+	case flaggerv1.IstioGatewayImplementation:
+		return &IstioGatewayObserver{
+			client:    factory.Client,
+			overrides: factory.Queries[istioGatewayObserver],
+		}
+	default:
+		return &HttpObserver{
+			client:    factory.Client,
+			overrides: factory.Queries[flaggerv1.GatewayAPIProvider],
+		}
+	}
+}
+
+// query override keys of the Gateway API implementation observers
+const (
+	ciliumObserver       = "cilium"
+	istioGatewayObserver = "istio-gateway"
+)
+
+// queryObservers are the observers that support query overrides
+var queryObservers = []string{
+	flaggerv1.ALBProvider,
+	flaggerv1.ApisixProvider,
+	flaggerv1.AppMeshProvider,
+	ciliumObserver,
+	flaggerv1.ContourProvider,
+	flaggerv1.GatewayAPIProvider,
+	flaggerv1.GlooProvider,
+	flaggerv1.HAProxyProvider,
+	flaggerv1.IstioProvider,
+	istioGatewayObserver,
+	flaggerv1.KnativeProvider,
+	flaggerv1.KumaProvider,
+	flaggerv1.LinkerdProvider,
+	flaggerv1.NGINXProvider,
+	flaggerv1.OsmProvider,
+	flaggerv1.SkipperProvider,
+	flaggerv1.TraefikProvider,
+}
+
+// ParseQueries parses the builtin metrics query overrides stored in a ConfigMap,
+// the keys have the <observer>.<metric> format e.g. istio.request-duration
+func ParseQueries(data map[string]string) (map[string]map[string]string, error) {
+	queries := make(map[string]map[string]string)
+	model := flaggerv1.MetricTemplateModel{}
+	for key, query := range data {
+		observer, metric, ok := strings.Cut(key, ".")
+		if !ok || observer == "" {
+			return nil, fmt.Errorf("query key %s is not in the <observer>.<metric> format", key)
+		}
+		if !slices.Contains(queryObservers, observer) {
+			return nil, fmt.Errorf("query key %s observer %s is not supported", key, observer)
+		}
+		if metric != "request-success-rate" && metric != "request-duration" {
+			return nil, fmt.Errorf("query key %s metric %s is not a builtin metric", key, metric)
+		}
+		if _, err := template.New(key).Funcs(model.TemplateFunctions()).Parse(query); err != nil {
+			return nil, fmt.Errorf("query key %s template parsing failed: %w", key, err)
+		}
+
+		if queries[observer] == nil {
+			queries[observer] = make(map[string]string)
 		}
+		queries[observer][metric] = query
 	}
+	return queries, nil
 }
@@ -0,0 +1,90 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestParseQueries(t *testing.T) {
+	queries, err := ParseQueries(map[string]string{
+		"istio.request-duration":          `histogram_quantile({{ quantile "0.99" }}, sum(rate(latency_bucket{app="{{ target }}"}[{{ interval }}])) by (le))`,
+		"gatewayapi.request-success-rate": `sum(rate(requests{code!~"{{ excludedStatus "5.." }}"}[{{ interval }}]))`,
+	})
+	require.NoError(t, err)
+	assert.Len(t, queries[flaggerv1.IstioProvider], 1)
+	assert.Len(t, queries[flaggerv1.GatewayAPIProvider], 1)
+
+	invalid := []map[string]string{
+		{"istio": "vector(1)"},
+		{"unknown.request-duration": "vector(1)"},
+		{"istio.error-rate": "vector(1)"},
+		{"istio.request-duration": "{{ target "},
+	}
+	for _, data := range invalid {
+		_, err := ParseQueries(data)
+		assert.Error(t, err)
+	}
+}
+
+func TestFactory_QueryOverrides(t *testing.T) {
+	expected := `histogram_quantile(0.5, sum(rate(latency_bucket{app="podinfo"}[1m])) by (le))`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, expected, r.URL.Query()["query"][0])
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	factory, err := NewFactory(ts.URL)
+	require.NoError(t, err)
+	factory.Queries, err = ParseQueries(map[string]string{
+		"istio.request-duration": `histogram_quantile({{ quantile "0.99" }}, sum(rate(latency_bucket{app="{{ target }}"}[{{ interval }}])) by (le))`,
+	})
+	require.NoError(t, err)
+
+	_, err = factory.Observer(flaggerv1.IstioProvider).GetRequestDuration(context.TODO(), flaggerv1.MetricTemplateModel{
+		Target:   "podinfo",
+		Interval: "1m",
+		Quantile: "0.5",
+	})
+	require.NoError(t, err)
+}
+
+func TestFactory_GatewayAPIObserver(t *testing.T) {
+	factory := Factory{
+		Queries: map[string]map[string]string{
+			"cilium": {"request-duration": "vector(1)"},
+		},
+	}
+
+	cilium, ok := factory.GatewayAPIObserver(flaggerv1.CiliumGatewayImplementation).(*CiliumObserver)
+	require.True(t, ok)
+	assert.Equal(t, "vector(1)", cilium.overrides["request-duration"])
+
+	assert.IsType(t, &IstioGatewayObserver{}, factory.GatewayAPIObserver(flaggerv1.IstioGatewayImplementation))
+	assert.IsType(t, &HttpObserver{}, factory.GatewayAPIObserver(""))
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/metrics/providers"
+)
+
+var haproxyQueries = map[string]string{
+	"request-success-rate": `
+	sum(
+		rate(
+			haproxy_backend_http_responses_total{
+				proxy=~"{{ namespace }}_(svc_)?{{ service }}-canary_.+",
+				code!~"{{ excludedStatus "5xx" }}"
+			}[{{ interval }}]
+		)
+	)
+	/
+	sum(
+		rate(
+			haproxy_backend_http_responses_total{
+				proxy=~"{{ namespace }}_(svc_)?{{ service }}-canary_.+"
+			}[{{ interval }}]
+		)
+	)
+	* 100`,
+	"request-duration": `
+	avg(
+		avg_over_time(
+			haproxy_backend_total_time_average_seconds{
+				proxy=~"{{ namespace }}_(svc_)?{{ service }}-canary_.+"
+			}[{{ interval }}]
+		)
+	)
+	* 1000`,
+}
+
+// HAProxyObserver implementation for the HAProxy Kubernetes Ingress controller
+type HAProxyObserver struct {
+	client    providers.Interface
+	overrides map[string]string
+}
+
+func (ob *HAProxyObserver) GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(builtinQuery(haproxyQueries, ob.overrides, "request-success-rate"), model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	return value, nil
+}
+
+func (ob *HAProxyObserver) GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(builtinQuery(haproxyQueries, ob.overrides, "request-duration"), model)
+	if err != nil {
+		return 0, fmt.Errorf("rendering query failed: %w", err)
+	}
+
+	value, err := ob.client.RunQuery(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("running query failed: %w", err)
+	}
+
+	ms := time.Duration(int64(value)) * time.Millisecond
+	return ms, nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -31,7 +32,7 @@ var httpQueries = map[string]string{
 			http_request_duration_seconds_count{
 				kubernetes_namespace="{{ namespace }}",
 				kubernetes_pod_name=~"{{ target }}-[0-9a-zA-Z]+(-[0-9a-zA-Z]+)",
-				status!~"5.*"
+				status!~"{{ excludedStatus "5.*" }}"
 			}[{{ interval }}]
 		)
 	) 
@@ -47,7 +48,7 @@ var httpQueries = map[string]string{
 	* 100`,
 	"request-duration": `
 	histogram_quantile(
-		0.99,
+		{{ quantile "0.99" }},
 		sum(
 			rate(
 				http_request_duration_seconds_bucket{
@@ -60,16 +61,17 @@ var httpQueries = map[string]string{
 }
 
 type HttpObserver struct {
-	client providers.Interface
+	client    providers.Interface
+	overrides map[string]string
 }
 
-func (ob *HttpObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
-	query, err := RenderQuery(httpQueries["request-success-rate"], model)
+func (ob *HttpObserver) GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(builtinQuery(httpQueries, ob.overrides, "request-success-rate"), model)
 	if err != nil {
 		return 0, err
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, err
 	}
@@ -77,13 +79,13 @@ func (ob *HttpObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateMode
 	return value, nil
 }
 
-func (ob *HttpObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
-	query, err := RenderQuery(httpQueries["request-duration"], model)
+func (ob *HttpObserver) GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(builtinQuery(httpQueries, ob.overrides, "request-duration"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
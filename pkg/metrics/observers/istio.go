@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -32,7 +33,7 @@ var istioQueries = map[string]string{
 				reporter="destination",
 				destination_workload_namespace="{{ namespace }}",
 				destination_workload=~"{{ target }}",
-				response_code!~"5.*"
+				response_code!~"{{ excludedStatus "5.*" }}"
 			}[{{ interval }}]
 		)
 	) 
@@ -49,7 +50,7 @@ var istioQueries = map[string]string{
 	* 100`,
 	"request-duration": `
 	histogram_quantile(
-		0.99,
+		{{ quantile "0.99" }},
 		sum(
 			rate(
 				istio_request_duration_milliseconds_bucket{
@@ -63,16 +64,17 @@ var istioQueries = map[string]string{
 }
 
 type IstioObserver struct {
-	client providers.Interface
+	client    providers.Interface
+	overrides map[string]string
 }
 
-func (ob *IstioObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
-	query, err := RenderQuery(istioQueries["request-success-rate"], model)
+func (ob *IstioObserver) GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
+	query, err := RenderQuery(builtinQuery(istioQueries, ob.overrides, "request-success-rate"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -80,13 +82,13 @@ func (ob *IstioObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateMod
 	return value, nil
 }
 
-func (ob *IstioObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
-	query, err := RenderQuery(istioQueries["request-duration"], model)
+func (ob *IstioObserver) GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(builtinQuery(istioQueries, ob.overrides, "request-duration"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
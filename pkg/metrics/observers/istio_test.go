@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -52,7 +53,7 @@ func TestIstioObserver_GetRequestSuccessRate(t *testing.T) {
 		client: client,
 	}
 
-	val, err := observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{
+	val, err := observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{
 		Name:      "podinfo",
 		Namespace: "default",
 		Target:    "podinfo",
@@ -87,7 +88,7 @@ func TestIstioObserver_GetRequestDuration(t *testing.T) {
 		client: client,
 	}
 
-	val, err := observer.GetRequestDuration(flaggerv1.MetricTemplateModel{
+	val, err := observer.GetRequestDuration(context.TODO(), flaggerv1.MetricTemplateModel{
 		Name:      "podinfo",
 		Namespace: "default",
 		Target:    "podinfo",
@@ -98,3 +99,44 @@ func TestIstioObserver_GetRequestDuration(t *testing.T) {
 
 	assert.Equal(t, 100*time.Millisecond, val)
 }
+
+func TestIstioObserver_Params(t *testing.T) {
+	expected := []string{
+		` sum( rate( istio_requests_total{ reporter="destination", destination_workload_namespace="default", destination_workload=~"podinfo", response_code!~"4.*|5.*" }[1m] ) ) / sum( rate( istio_requests_total{ reporter="destination", destination_workload_namespace="default", destination_workload=~"podinfo" }[1m] ) ) * 100`,
+		` histogram_quantile( 0.95, sum( rate( istio_request_duration_milliseconds_bucket{ reporter="destination", destination_workload_namespace="default", destination_workload=~"podinfo" }[1m] ) ) by (le) )`,
+	}
+
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query()["query"][0])
+		json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1,"100"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	client, err := providers.NewPrometheusProvider(flaggerv1.MetricTemplateProvider{
+		Type:    "prometheus",
+		Address: ts.URL,
+	}, nil)
+	require.NoError(t, err)
+
+	observer := &IstioObserver{
+		client: client,
+	}
+
+	model := flaggerv1.MetricTemplateModel{
+		Name:           "podinfo",
+		Namespace:      "default",
+		Target:         "podinfo",
+		Service:        "podinfo",
+		Interval:       "1m",
+		Quantile:       "0.95",
+		ExcludedStatus: "4.*|5.*",
+	}
+	_, err = observer.GetRequestSuccessRate(context.TODO(), model)
+	require.NoError(t, err)
+	_, err = observer.GetRequestDuration(context.TODO(), model)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, queries)
+}
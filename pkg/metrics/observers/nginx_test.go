@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -53,7 +54,7 @@ func TestNginxObserver_GetRequestSuccessRate(t *testing.T) {
 			client: client,
 		}
 
-		val, err := observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{
+		val, err := observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{
 			Name:      "podinfo",
 			Namespace: "nginx",
 			Target:    "podinfo",
@@ -83,7 +84,7 @@ func TestNginxObserver_GetRequestSuccessRate(t *testing.T) {
 			client: client,
 		}
 
-		_, err = observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{})
+		_, err = observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{})
 		require.True(t, errors.Is(err, providers.ErrNoValuesFound))
 	})
 }
@@ -111,7 +112,7 @@ func TestNginxObserver_GetRequestDuration(t *testing.T) {
 		client: client,
 	}
 
-	val, err := observer.GetRequestDuration(flaggerv1.MetricTemplateModel{
+	val, err := observer.GetRequestDuration(context.TODO(), flaggerv1.MetricTemplateModel{
 		Name:      "podinfo",
 		Namespace: "nginx",
 		Target:    "podinfo",
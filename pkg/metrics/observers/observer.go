@@ -17,12 +17,22 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"time"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 )
 
 type Interface interface {
-	GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error)
-	GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error)
+	GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error)
+	GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error)
+}
+
+// builtinQuery returns the override of the metric query if one is set,
+// otherwise the default query of the observer
+func builtinQuery(defaults, overrides map[string]string, metric string) string {
+	if query, ok := overrides[metric]; ok {
+		return query
+	}
+	return defaults[metric]
 }
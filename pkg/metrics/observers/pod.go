@@ -0,0 +1,180 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Builtin pod health metrics, computed from the canary pods status
+// without querying a metrics server
+const (
+	// PodRestartsMetric counts the container restarts of the canary pods,
+	// since the canary pods are created when the analysis starts this is
+	// the restart count delta for the current analysis
+	PodRestartsMetric = "pod-restarts"
+	// PodOOMKilledMetric counts the canary containers terminated with OOMKilled
+	PodOOMKilledMetric = "pod-oomkilled"
+	// PodCrashLoopMetric counts the canary containers waiting in CrashLoopBackOff
+	PodCrashLoopMetric = "pod-crashloop"
+	// PodReadinessFlapsMetric counts the canary pods that changed readiness
+	// within the metric interval after being started before it
+	PodReadinessFlapsMetric = "pod-readiness-flaps"
+	// PodWarningEventsMetric counts the Warning events emitted for the
+	// canary pods within the metric interval
+	PodWarningEventsMetric = "pod-warning-events"
+)
+
+// IsPodMetric returns true if the metric name refers to a builtin pod health metric
+func IsPodMetric(name string) bool {
+	switch name {
+	case PodRestartsMetric, PodOOMKilledMetric, PodCrashLoopMetric,
+		PodReadinessFlapsMetric, PodWarningEventsMetric:
+		return true
+	}
+	return false
+}
+
+// PodObserver computes the builtin pod health metrics using the Kubernetes API
+type PodObserver struct {
+	kubeClient kubernetes.Interface
+}
+
+// NewPodObserver returns an observer for the builtin pod health metrics
+func NewPodObserver(kubeClient kubernetes.Interface) *PodObserver {
+	return &PodObserver{
+		kubeClient: kubeClient,
+	}
+}
+
+// GetPodMetric returns the value of a pod health metric for the pods matching
+// the label selector, the interval defines the window for time based metrics
+func (ob *PodObserver) GetPodMetric(name string, namespace string, labelSelector string, interval time.Duration) (float64, error) {
+	pods, err := ob.kubeClient.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("pods %s list query error: %w", namespace, err)
+	}
+
+	since := time.Now().Add(-interval)
+	switch name {
+	case PodRestartsMetric:
+		return countContainers(pods.Items, func(cs corev1.ContainerStatus) int {
+			return int(cs.RestartCount)
+		}), nil
+	case PodOOMKilledMetric:
+		return countContainers(pods.Items, func(cs corev1.ContainerStatus) int {
+			if cs.State.Terminated != nil && cs.State.Terminated.Reason == "OOMKilled" {
+				return 1
+			}
+			if cs.LastTerminationState.Terminated != nil && cs.LastTerminationState.Terminated.Reason == "OOMKilled" {
+				return 1
+			}
+			return 0
+		}), nil
+	case PodCrashLoopMetric:
+		return countContainers(pods.Items, func(cs corev1.ContainerStatus) int {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+				return 1
+			}
+			return 0
+		}), nil
+	case PodReadinessFlapsMetric:
+		var total float64
+		for _, pod := range pods.Items {
+			if pod.Status.StartTime == nil || !pod.Status.StartTime.Time.Before(since) {
+				continue
+			}
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == corev1.PodReady && condition.LastTransitionTime.Time.After(since) {
+					total++
+				}
+			}
+		}
+		return total, nil
+	case PodWarningEventsMetric:
+		return ob.countWarningEvents(namespace, pods.Items, since)
+	default:
+		return 0, fmt.Errorf("unknown pod metric %s", name)
+	}
+}
+
+func (ob *PodObserver) countWarningEvents(namespace string, pods []corev1.Pod, since time.Time) (float64, error) {
+	if len(pods) == 0 {
+		return 0, nil
+	}
+
+	names := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		names[pod.Name] = true
+	}
+
+	events, err := ob.kubeClient.CoreV1().Events(namespace).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod,type=Warning",
+	})
+	if err != nil {
+		return 0, fmt.Errorf("events %s list query error: %w", namespace, err)
+	}
+
+	var total float64
+	for _, event := range events.Items {
+		if event.Type != corev1.EventTypeWarning || event.InvolvedObject.Kind != "Pod" ||
+			!names[event.InvolvedObject.Name] {
+			continue
+		}
+
+		last := event.LastTimestamp.Time
+		if event.Series != nil {
+			last = event.Series.LastObservedTime.Time
+		} else if last.IsZero() {
+			last = event.EventTime.Time
+		}
+		if last.Before(since) {
+			continue
+		}
+
+		count := event.Count
+		if event.Series != nil {
+			count = event.Series.Count
+		}
+		if count < 1 {
+			count = 1
+		}
+		total += float64(count)
+	}
+	return total, nil
+}
+
+func countContainers(pods []corev1.Pod, fn func(cs corev1.ContainerStatus) int) float64 {
+	var total float64
+	for _, pod := range pods {
+		for _, cs := range pod.Status.InitContainerStatuses {
+			total += float64(fn(cs))
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			total += float64(fn(cs))
+		}
+	}
+	return total
+}
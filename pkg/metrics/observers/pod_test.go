@@ -0,0 +1,128 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodObserver_GetPodMetric(t *testing.T) {
+	started := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	flapped := metav1.NewTime(time.Now().Add(-10 * time.Second))
+
+	canaryPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "podinfo-abc",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "podinfo"},
+		},
+		Status: corev1.PodStatus{
+			StartTime: &started,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionFalse, LastTransitionTime: flapped},
+			},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:         "podinfo",
+					RestartCount: 3,
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					},
+					LastTerminationState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"},
+					},
+				},
+				{
+					Name:         "sidecar",
+					RestartCount: 1,
+				},
+			},
+		},
+	}
+	primaryPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "podinfo-primary-abc",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "podinfo-primary"},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "podinfo", RestartCount: 5},
+			},
+		},
+	}
+	events := []*corev1.Event{
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "podinfo-abc.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "podinfo-abc"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Count:          2,
+			LastTimestamp:  flapped,
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "podinfo-abc.2", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "podinfo-abc"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Unhealthy",
+			Count:          1,
+			LastTimestamp:  started,
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "podinfo-primary-abc.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "podinfo-primary-abc"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Count:          1,
+			LastTimestamp:  flapped,
+		},
+	}
+
+	kubeClient := fake.NewSimpleClientset(canaryPod, primaryPod, events[0], events[1], events[2])
+	observer := NewPodObserver(kubeClient)
+
+	tests := []struct {
+		metric   string
+		expected float64
+	}{
+		{metric: PodRestartsMetric, expected: 4},
+		{metric: PodOOMKilledMetric, expected: 1},
+		{metric: PodCrashLoopMetric, expected: 1},
+		{metric: PodReadinessFlapsMetric, expected: 1},
+		{metric: PodWarningEventsMetric, expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			assert.True(t, IsPodMetric(tt.metric))
+			val, err := observer.GetPodMetric(tt.metric, "default", "app=podinfo", time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, val)
+		})
+	}
+
+	_, err := observer.GetPodMetric("request-duration", "default", "app=podinfo", time.Minute)
+	require.Error(t, err)
+	assert.False(t, IsPodMetric("request-duration"))
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"time"
@@ -31,7 +32,7 @@ const routePattern = `{{- $route := printf "kube(ew)?_%s__%s_canary__.*__%s_cana
 
 var skipperQueries = map[string]string{
 	"request-success-rate": routePattern + `
-	sum(rate(skipper_response_duration_seconds_bucket{route=~"{{ $route }}",code!~"5..",le="+Inf"}[{{ interval }}])) / 
+	sum(rate(skipper_response_duration_seconds_bucket{route=~"{{ $route }}",code!~"{{ excludedStatus "5.." }}",le="+Inf"}[{{ interval }}])) / 
 	sum(rate(skipper_response_duration_seconds_bucket{route=~"{{ $route }}",le="+Inf"}[{{ interval }}])) * 100`,
 	"request-duration": routePattern + `
 	sum(rate(skipper_serve_route_duration_seconds_sum{route=~"{{ $route }}"}[{{ interval }}])) / 
@@ -40,22 +41,23 @@ var skipperQueries = map[string]string{
 
 // SkipperObserver Implementation for Skipper (https://github.com/zalando/skipper)
 type SkipperObserver struct {
-	client providers.Interface
+	client    providers.Interface
+	overrides map[string]string
 }
 
 // GetRequestSuccessRate return value for Skipper Request Success Rate
-func (ob *SkipperObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+func (ob *SkipperObserver) GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
 
 	model = encodeModelForSkipper(model)
 
-	query, err := RenderQuery(skipperQueries["request-success-rate"], model)
+	query, err := RenderQuery(builtinQuery(skipperQueries, ob.overrides, "request-success-rate"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 	logger, _ := logger.NewLoggerWithEncoding("debug", "json")
 	logger.Debugf("GetRequestSuccessRate: %s", query)
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -64,18 +66,18 @@ func (ob *SkipperObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateM
 }
 
 // GetRequestDuration return value for Skipper Request Duration
-func (ob *SkipperObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+func (ob *SkipperObserver) GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
 
 	model = encodeModelForSkipper(model)
 
-	query, err := RenderQuery(skipperQueries["request-duration"], model)
+	query, err := RenderQuery(builtinQuery(skipperQueries, ob.overrides, "request-duration"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 	logger, _ := logger.NewLoggerWithEncoding("debug", "json")
 	logger.Debugf("GetRequestDuration: %s", query)
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
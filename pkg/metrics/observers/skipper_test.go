@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -50,7 +51,7 @@ func TestSkipperObserver_GetRequestSuccessRate(t *testing.T) {
 		require.NoError(t, err)
 
 		observer := &SkipperObserver{client: client}
-		val, err := observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{
+		val, err := observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{
 			Namespace: "skipper",
 			Interval:  "1m",
 			Service:   "backend",
@@ -76,7 +77,7 @@ func TestSkipperObserver_GetRequestSuccessRate(t *testing.T) {
 		require.NoError(t, err)
 
 		observer := &SkipperObserver{client: client}
-		_, err = observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{})
+		_, err = observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{})
 		require.True(t, errors.Is(err, providers.ErrNoValuesFound))
 	})
 }
@@ -101,7 +102,7 @@ func TestSkipperObserver_GetRequestDuration(t *testing.T) {
 	require.NoError(t, err)
 
 	observer := &SkipperObserver{client: client}
-	val, err := observer.GetRequestDuration(flaggerv1.MetricTemplateModel{
+	val, err := observer.GetRequestDuration(context.TODO(), flaggerv1.MetricTemplateModel{
 		Namespace: "skipper",
 		Interval:  "1m",
 		Service:   "backend",
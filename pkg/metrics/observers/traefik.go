@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -30,7 +31,7 @@ var traefikQueries = map[string]string{
 		rate(
 			traefik_service_request_duration_seconds_bucket{
 				service=~"{{ namespace }}-{{ target }}-canary-[0-9a-zA-Z-]+@kubernetescrd",
-				code!~"5..",
+				code!~"{{ excludedStatus "5.." }}",
 				le="+Inf"
 			}[{{ interval }}]
 		)
@@ -46,7 +47,7 @@ var traefikQueries = map[string]string{
 	) * 100`,
 	"request-duration": `
 	histogram_quantile(
-		0.99,
+		{{ quantile "0.99" }},
 		sum(
 			rate(
 				traefik_service_request_duration_seconds_bucket{
@@ -58,17 +59,18 @@ var traefikQueries = map[string]string{
 }
 
 type TraefikObserver struct {
-	client providers.Interface
+	client    providers.Interface
+	overrides map[string]string
 }
 
-func (ob *TraefikObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateModel) (float64, error) {
+func (ob *TraefikObserver) GetRequestSuccessRate(ctx context.Context, model flaggerv1.MetricTemplateModel) (float64, error) {
 
-	query, err := RenderQuery(traefikQueries["request-success-rate"], model)
+	query, err := RenderQuery(builtinQuery(traefikQueries, ob.overrides, "request-success-rate"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
@@ -76,13 +78,13 @@ func (ob *TraefikObserver) GetRequestSuccessRate(model flaggerv1.MetricTemplateM
 	return value, nil
 }
 
-func (ob *TraefikObserver) GetRequestDuration(model flaggerv1.MetricTemplateModel) (time.Duration, error) {
-	query, err := RenderQuery(traefikQueries["request-duration"], model)
+func (ob *TraefikObserver) GetRequestDuration(ctx context.Context, model flaggerv1.MetricTemplateModel) (time.Duration, error) {
+	query, err := RenderQuery(builtinQuery(traefikQueries, ob.overrides, "request-duration"), model)
 	if err != nil {
 		return 0, fmt.Errorf("rendering query failed: %w", err)
 	}
 
-	value, err := ob.client.RunQuery(query)
+	value, err := ob.client.RunQuery(ctx, query)
 	if err != nil {
 		return 0, fmt.Errorf("running query failed: %w", err)
 	}
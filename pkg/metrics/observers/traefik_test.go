@@ -17,6 +17,7 @@ limitations under the License.
 package observers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -52,7 +53,7 @@ func TestTraefikObserver_GetRequestSuccessRate(t *testing.T) {
 
 		observer := &TraefikObserver{client: client}
 
-		val, err := observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{
+		val, err := observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{
 			Name:      "podinfo",
 			Namespace: "default",
 			Target:    "podinfo",
@@ -79,7 +80,7 @@ func TestTraefikObserver_GetRequestSuccessRate(t *testing.T) {
 		require.NoError(t, err)
 
 		observer := &TraefikObserver{client: client}
-		_, err = observer.GetRequestSuccessRate(flaggerv1.MetricTemplateModel{})
+		_, err = observer.GetRequestSuccessRate(context.TODO(), flaggerv1.MetricTemplateModel{})
 		require.True(t, errors.Is(err, providers.ErrNoValuesFound))
 	})
 }
@@ -105,7 +106,7 @@ func TestTraefikObserver_GetRequestDuration(t *testing.T) {
 
 	observer := &TraefikObserver{client: client}
 
-	val, err := observer.GetRequestDuration(flaggerv1.MetricTemplateModel{
+	val, err := observer.GetRequestDuration(context.TODO(), flaggerv1.MetricTemplateModel{
 		Name:      "podinfo",
 		Namespace: "default",
 		Target:    "podinfo",
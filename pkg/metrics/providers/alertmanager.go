@@ -0,0 +1,195 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	alertmanagerAlertsPath = "/api/v2/alerts"
+	alertmanagerReadyPath  = "/-/ready"
+)
+
+// AlertmanagerProvider queries the Alertmanager v2 API for firing alerts
+type AlertmanagerProvider struct {
+	timeout  time.Duration
+	url      url.URL
+	headers  http.Header
+	username string
+	password string
+	token    string
+	client   *http.Client
+}
+
+type alertmanagerAlert struct {
+	Labels map[string]string `json:"labels"`
+	Status struct {
+		State string `json:"state"`
+	} `json:"status"`
+}
+
+// NewAlertmanagerProvider takes a provider spec and the credentials map,
+// validates the address, extracts the bearer token or username and password values
+// and returns an Alertmanager client ready to query the alerts API
+func NewAlertmanagerProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*AlertmanagerProvider, error) {
+	amURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	am := AlertmanagerProvider{
+		timeout: 5 * time.Second,
+		url:     *amURL,
+		headers: provider.Headers,
+		client:  http.DefaultClient,
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		am.client = &http.Client{Transport: t}
+	}
+
+	if provider.SecretRef != nil {
+		if token, ok := credentials["token"]; ok {
+			am.token = string(token)
+		} else {
+			if username, ok := credentials["username"]; ok {
+				am.username = string(username)
+			} else {
+				return nil, fmt.Errorf("%s credentials does not contain a username", provider.Type)
+			}
+
+			if password, ok := credentials["password"]; ok {
+				am.password = string(password)
+			} else {
+				return nil, fmt.Errorf("%s credentials does not contain a password", provider.Type)
+			}
+		}
+	}
+
+	return &am, nil
+}
+
+// RunQuery returns the number of firing alerts matching the label selector
+func (p *AlertmanagerProvider) RunQuery(ctx context.Context, selector string) (float64, error) {
+	alerts, err := p.FiringAlerts(ctx, selector)
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(alerts)), nil
+}
+
+// FiringAlerts returns the name of each active alert matching the label selector,
+// silenced and inhibited alerts are ignored
+func (p *AlertmanagerProvider) FiringAlerts(ctx context.Context, selector string) ([]string, error) {
+	matchers, err := parseAlertSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("active", "true")
+	q.Set("silenced", "false")
+	q.Set("inhibited", "false")
+	for _, m := range matchers {
+		q.Add("filter", m)
+	}
+
+	b, err := p.do(ctx, alertmanagerAlertsPath, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []alertmanagerAlert
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	alerts := make([]string, 0, len(result))
+	for _, alert := range result {
+		if alert.Status.State != "" && alert.Status.State != "active" {
+			continue
+		}
+		alerts = append(alerts, alert.Labels["alertname"])
+	}
+	return alerts, nil
+}
+
+// IsOnline calls the Alertmanager readiness endpoint and returns an error if the API is unreachable
+func (p *AlertmanagerProvider) IsOnline(ctx context.Context) (bool, error) {
+	if _, err := p.do(ctx, alertmanagerReadyPath, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *AlertmanagerProvider) do(ctx context.Context, apiPath string, query url.Values) ([]byte, error) {
+	u, err := url.Parse("." + apiPath)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse failed: %w", err)
+	}
+	u.Path = path.Join(p.url.Path, u.Path)
+	u = p.url.ResolveReference(u)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+
+	if p.headers != nil {
+		req.Header = p.headers.Clone()
+	}
+
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	} else if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	ctx, cancel := withTimeout(ctx, p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, statusError(r.StatusCode, fmt.Errorf("error response: Status %d %s: %s", r.StatusCode, http.StatusText(r.StatusCode), string(b)))
+	}
+
+	return b, nil
+}
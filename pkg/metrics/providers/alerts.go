@@ -0,0 +1,138 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// AlertsInterface is implemented by the providers that gate the analysis on firing alerts,
+// the query of these providers is a label selector e.g. {namespace="test", severity=~"critical|page"}
+type AlertsInterface interface {
+	// FiringAlerts returns the name of each firing alert matching the label selector
+	FiringAlerts(ctx context.Context, selector string) ([]string, error)
+}
+
+var alertMatcherRegexp = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*"((?:[^"\\]|\\.)*)"$`)
+
+// parseAlertSelector splits a label selector into Prometheus style matchers
+func parseAlertSelector(selector string) ([]string, error) {
+	selector = strings.TrimSpace(selector)
+	selector = strings.TrimSuffix(strings.TrimPrefix(selector, "{"), "}")
+
+	var parts []string
+	var part strings.Builder
+	quoted, escaped := false, false
+	for _, c := range selector {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			parts = append(parts, part.String())
+			part.Reset()
+			continue
+		}
+		part.WriteRune(c)
+	}
+	parts = append(parts, part.String())
+
+	var matchers []string
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		m := alertMatcherRegexp.FindStringSubmatch(p)
+		if m == nil {
+			return nil, fmt.Errorf("alert selector matcher %s is not valid", p)
+		}
+		matchers = append(matchers, fmt.Sprintf(`%s%s"%s"`, m[1], m[2], m[3]))
+	}
+
+	if len(matchers) == 0 {
+		return nil, fmt.Errorf("alert selector %s does not contain any matcher", selector)
+	}
+	return matchers, nil
+}
+
+// AlertNames returns the sorted unique alert names
+func AlertNames(alerts []string) []string {
+	set := make(map[string]bool, len(alerts))
+	var names []string
+	for _, alert := range alerts {
+		if !set[alert] {
+			set[alert] = true
+			names = append(names, alert)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PrometheusAlertsProvider queries the Prometheus ALERTS series for firing alerts
+type PrometheusAlertsProvider struct {
+	*PrometheusProvider
+}
+
+// NewPrometheusAlertsProvider takes a provider spec and the credentials map
+// and returns a Prometheus client ready to query the ALERTS series
+func NewPrometheusAlertsProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*PrometheusAlertsProvider, error) {
+	prom, err := NewPrometheusProvider(provider, credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &PrometheusAlertsProvider{PrometheusProvider: prom}, nil
+}
+
+// RunQuery returns the number of firing alerts matching the label selector
+func (p *PrometheusAlertsProvider) RunQuery(ctx context.Context, selector string) (float64, error) {
+	alerts, err := p.FiringAlerts(ctx, selector)
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(alerts)), nil
+}
+
+// FiringAlerts returns the name of each firing alert matching the label selector
+func (p *PrometheusAlertsProvider) FiringAlerts(ctx context.Context, selector string) ([]string, error) {
+	matchers, err := parseAlertSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`ALERTS{alertstate="firing",%s}`, strings.Join(matchers, ","))
+	result, err := p.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]string, 0, len(result.Data.Result))
+	for _, v := range result.Data.Result {
+		alerts = append(alerts, v.Metric["alertname"])
+	}
+	return alerts, nil
+}
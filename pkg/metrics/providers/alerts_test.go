@@ -0,0 +1,117 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestParseAlertSelector(t *testing.T) {
+	matchers, err := parseAlertSelector(`{namespace="test", severity=~"critical|page", summary!="a, \"b\""}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`namespace="test"`, `severity=~"critical|page"`, `summary!="a, \"b\""`}, matchers)
+
+	matchers, err = parseAlertSelector(`app="podinfo"`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`app="podinfo"`}, matchers)
+
+	_, err = parseAlertSelector(`{}`)
+	require.Error(t, err)
+
+	_, err = parseAlertSelector(`{app=podinfo}`)
+	require.Error(t, err)
+}
+
+func TestAlertNames(t *testing.T) {
+	assert.Equal(t, []string{"A", "B"}, AlertNames([]string{"B", "A", "B"}))
+}
+
+func TestAlertmanagerProvider_FiringAlerts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, alertmanagerAlertsPath, r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("active"))
+		assert.Equal(t, "false", r.URL.Query().Get("silenced"))
+		assert.Equal(t, []string{`namespace="test"`, `app="podinfo"`}, r.URL.Query()["filter"])
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		json := `[
+			{"labels":{"alertname":"HighErrorRate","app":"podinfo"},"status":{"state":"active"}},
+			{"labels":{"alertname":"HighLatency","app":"podinfo"},"status":{"state":"active"}},
+			{"labels":{"alertname":"Silenced","app":"podinfo"},"status":{"state":"suppressed"}}
+		]`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	am, err := NewAlertmanagerProvider(flaggerv1.MetricTemplateProvider{
+		Type:      "alertmanager",
+		Address:   ts.URL,
+		SecretRef: &corev1.LocalObjectReference{Name: "alertmanager"},
+	}, map[string][]byte{"token": []byte("token")})
+	require.NoError(t, err)
+
+	alerts, err := am.FiringAlerts(context.TODO(), `{namespace="test", app="podinfo"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HighErrorRate", "HighLatency"}, alerts)
+
+	val, err := am.RunQuery(context.TODO(), `{namespace="test", app="podinfo"}`)
+	require.NoError(t, err)
+	assert.Equal(t, float64(2), val)
+}
+
+func TestAlertmanagerProvider_IsOnline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, alertmanagerReadyPath, r.URL.Path)
+		w.Write([]byte("OK"))
+	}))
+	defer ts.Close()
+
+	am, err := NewAlertmanagerProvider(flaggerv1.MetricTemplateProvider{Type: "alertmanager", Address: ts.URL}, nil)
+	require.NoError(t, err)
+
+	ok, err := am.IsOnline(context.TODO())
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestPrometheusAlertsProvider_FiringAlerts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `ALERTS{alertstate="firing",namespace="test",app="podinfo"}`, r.URL.Query().Get("query"))
+
+		json := `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"ALERTS","alertname":"HighErrorRate","alertstate":"firing"},"value":[1545905245.458,"1"]}
+		]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	prom, err := NewPrometheusAlertsProvider(flaggerv1.MetricTemplateProvider{Type: "prometheusalerts", Address: ts.URL}, nil)
+	require.NoError(t, err)
+
+	alerts, err := prom.FiringAlerts(context.TODO(), `{namespace="test", app="podinfo"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"HighErrorRate"}, alerts)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -24,6 +25,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 
@@ -42,7 +44,7 @@ type CloudWatchProvider struct {
 
 // for the testing purpose
 type cloudWatchClient interface {
-	GetMetricData(input *cloudwatch.GetMetricDataInput) (*cloudwatch.GetMetricDataOutput, error)
+	GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput, opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error)
 }
 
 // NewCloudWatchProvider takes a metricInterval, a provider spec and the credentials map, and
@@ -72,7 +74,7 @@ func NewCloudWatchProvider(metricInterval string, provider flaggerv1.MetricTempl
 
 // RunQuery executes the aws cloud watch metrics query against GetMetricData endpoint
 // and returns the the first result as float64
-func (p *CloudWatchProvider) RunQuery(query string) (float64, error) {
+func (p *CloudWatchProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 	var cq []*cloudwatch.MetricDataQuery
 	if err := json.Unmarshal([]byte(query), &cq); err != nil {
 		return 0, fmt.Errorf("error unmarshaling query: %s", err.Error())
@@ -80,7 +82,7 @@ func (p *CloudWatchProvider) RunQuery(query string) (float64, error) {
 
 	end := time.Now()
 	start := end.Add(-p.startDelta)
-	res, err := p.client.GetMetricData(&cloudwatch.GetMetricDataInput{
+	res, err := p.client.GetMetricDataWithContext(ctx, &cloudwatch.GetMetricDataInput{
 		EndTime:           aws.Time(end),
 		MaxDatapoints:     aws.Int64(20),
 		StartTime:         aws.Time(start),
@@ -88,6 +90,9 @@ func (p *CloudWatchProvider) RunQuery(query string) (float64, error) {
 	})
 
 	if err != nil {
+		if ae, ok := err.(awserr.RequestFailure); ok {
+			return 0, statusError(ae.StatusCode(), fmt.Errorf("error requesting cloudwatch: %s", err.Error()))
+		}
 		return 0, fmt.Errorf("error requesting cloudwatch: %s", err.Error())
 	}
 
@@ -108,8 +113,8 @@ func (p *CloudWatchProvider) RunQuery(query string) (float64, error) {
 // and returns an error if the returned status code is NOT http.StatusBadRequests.
 // For example, if the flagger does not have permission to perform `cloudwatch:GetMetricData`,
 // the returned status code would be http.StatusForbidden
-func (p *CloudWatchProvider) IsOnline() (bool, error) {
-	_, err := p.client.GetMetricData(&cloudwatch.GetMetricDataInput{
+func (p *CloudWatchProvider) IsOnline(ctx context.Context) (bool, error) {
+	_, err := p.client.GetMetricDataWithContext(ctx, &cloudwatch.GetMetricDataInput{
 		EndTime:           aws.Time(time.Time{}),
 		MetricDataQueries: []*cloudwatch.MetricDataQuery{},
 		StartTime:         aws.Time(time.Time{}),
@@ -17,6 +17,7 @@ limitations under the License.
 package providers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"testing"
@@ -24,6 +25,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/costandusagereportservice"
 	"github.com/stretchr/testify/assert"
@@ -37,7 +39,7 @@ type cloudWatchClientMock struct {
 	err error
 }
 
-func (c cloudWatchClientMock) GetMetricData(_ *cloudwatch.GetMetricDataInput) (*cloudwatch.GetMetricDataOutput, error) {
+func (c cloudWatchClientMock) GetMetricDataWithContext(_ aws.Context, _ *cloudwatch.GetMetricDataInput, _ ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
 	return c.o, c.err
 }
 
@@ -66,7 +68,7 @@ func TestCloudWatchProvider_IsOnline(t *testing.T) {
 			err: awserr.NewRequestFailure(nil, http.StatusForbidden, "request-id"),
 		}}
 
-		actual, err := p.IsOnline()
+		actual, err := p.IsOnline(context.TODO())
 		assert.Error(t, err)
 		assert.False(t, actual)
 	})
@@ -74,7 +76,7 @@ func TestCloudWatchProvider_IsOnline(t *testing.T) {
 	t.Run("ok", func(t *testing.T) {
 		// no error
 		p := CloudWatchProvider{client: cloudWatchClientMock{}}
-		actual, err := p.IsOnline()
+		actual, err := p.IsOnline(context.TODO())
 		assert.NoError(t, err)
 		assert.True(t, actual)
 
@@ -146,7 +148,7 @@ func TestCloudWatchProvider_RunQuery(t *testing.T) {
 			},
 		}}
 
-		actual, err := p.RunQuery(query)
+		actual, err := p.RunQuery(context.TODO(), query)
 		assert.NoError(t, err)
 		assert.Equal(t, exp, actual)
 	})
@@ -160,14 +162,14 @@ func TestCloudWatchProvider_RunQuery(t *testing.T) {
 			},
 		}}
 
-		_, err := p.RunQuery(query)
+		_, err := p.RunQuery(context.TODO(), query)
 		require.Error(t, err)
 		require.True(t, errors.Is(err, ErrNoValuesFound))
 
 		p = CloudWatchProvider{client: cloudWatchClientMock{
 			o: &cloudwatch.GetMetricDataOutput{}}}
 
-		_, err = p.RunQuery(query)
+		_, err = p.RunQuery(context.TODO(), query)
 		require.Error(t, err)
 		require.True(t, errors.Is(err, ErrNoValuesFound))
 	})
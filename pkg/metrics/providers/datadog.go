@@ -101,7 +101,7 @@ func NewDatadogProvider(metricInterval string,
 
 // RunQuery executes the datadog query against DatadogProvider.metricsQueryEndpoint
 // and returns the the first result as float64
-func (p *DatadogProvider) RunQuery(query string) (float64, error) {
+func (p *DatadogProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 
 	req, err := http.NewRequest("GET", p.metricsQueryEndpoint, nil)
 	if err != nil {
@@ -117,7 +117,7 @@ func (p *DatadogProvider) RunQuery(query string) (float64, error) {
 	q.Add("to", strconv.FormatInt(now, 10))
 	req.URL.RawQuery = q.Encode()
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(ctx, p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
@@ -131,7 +131,7 @@ func (p *DatadogProvider) RunQuery(query string) (float64, error) {
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("error response: %s: %s", string(b), r.Status)
+		return 0, statusError(r.StatusCode, fmt.Errorf("error response: %s: %s", string(b), r.Status))
 	}
 
 	var res datadogResponse
@@ -162,7 +162,7 @@ func (p *DatadogProvider) RunQuery(query string) (float64, error) {
 
 // IsOnline calls the Datadog's validation endpoint with api keys
 // and returns an error if the validation fails
-func (p *DatadogProvider) IsOnline() (bool, error) {
+func (p *DatadogProvider) IsOnline(ctx context.Context) (bool, error) {
 	req, err := http.NewRequest("GET", p.apiKeyValidationEndpoint, nil)
 	if err != nil {
 		return false, fmt.Errorf("error http.NewRequest: %w", err)
@@ -171,7 +171,7 @@ func (p *DatadogProvider) IsOnline() (bool, error) {
 	req.Header.Add(datadogAPIKeyHeaderKey, p.apiKey)
 	req.Header.Add(datadogApplicationKeyHeaderKey, p.applicationKey)
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(ctx, p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
@@ -186,7 +186,7 @@ func (p *DatadogProvider) IsOnline() (bool, error) {
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("error response: %s", string(b))
+		return false, statusError(r.StatusCode, fmt.Errorf("error response: %s", string(b)))
 	}
 
 	return true, nil
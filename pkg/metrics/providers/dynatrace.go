@@ -93,7 +93,7 @@ func NewDynatraceProvider(metricInterval string,
 
 // RunQuery executes the dynatrace query against DynatraceProvider.metricsQueryEndpoint
 // and returns the the first result as float64
-func (p *DynatraceProvider) RunQuery(query string) (float64, error) {
+func (p *DynatraceProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 
 	req, err := http.NewRequest("GET", p.metricsQueryEndpoint, nil)
 	if err != nil {
@@ -110,7 +110,7 @@ func (p *DynatraceProvider) RunQuery(query string) (float64, error) {
 	q.Add("to", strconv.FormatInt(now, 10))
 	req.URL.RawQuery = q.Encode()
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(ctx, p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
@@ -124,7 +124,7 @@ func (p *DynatraceProvider) RunQuery(query string) (float64, error) {
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("error response: %s: %w", string(b), err)
+		return 0, statusError(r.StatusCode, fmt.Errorf("error response: %s: %w", string(b), err))
 	}
 
 	var res dynatraceResponse
@@ -151,7 +151,7 @@ func (p *DynatraceProvider) RunQuery(query string) (float64, error) {
 
 // IsOnline calls the Dynatrace's metrics endpoint with token
 // and returns an error if the endpoint fails
-func (p *DynatraceProvider) IsOnline() (bool, error) {
+func (p *DynatraceProvider) IsOnline(ctx context.Context) (bool, error) {
 	req, err := http.NewRequest("GET", p.apiValidationEndpoint, nil)
 	if err != nil {
 		return false, fmt.Errorf("error http.NewRequest: %w", err)
@@ -159,7 +159,7 @@ func (p *DynatraceProvider) IsOnline() (bool, error) {
 
 	req.Header.Set(dynatraceAuthorizationHeaderKey, fmt.Sprintf("%s %s", dynatraceAuthorizationHeaderType, p.token))
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(ctx, p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
@@ -174,7 +174,7 @@ func (p *DynatraceProvider) IsOnline() (bool, error) {
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("error response: %s", string(b))
+		return false, statusError(r.StatusCode, fmt.Errorf("error response: %s", string(b)))
 	}
 
 	return true, nil
@@ -17,6 +17,7 @@ limitations under the License.
 package providers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -111,7 +112,7 @@ func TestDynatraceProvider_RunQuery(t *testing.T) {
 		)
 		require.NoError(t, err)
 
-		f, err := dp.RunQuery(eq)
+		f, err := dp.RunQuery(context.TODO(), eq)
 		require.NoError(t, err)
 		assert.Equal(t, expected, f)
 	})
@@ -130,7 +131,7 @@ func TestDynatraceProvider_RunQuery(t *testing.T) {
 			},
 		)
 		require.NoError(t, err)
-		_, err = dp.RunQuery("")
+		_, err = dp.RunQuery(context.TODO(), "")
 		require.True(t, errors.Is(err, ErrNoValuesFound))
 	})
 }
@@ -159,7 +160,7 @@ func TestDynatraceProvider_IsOnline(t *testing.T) {
 			)
 			require.NoError(t, err)
 
-			_, err = dp.IsOnline()
+			_, err = dp.IsOnline(context.TODO())
 			if c.errExpected {
 				require.Error(t, err)
 			} else {
@@ -0,0 +1,249 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	elasticsearchSearchPath = "/_search"
+	elasticsearchCountPath  = "/_count"
+
+	elasticsearchAPIKeySecretKey   = "apiKey"
+	elasticsearchUsernameSecretKey = "username"
+	elasticsearchPasswordSecretKey = "password"
+)
+
+// ElasticsearchProvider executes search queries against Elasticsearch or OpenSearch,
+// the index pattern is taken from the address path
+type ElasticsearchProvider struct {
+	timeout    time.Duration
+	url        url.URL
+	headers    http.Header
+	resultPath []string
+	username   string
+	password   string
+	apiKey     string
+	client     *http.Client
+}
+
+// NewElasticsearchProvider takes a provider spec and the credentials map,
+// validates the address, extracts the API key or username and password values
+// and returns a client ready to execute search requests against the API
+func NewElasticsearchProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*ElasticsearchProvider, error) {
+	esURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	es := ElasticsearchProvider{
+		timeout:    5 * time.Second,
+		url:        *esURL,
+		headers:    provider.Headers,
+		resultPath: splitResultPath(provider.ResultPath),
+		client:     http.DefaultClient,
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		es.client = &http.Client{Transport: t}
+	}
+
+	if provider.SecretRef != nil {
+		if apiKey, ok := credentials[elasticsearchAPIKeySecretKey]; ok {
+			es.apiKey = string(apiKey)
+		} else if username, ok := credentials[elasticsearchUsernameSecretKey]; ok {
+			es.username = string(username)
+			if password, ok := credentials[elasticsearchPasswordSecretKey]; ok {
+				es.password = string(password)
+			} else {
+				return nil, fmt.Errorf("%s credentials does not contain a password", provider.Type)
+			}
+		} else {
+			return nil, fmt.Errorf("%s credentials does not contain an apiKey or username", provider.Type)
+		}
+	}
+
+	return &es, nil
+}
+
+// RunQuery posts the JSON search body and returns the value found at the result path,
+// when no result path is set the total hit count is returned
+func (p *ElasticsearchProvider) RunQuery(ctx context.Context, query string) (float64, error) {
+	if !json.Valid([]byte(query)) {
+		return 0, fmt.Errorf("query is not a valid JSON search body: %s", query)
+	}
+
+	b, err := p.do(ctx, "POST", elasticsearchSearchPath, []byte(query))
+	if err != nil {
+		return 0, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	resultPath := p.resultPath
+	if len(resultPath) == 0 {
+		resultPath = []string{"hits", "total"}
+	}
+
+	var value interface{} = result
+	for _, key := range resultPath {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return 0, fmt.Errorf("result path %s index %s is not valid", strings.Join(resultPath, "."), key)
+			}
+			value = v[i]
+		default:
+			value = nil
+		}
+		if value == nil {
+			return 0, fmt.Errorf("result path %s not found: %w", strings.Join(resultPath, "."), ErrNoValuesFound)
+		}
+	}
+
+	// hits.total is an object since Elasticsearch 7 and OpenSearch
+	if m, ok := value.(map[string]interface{}); ok {
+		if v, ok := m["value"]; ok {
+			value = v
+		}
+	}
+
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case string:
+		f, err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("error parsing value %s: %w", v, err)
+		}
+	case nil:
+		return 0, fmt.Errorf("%w", ErrNoValuesFound)
+	default:
+		return 0, fmt.Errorf("result path %s value %v is not a number", strings.Join(resultPath, "."), v)
+	}
+
+	if math.IsNaN(f) {
+		return 0, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return f, nil
+}
+
+// IsOnline runs a count request against the index and returns an error if the API is unreachable
+func (p *ElasticsearchProvider) IsOnline(ctx context.Context) (bool, error) {
+	if _, err := p.do(ctx, "GET", elasticsearchCountPath, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *ElasticsearchProvider) do(ctx context.Context, method string, apiPath string, body []byte) ([]byte, error) {
+	u, err := url.Parse("." + apiPath)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse failed: %w", err)
+	}
+	u.Path = path.Join(p.url.Path, u.Path)
+	u = p.url.ResolveReference(u)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+
+	if p.headers != nil {
+		req.Header = p.headers.Clone()
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+p.apiKey)
+	} else if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	ctx, cancel := withTimeout(ctx, p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, statusError(r.StatusCode, fmt.Errorf("error response: Status %d %s: %s", r.StatusCode, http.StatusText(r.StatusCode), string(b)))
+	}
+
+	return b, nil
+}
+
+// splitResultPath splits a dot separated path, dots can be escaped with a
+// backslash to address keys such as percentiles e.g. aggregations.p.values.99\.0
+func splitResultPath(resultPath string) []string {
+	if resultPath == "" {
+		return nil
+	}
+
+	var keys []string
+	var key strings.Builder
+	for i := 0; i < len(resultPath); i++ {
+		c := resultPath[i]
+		switch {
+		case c == '\\' && i+1 < len(resultPath) && resultPath[i+1] == '.':
+			key.WriteByte('.')
+			i++
+		case c == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(c)
+		}
+	}
+	return append(keys, key.String())
+}
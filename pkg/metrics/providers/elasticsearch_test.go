@@ -0,0 +1,185 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewElasticsearchProvider(t *testing.T) {
+	provider := flaggerv1.MetricTemplateProvider{
+		Type:       "opensearch",
+		Address:    "https://opensearch:9200/logs-*",
+		SecretRef:  &corev1.LocalObjectReference{Name: "opensearch"},
+		ResultPath: `aggregations.latency.values.99\.0`,
+	}
+
+	es, err := NewElasticsearchProvider(provider, map[string][]byte{
+		elasticsearchUsernameSecretKey: []byte("user"),
+		elasticsearchPasswordSecretKey: []byte("pass"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "pass", es.password)
+	assert.Equal(t, []string{"aggregations", "latency", "values", "99.0"}, es.resultPath)
+
+	_, err = NewElasticsearchProvider(provider, map[string][]byte{
+		elasticsearchUsernameSecretKey: []byte("user"),
+	})
+	require.Error(t, err)
+
+	_, err = NewElasticsearchProvider(provider, map[string][]byte{})
+	require.Error(t, err)
+}
+
+func TestElasticsearchProvider_RunQuery(t *testing.T) {
+	query := `{"size":0,"query":{"match":{"level":"error"}}}`
+
+	t.Run("hit count", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/logs-*/_search", r.URL.Path)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, "ApiKey key", r.Header.Get("Authorization"))
+			assert.Equal(t, "flagger", r.Header.Get("X-Opaque-Id"))
+			b, _ := io.ReadAll(r.Body)
+			assert.Equal(t, query, string(b))
+
+			json := `{"took":3,"hits":{"total":{"value":42,"relation":"eq"},"hits":[]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		es, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:      "elasticsearch",
+			Address:   ts.URL + "/logs-*",
+			Headers:   http.Header{"X-Opaque-Id": []string{"flagger"}},
+			SecretRef: &corev1.LocalObjectReference{Name: "elasticsearch"},
+		}, map[string][]byte{
+			elasticsearchAPIKeySecretKey: []byte("key"),
+		})
+		require.NoError(t, err)
+
+		val, err := es.RunQuery(context.TODO(), query)
+		require.NoError(t, err)
+		assert.Equal(t, float64(42), val)
+	})
+
+	t.Run("aggregation", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "user", username)
+			assert.Equal(t, "pass", password)
+
+			json := `{"hits":{"total":{"value":100}},"aggregations":{"latency":{"values":{"99.0":512.5}}}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		es, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:       "opensearch",
+			Address:    ts.URL,
+			SecretRef:  &corev1.LocalObjectReference{Name: "opensearch"},
+			ResultPath: `aggregations.latency.values.99\.0`,
+		}, map[string][]byte{
+			elasticsearchUsernameSecretKey: []byte("user"),
+			elasticsearchPasswordSecretKey: []byte("pass"),
+		})
+		require.NoError(t, err)
+
+		val, err := es.RunQuery(context.TODO(), query)
+		require.NoError(t, err)
+		assert.Equal(t, 512.5, val)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json := `{"hits":{"total":{"value":0}},"aggregations":{"latency":{"value":null}}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		es, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:       "elasticsearch",
+			Address:    ts.URL,
+			ResultPath: "aggregations.latency.value",
+		}, nil)
+		require.NoError(t, err)
+
+		_, err = es.RunQuery(context.TODO(), query)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		es, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "elasticsearch",
+			Address: "http://elasticsearch:9200",
+		}, nil)
+		require.NoError(t, err)
+
+		_, err = es.RunQuery(context.TODO(), `{"query":`)
+		require.Error(t, err)
+	})
+}
+
+func TestElasticsearchProvider_IsOnline(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/logs-*/_count", r.URL.Path)
+			w.Write([]byte(`{"count":10}`))
+		}))
+		defer ts.Close()
+
+		es, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "elasticsearch",
+			Address: ts.URL + "/logs-*",
+		}, nil)
+		require.NoError(t, err)
+
+		ok, err := es.IsOnline(context.TODO())
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+
+		es, err := NewElasticsearchProvider(flaggerv1.MetricTemplateProvider{
+			Type:    "elasticsearch",
+			Address: ts.URL,
+		}, nil)
+		require.NoError(t, err)
+
+		ok, err := es.IsOnline(context.TODO())
+		require.Error(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -16,9 +16,64 @@ limitations under the License.
 
 package providers
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
 
 var (
 	ErrNoValuesFound          = errors.New("no values found")
 	ErrMultipleValuesReturned = errors.New("query returned multiple values")
 )
+
+// TransientError wraps the errors caused by a temporary provider failure,
+// such as a network timeout or a gateway error, the query can be retried
+type TransientError struct {
+	Err error
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// newTransientError marks the error as transient
+func newTransientError(err error) error {
+	return &TransientError{Err: err}
+}
+
+// statusError marks the error of a failed HTTP response as transient
+// when the status code indicates rate limiting or an unavailable upstream
+func statusError(statusCode int, err error) error {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return newTransientError(err)
+	}
+	return err
+}
+
+// IsTransient returns true if the error is caused by a temporary failure
+// and the query may succeed if retried
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var te *TransientError
+	if errors.As(err, &te) {
+		return true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
@@ -17,12 +17,14 @@ limitations under the License.
 package providers
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"strings"
 	"time"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
 	externalmetrics_client "k8s.io/metrics/pkg/client/external_metrics"
@@ -74,7 +76,7 @@ func NewExternalMetricsProvider(
 // RunQuery retrieves the ExternalMetricValue from the External Metrics API
 // at the ExternalMetricsProvider's address, using the provided query string,
 // and returns the *first* result as a float64.
-func (p *ExternalMetricsProvider) RunQuery(query string) (float64, error) {
+func (p *ExternalMetricsProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 	namespace, metricName, selector, err := parseExternalMetricsQuery(query)
 	if err != nil {
 		return 0, fmt.Errorf("error parsing metric query: %w", err)
@@ -83,7 +85,12 @@ func (p *ExternalMetricsProvider) RunQuery(query string) (float64, error) {
 	nm := p.client.NamespacedMetrics(namespace)
 	metricsList, err := nm.List(metricName, selector)
 	if err != nil {
-		return 0, fmt.Errorf("error querying external metrics API: %w", err)
+		err = fmt.Errorf("error querying external metrics API: %w", err)
+		if apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+			apierrors.IsTooManyRequests(err) || apierrors.IsServiceUnavailable(err) {
+			return 0, newTransientError(err)
+		}
+		return 0, err
 	}
 
 	if len(metricsList.Items) < 1 {
@@ -97,7 +104,7 @@ func (p *ExternalMetricsProvider) RunQuery(query string) (float64, error) {
 
 // IsOnline tests that the External Metrics API is reachable by looking for dummy metrics.
 // If we don't get a network error, we assume the service is online.
-func (p *ExternalMetricsProvider) IsOnline() (bool, error) {
+func (p *ExternalMetricsProvider) IsOnline(ctx context.Context) (bool, error) {
 	nm := p.client.NamespacedMetrics("kube-system")
 	_, err := nm.List("dummy-metric", labels.Everything())
 
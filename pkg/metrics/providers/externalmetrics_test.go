@@ -17,6 +17,7 @@ limitations under the License.
 package providers
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -263,7 +264,7 @@ func TestExternalMetrics_RunQuery(t *testing.T) {
 				client: emclient,
 			}
 
-			got, err := emp.RunQuery(tt.query)
+			got, err := emp.RunQuery(context.TODO(), tt.query)
 			if tt.wantErr {
 				require.Error(t, err)
 				assert.Zero(t, got)
@@ -281,7 +282,7 @@ func TestExternalMetrics_IsOnline(t *testing.T) {
 		client: &fakeemc.FakeExternalMetricsClient{},
 	}
 
-	online, err := emp.IsOnline()
+	online, err := emp.IsOnline(context.TODO())
 	require.NoError(t, err)
 	assert.True(t, online)
 }
@@ -32,7 +32,23 @@ func NewFactory(logger *zap.SugaredLogger) *Factory {
 	}
 }
 
+// Provider returns the metrics provider client for the template provider spec,
+// the client applies the template timeout and retries the transient errors
 func (factory Factory) Provider(metricInterval string, provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte, config *rest.Config) (Interface, error) {
+	policy, err := newRetryPolicy(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := factory.provider(metricInterval, provider, credentials, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy.wrap(client), nil
+}
+
+func (factory Factory) provider(metricInterval string, provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte, config *rest.Config) (Interface, error) {
 	switch provider.Type {
 	case "prometheus":
 		return NewPrometheusProvider(provider, credentials)
@@ -56,6 +72,14 @@ func (factory Factory) Provider(metricInterval string, provider flaggerv1.Metric
 		return NewKeptnProvider(config)
 	case "splunk":
 		return NewSplunkProvider(metricInterval, provider, credentials)
+	case "loki":
+		return NewLokiProvider(provider, credentials)
+	case "elasticsearch", "opensearch":
+		return NewElasticsearchProvider(provider, credentials)
+	case "alertmanager":
+		return NewAlertmanagerProvider(provider, credentials)
+	case "prometheusalerts":
+		return NewPrometheusAlertsProvider(provider, credentials)
 	default:
 		factory.logger.Warnf("unknown metrics provider '%s', using prometheus", provider.Type)
 		return NewPrometheusProvider(provider, credentials)
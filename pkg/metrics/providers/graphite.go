@@ -151,7 +151,7 @@ func NewGraphiteProvider(provider flaggerv1.MetricTemplateProvider, credentials
 
 // RunQuery executes the Graphite render URL API query and returns the
 // the first result as float64.
-func (g *GraphiteProvider) RunQuery(query string) (float64, error) {
+func (g *GraphiteProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 	query = g.trimQuery(query)
 	u, err := url.Parse(fmt.Sprintf("./render?%s", query))
 	if err != nil {
@@ -174,7 +174,7 @@ func (g *GraphiteProvider) RunQuery(query string) (float64, error) {
 		req.SetBasicAuth(g.username, g.password)
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), g.timeout)
+	ctx, cancel := withTimeout(ctx, g.timeout)
 	defer cancel()
 
 	r, err := g.client.Do(req.WithContext(ctx))
@@ -189,7 +189,7 @@ func (g *GraphiteProvider) RunQuery(query string) (float64, error) {
 	}
 
 	if 400 <= r.StatusCode {
-		return 0, fmt.Errorf("error response: %s", string(b))
+		return 0, statusError(r.StatusCode, fmt.Errorf("error response: %s", string(b)))
 	}
 
 	var result graphiteResponse
@@ -215,8 +215,8 @@ func (g *GraphiteProvider) RunQuery(query string) (float64, error) {
 
 // IsOnline runs a simple Graphite render URL API query and returns
 // an error if the API is unreachable.
-func (g *GraphiteProvider) IsOnline() (bool, error) {
-	_, err := g.RunQuery("target=test")
+func (g *GraphiteProvider) IsOnline(ctx context.Context) (bool, error) {
+	_, err := g.RunQuery(ctx, "target=test")
 	if err != nil && err != ErrNoValuesFound {
 		return false, fmt.Errorf("running query failed: %w", err)
 	}
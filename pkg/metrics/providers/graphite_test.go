@@ -244,7 +244,7 @@ func TestGraphiteProvider_RunQuery(t *testing.T) {
 			graphite, err := NewGraphiteProvider(template.Spec.Provider, secret.Data)
 			require.NoError(t, err)
 
-			val, err := graphite.RunQuery(template.Spec.Query)
+			val, err := graphite.RunQuery(context.TODO(), template.Spec.Query)
 			require.NoError(t, err)
 
 			if test.errExpected {
@@ -309,7 +309,7 @@ func TestGraphiteProvider_IsOnline(t *testing.T) {
 			}, map[string][]byte{})
 			require.NoError(t, err)
 
-			res, err := graph.IsOnline()
+			res, err := graph.IsOnline(context.TODO())
 			assert.Equal(t, res, test.expectedResult)
 
 			if test.errExpected {
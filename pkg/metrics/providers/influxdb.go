@@ -62,9 +62,9 @@ func NewInfluxdbProvider(provider flaggerv1.MetricTemplateProvider,
 	return &influxProvider, nil
 }
 
-func (i *InfluxdbProvider) RunQuery(query string) (float64, error) {
+func (i *InfluxdbProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 	queryAPI := i.client.QueryAPI(i.org)
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := withTimeout(ctx, 15*time.Second)
 	defer cancel()
 	result, err := queryAPI.Query(ctx, query)
 	if err != nil {
@@ -89,9 +89,9 @@ func (i *InfluxdbProvider) RunQuery(query string) (float64, error) {
 }
 
 // IsOnline runs a simple query against the default bucket.
-func (i *InfluxdbProvider) IsOnline() (bool, error) {
+func (i *InfluxdbProvider) IsOnline(ctx context.Context) (bool, error) {
 	queryAPI := i.client.QueryAPI(i.org)
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx, cancel := withTimeout(ctx, 15*time.Second)
 	defer cancel()
 	result, err := queryAPI.Query(ctx, `from(bucket: "default") |> range(start: -2h)`)
 	if err != nil {
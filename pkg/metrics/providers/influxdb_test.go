@@ -17,6 +17,7 @@ limitations under the License.
 package providers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -82,7 +83,7 @@ func TestInfluxdbProvider_IsOnline(t *testing.T) {
 			client: client,
 			org:    "fake-org",
 		}
-		isOnline, err := provider.IsOnline()
+		isOnline, err := provider.IsOnline(context.TODO())
 		assert.NoError(t, err)
 		assert.True(t, isOnline)
 	})
@@ -98,7 +99,7 @@ func TestInfluxdbProvider_IsOnline(t *testing.T) {
 			client: client,
 			org:    "fake-org",
 		}
-		isOnline, err := provider.IsOnline()
+		isOnline, err := provider.IsOnline(context.TODO())
 		assert.Error(t, err)
 		assert.False(t, isOnline)
 	})
@@ -124,7 +125,7 @@ func TestInfluxdbProvider_RunQuery(t *testing.T) {
 		client: client,
 		org:    "fake-org",
 	}
-	float, err := provider.RunQuery(`from(bucket: "default")  |> range(start: -2h)`)
+	float, err := provider.RunQuery(context.TODO(), `from(bucket: "default")  |> range(start: -2h)`)
 
 	assert.NoError(t, err)
 	assert.Equal(t, float, 1.4)
@@ -69,7 +69,7 @@ func NewKeptnProvider(cfg *rest.Config) (*KeptnProvider, error) {
 // based on the selector provided in the query.
 // The format of the selector is the following:
 // <keptnmetric|analysis>/<namespace>/<resourceName>/<duration>/<arguments>
-func (k *KeptnProvider) RunQuery(query string) (float64, error) {
+func (k *KeptnProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 	queryObj, err := parseQuery(query)
 	if err != nil {
 		return 0, err
@@ -77,25 +77,25 @@ func (k *KeptnProvider) RunQuery(query string) (float64, error) {
 
 	switch queryObj.GroupVersionResource.Resource {
 	case keptnMetricsResourceName:
-		return k.queryKeptnMetric(queryObj)
+		return k.queryKeptnMetric(ctx, queryObj)
 	case analysisResourceName:
-		return k.queryKeptnAnalysis(queryObj)
+		return k.queryKeptnAnalysis(ctx, queryObj)
 	default:
 		return 0, errors.New("unsupported query")
 	}
 
 }
 
-func (k *KeptnProvider) IsOnline() (bool, error) {
+func (k *KeptnProvider) IsOnline(ctx context.Context) (bool, error) {
 	// TODO should we check for the keptn deployment to be up and running in the cluster?
 	return true, nil
 }
 
-func (k *KeptnProvider) queryKeptnMetric(queryObj *queryObject) (float64, error) {
+func (k *KeptnProvider) queryKeptnMetric(ctx context.Context, queryObj *queryObject) (float64, error) {
 	get, err := k.client.Resource(queryObj.GroupVersionResource).
 		Namespace(queryObj.Namespace).
 		Get(
-			context.Background(),
+			ctx,
 			queryObj.ResourceName,
 			v1.GetOptions{},
 		)
@@ -118,7 +118,7 @@ func (k *KeptnProvider) queryKeptnMetric(queryObj *queryObject) (float64, error)
 	return 0, fmt.Errorf("could not retrieve KeptnMetric - no value found in resource %s/%s", queryObj.Namespace, queryObj.ResourceName)
 }
 
-func (k *KeptnProvider) queryKeptnAnalysis(obj *queryObject) (float64, error) {
+func (k *KeptnProvider) queryKeptnAnalysis(ctx context.Context, obj *queryObject) (float64, error) {
 	analysis := &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": fmt.Sprintf("metrics.keptn.sh/%s", apiVersion),
@@ -141,7 +141,7 @@ func (k *KeptnProvider) queryKeptnAnalysis(obj *queryObject) (float64, error) {
 
 	// set the timeout to 10s - this will give Keptn enough time to reconcile the Analysis
 	// and store the result in the status of the resource created here.
-	ctx, cancel := context.WithTimeout(context.Background(), k.analysisTimeout)
+	ctx, cancel := withTimeout(ctx, k.analysisTimeout)
 	defer cancel()
 
 	createdAnalysis, err := k.client.
@@ -175,7 +175,7 @@ func (k *KeptnProvider) queryKeptnAnalysis(obj *queryObject) (float64, error) {
 		// by then, we return an error.
 		select {
 		case <-ctx.Done():
-			return 0, fmt.Errorf("encountered timeout while waiting for Keptn Analysis %s/%s to be finished", obj.Namespace, obj.ResourceName)
+			return 0, newTransientError(fmt.Errorf("encountered timeout while waiting for Keptn Analysis %s/%s to be finished", obj.Namespace, obj.ResourceName))
 		case <-time.After(time.Second):
 			get, err := k.client.Resource(obj.GroupVersionResource).Namespace(obj.Namespace).Get(ctx, createdAnalysis.GetName(), v1.GetOptions{})
 			if err != nil {
@@ -23,7 +23,7 @@ func TestNewKeptnProvider(t *testing.T) {
 	require.Nil(t, err)
 	require.NotNil(t, provider)
 
-	isOnline, err := provider.IsOnline()
+	isOnline, err := provider.IsOnline(context.TODO())
 	require.NoError(t, err)
 	require.True(t, isOnline)
 }
@@ -132,7 +132,7 @@ func TestKeptnProvider_RunQuery_KeptnMetric(t *testing.T) {
 			k := &KeptnProvider{
 				client: tt.setupClient(),
 			}
-			got, err := k.RunQuery(tt.query)
+			got, err := k.RunQuery(context.TODO(), tt.query)
 			if tt.wantErr {
 				require.NotNil(t, err)
 			} else {
@@ -331,7 +331,7 @@ func TestKeptnProvider_RunQueryAnalysis(t *testing.T) {
 				return tt.verificationFunc(fakeClient)
 			})
 
-			got, err := k.RunQuery(tt.query)
+			got, err := k.RunQuery(context.TODO(), tt.query)
 			if tt.wantErr {
 				require.NotNil(t, err)
 			} else {
@@ -0,0 +1,278 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const (
+	lokiQueryPath    = "/loki/api/v1/query"
+	lokiReadyPath    = "/ready"
+	lokiTenantHeader = "X-Scope-OrgID"
+
+	lokiTokenSecretKey    = "token"
+	lokiUsernameSecretKey = "username"
+	lokiPasswordSecretKey = "password"
+	lokiTenantSecretKey   = "tenant"
+)
+
+// LokiProvider executes LogQL metric queries
+type LokiProvider struct {
+	timeout  time.Duration
+	url      url.URL
+	headers  http.Header
+	username string
+	password string
+	token    string
+	tenant   string
+	client   *http.Client
+}
+
+type lokiResponse struct {
+	Data struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type lokiVectorResult []struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+// NewLokiProvider takes a provider spec and the credentials map,
+// validates the address, extracts the bearer token or username and password values
+// and the tenant ID if provided and returns a Loki client ready to execute queries against the API
+func NewLokiProvider(provider flaggerv1.MetricTemplateProvider, credentials map[string][]byte) (*LokiProvider, error) {
+	lokiURL, err := url.Parse(provider.Address)
+	if provider.Address == "" || err != nil {
+		return nil, fmt.Errorf("%s address %s is not a valid URL", provider.Type, provider.Address)
+	}
+
+	loki := LokiProvider{
+		timeout: 5 * time.Second,
+		url:     *lokiURL,
+		headers: provider.Headers,
+		client:  http.DefaultClient,
+	}
+
+	if provider.InsecureSkipVerify {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		loki.client = &http.Client{Transport: t}
+	}
+
+	if provider.SecretRef != nil {
+		if tenant, ok := credentials[lokiTenantSecretKey]; ok {
+			loki.tenant = string(tenant)
+		}
+
+		if token, ok := credentials[lokiTokenSecretKey]; ok {
+			loki.token = string(token)
+		} else if username, ok := credentials[lokiUsernameSecretKey]; ok {
+			loki.username = string(username)
+			if password, ok := credentials[lokiPasswordSecretKey]; ok {
+				loki.password = string(password)
+			} else {
+				return nil, fmt.Errorf("%s credentials does not contain a password", provider.Type)
+			}
+		} else if loki.tenant == "" {
+			return nil, fmt.Errorf("%s credentials does not contain a token, username or tenant", provider.Type)
+		}
+	}
+
+	return &loki, nil
+}
+
+// RunQuery executes the LogQL metric query and returns the the first result as float64
+func (p *LokiProvider) RunQuery(ctx context.Context, query string) (float64, error) {
+	samples, err := p.query(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(samples) == 0 || math.IsNaN(samples[len(samples)-1].Value) {
+		return 0, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return samples[len(samples)-1].Value, nil
+}
+
+// RunVectorQuery executes the LogQL metric query and returns the value and labels of each series
+func (p *LokiProvider) RunVectorQuery(ctx context.Context, query string) ([]Sample, error) {
+	samples, err := p.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Sample
+	for _, s := range samples {
+		if !math.IsNaN(s.Value) {
+			result = append(result, s)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return result, nil
+}
+
+// query executes the LogQL metric query and returns the samples of the vector or scalar result
+func (p *LokiProvider) query(ctx context.Context, query string) ([]Sample, error) {
+	u, err := url.Parse("." + lokiQueryPath)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse failed: %w", err)
+	}
+	u.Path = path.Join(p.url.Path, u.Path)
+	u = p.url.ResolveReference(u)
+
+	q := u.Query()
+	q.Set("query", p.trimQuery(query))
+	u.RawQuery = q.Encode()
+
+	b, err := p.do(ctx, u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var result lokiResponse
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
+	}
+
+	var samples []Sample
+	switch result.Data.ResultType {
+	case "vector":
+		var vector lokiVectorResult
+		if err := json.Unmarshal(result.Data.Result, &vector); err != nil {
+			return nil, fmt.Errorf("error unmarshaling vector: %w", err)
+		}
+		for _, v := range vector {
+			if len(v.Value) != 2 {
+				continue
+			}
+			f, err := lokiParseValue(v.Value[1])
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, Sample{Labels: v.Metric, Value: f})
+		}
+	case "scalar":
+		var scalar []interface{}
+		if err := json.Unmarshal(result.Data.Result, &scalar); err != nil {
+			return nil, fmt.Errorf("error unmarshaling scalar: %w", err)
+		}
+		if len(scalar) == 2 {
+			f, err := lokiParseValue(scalar[1])
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, Sample{Value: f})
+		}
+	case "matrix":
+		return nil, fmt.Errorf("%w", ErrMultipleValuesReturned)
+	default:
+		return nil, fmt.Errorf("result type %s is not supported, use a LogQL metric query", result.Data.ResultType)
+	}
+
+	return samples, nil
+}
+
+// IsOnline calls the Loki readiness endpoint and returns an error if the API is unreachable
+func (p *LokiProvider) IsOnline(ctx context.Context) (bool, error) {
+	u, err := url.Parse("." + lokiReadyPath)
+	if err != nil {
+		return false, fmt.Errorf("url.Parse failed: %w", err)
+	}
+	u.Path = path.Join(p.url.Path, u.Path)
+	u = p.url.ResolveReference(u)
+
+	if _, err := p.do(ctx, u.String()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p *LokiProvider) do(ctx context.Context, address string) ([]byte, error) {
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
+	}
+
+	if p.headers != nil {
+		req.Header = p.headers.Clone()
+	}
+
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	} else if p.username != "" && p.password != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	if p.tenant != "" {
+		req.Header.Set(lokiTenantHeader, p.tenant)
+	}
+
+	ctx, cancel := withTimeout(ctx, p.timeout)
+	defer cancel()
+
+	r, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer r.Body.Close()
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	if 400 <= r.StatusCode {
+		return nil, statusError(r.StatusCode, fmt.Errorf("error response: Status %d %s: %s", r.StatusCode, http.StatusText(r.StatusCode), string(b)))
+	}
+
+	return b, nil
+}
+
+// trimQuery removes the leading and trailing whitespace of a LogQL query,
+// the whitespace inside the query is kept as it can be part of a string literal
+func (p *LokiProvider) trimQuery(query string) string {
+	return strings.TrimSpace(query)
+}
+
+func lokiParseValue(v interface{}) (float64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, fmt.Errorf("error unmarshaling value: %v", v)
+	}
+	return strconv.ParseFloat(s, 64)
+}
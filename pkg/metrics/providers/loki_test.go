@@ -0,0 +1,237 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestNewLokiProvider(t *testing.T) {
+	provider := flaggerv1.MetricTemplateProvider{
+		Type:      "loki",
+		Address:   "http://loki:3100",
+		SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+	}
+
+	loki, err := NewLokiProvider(provider, map[string][]byte{
+		lokiUsernameSecretKey: []byte("user"),
+		lokiPasswordSecretKey: []byte("pass"),
+		lokiTenantSecretKey:   []byte("team-a"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "http://loki:3100", loki.url.String())
+	assert.Equal(t, "pass", loki.password)
+	assert.Equal(t, "team-a", loki.tenant)
+
+	_, err = NewLokiProvider(provider, map[string][]byte{
+		lokiUsernameSecretKey: []byte("user"),
+	})
+	require.Error(t, err)
+
+	_, err = NewLokiProvider(provider, map[string][]byte{})
+	require.Error(t, err)
+
+	provider.Address = ""
+	_, err = NewLokiProvider(provider, nil)
+	require.Error(t, err)
+}
+
+func TestLokiProvider_RunQuery(t *testing.T) {
+	query := `sum(count_over_time({app="podinfo"} |= "panic" [5m]))`
+
+	t.Run("ok", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, lokiQueryPath, r.URL.Path)
+			assert.Equal(t, query, r.URL.Query().Get("query"))
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.Equal(t, "team-a", r.Header.Get(lokiTenantHeader))
+
+			json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"3"]}]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		loki, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{
+			Type:      "loki",
+			Address:   ts.URL,
+			SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+		}, map[string][]byte{
+			lokiTokenSecretKey:  []byte("token"),
+			lokiTenantSecretKey: []byte("team-a"),
+		})
+		require.NoError(t, err)
+
+		val, err := loki.RunQuery(context.TODO(), query)
+		require.NoError(t, err)
+		assert.Equal(t, float64(3), val)
+	})
+
+	t.Run("multiline", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "sum(count_over_time(\n  {app=\"podinfo\"} |= \"level:  error\"\n  [5m]))",
+				r.URL.Query().Get("query"))
+
+			json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"1"]}]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		loki, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		val, err := loki.RunQuery(context.TODO(), "\n  sum(count_over_time(\n  {app=\"podinfo\"} |= \"level:  error\"\n  [5m]))\n")
+		require.NoError(t, err)
+		assert.Equal(t, float64(1), val)
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json := `{"status":"success","data":{"resultType":"scalar","result":[1545905245.458,"1.5"]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		loki, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		val, err := loki.RunQuery(context.TODO(), "vector(1.5)")
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, val)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json := `{"status":"success","data":{"resultType":"vector","result":[]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		loki, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = loki.RunQuery(context.TODO(), query)
+		require.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("log query", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json := `{"status":"success","data":{"resultType":"streams","result":[{"stream":{"app":"podinfo"},"values":[["1545905245458000000","panic"]]}]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		loki, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = loki.RunQuery(context.TODO(), `{app="podinfo"} |= "panic"`)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrNoValuesFound))
+	})
+
+	t.Run("basic auth", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "user", username)
+			assert.Equal(t, "pass", password)
+
+			json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"0"]}]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		loki, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{
+			Type:      "loki",
+			Address:   ts.URL,
+			SecretRef: &corev1.LocalObjectReference{Name: "loki"},
+		}, map[string][]byte{
+			lokiUsernameSecretKey: []byte("user"),
+			lokiPasswordSecretKey: []byte("pass"),
+		})
+		require.NoError(t, err)
+
+		val, err := loki.RunQuery(context.TODO(), query)
+		require.NoError(t, err)
+		assert.Equal(t, float64(0), val)
+	})
+}
+
+func TestLokiProvider_IsOnline(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, lokiReadyPath, r.URL.Path)
+			w.Write([]byte("ready"))
+		}))
+		defer ts.Close()
+
+		loki, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		ok, err := loki.IsOnline(context.TODO())
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("Ingester not ready"))
+		}))
+		defer ts.Close()
+
+		loki, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		ok, err := loki.IsOnline(context.TODO())
+		require.Error(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestLokiProvider_RunVectorQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json := `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"pod":"podinfo-1"},"value":[1545905245.458,"2"]},
+			{"metric":{"pod":"podinfo-2"},"value":[1545905245.458,"5"]}]}}`
+		w.Write([]byte(json))
+	}))
+	defer ts.Close()
+
+	loki, err := NewLokiProvider(flaggerv1.MetricTemplateProvider{Type: "loki", Address: ts.URL}, nil)
+	require.NoError(t, err)
+
+	samples, err := loki.RunVectorQuery(context.TODO(), `sum by (pod) (count_over_time({app="podinfo"} |= "panic" [5m]))`)
+	require.NoError(t, err)
+	assert.Equal(t, []Sample{
+		{Labels: map[string]string{"pod": "podinfo-1"}, Value: 2},
+		{Labels: map[string]string{"pod": "podinfo-2"}, Value: 5},
+	}, samples)
+
+	val, err := loki.RunQuery(context.TODO(), `sum(count_over_time({app="podinfo"} |= "panic" [5m]))`)
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), val)
+}
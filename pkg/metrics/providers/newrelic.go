@@ -91,13 +91,13 @@ func NewNewRelicProvider(
 
 // RunQuery executes the new relic query against the New Relic Insights API
 // and returns the the first result
-func (p *NewRelicProvider) RunQuery(query string) (float64, error) {
+func (p *NewRelicProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 	req, err := p.newInsightsRequest(query)
 	if err != nil {
 		return 0, err
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(ctx, p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
@@ -111,7 +111,7 @@ func (p *NewRelicProvider) RunQuery(query string) (float64, error) {
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("error response: %s: %w", string(b), err)
+		return 0, statusError(r.StatusCode, fmt.Errorf("error response: %s: %w", string(b), err))
 	}
 
 	var res newRelicResponse
@@ -132,13 +132,13 @@ func (p *NewRelicProvider) RunQuery(query string) (float64, error) {
 
 // IsOnline calls the NewRelic's insights API with
 // and returns an error if the request is rejected
-func (p *NewRelicProvider) IsOnline() (bool, error) {
+func (p *NewRelicProvider) IsOnline(ctx context.Context) (bool, error) {
 	req, err := p.newInsightsRequest("SELECT * FROM Metric")
 	if err != nil {
 		return false, fmt.Errorf("error http.NewRequest: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(ctx, p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
@@ -153,7 +153,7 @@ func (p *NewRelicProvider) IsOnline() (bool, error) {
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("error response: %s", string(b))
+		return false, statusError(r.StatusCode, fmt.Errorf("error response: %s", string(b)))
 	}
 
 	return true, nil
@@ -17,6 +17,7 @@ limitations under the License.
 package providers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -77,7 +78,7 @@ func TestNewRelicProvider_RunQuery(t *testing.T) {
 		)
 		require.NoError(t, err)
 
-		f, err := nr.RunQuery(q)
+		f, err := nr.RunQuery(context.TODO(), q)
 		assert.NoError(t, err)
 		assert.Equal(t, er, f)
 	})
@@ -97,7 +98,7 @@ func TestNewRelicProvider_RunQuery(t *testing.T) {
 				"newrelic_account_id": []byte(accountId)},
 		)
 		require.NoError(t, err)
-		_, err = dp.RunQuery("")
+		_, err = dp.RunQuery(context.TODO(), "")
 		require.True(t, errors.Is(err, ErrNoValuesFound))
 	})
 }
@@ -131,7 +132,7 @@ func TestNewReelicProvider_IsOnline(t *testing.T) {
 			)
 			require.NoError(t, err)
 
-			_, err = dp.IsOnline()
+			_, err = dp.IsOnline(context.TODO())
 			if c.errExpected {
 				require.Error(t, err)
 			} else {
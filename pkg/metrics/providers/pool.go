@@ -0,0 +1,117 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// Pool caches the providers built from metric templates across analysis runs,
+// so that the provider clients and their connections are reused.
+// A pooled provider is rebuilt when the template generation or the secret version changes.
+type Pool struct {
+	factory *Factory
+	mu      sync.Mutex
+	entries map[poolKey]poolEntry
+}
+
+type poolKey struct {
+	namespace string
+	name      string
+	interval  string
+}
+
+type poolEntry struct {
+	version  string
+	secret   string
+	provider Interface
+}
+
+// NewPool returns an empty provider pool backed by the factory
+func NewPool(factory *Factory) *Pool {
+	return &Pool{
+		factory: factory,
+		entries: make(map[poolKey]poolEntry),
+	}
+}
+
+// Provider returns the pooled provider for the template and metric interval,
+// the secret must be the one referenced by the template provider or nil
+func (p *Pool) Provider(metricInterval string, template *flaggerv1.MetricTemplate, secret *corev1.Secret, config *rest.Config) (Interface, error) {
+	key := poolKey{namespace: template.Namespace, name: template.Name, interval: metricInterval}
+	version := fmt.Sprintf("%s/%d", template.UID, template.Generation)
+	var credentials map[string][]byte
+	var secretName string
+	if secret != nil {
+		version = fmt.Sprintf("%s/%s/%s", version, secret.UID, secret.ResourceVersion)
+		credentials = secret.Data
+		secretName = secret.Name
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if entry, ok := p.entries[key]; ok && entry.version == version {
+		return entry.provider, nil
+	}
+
+	provider, err := p.factory.Provider(metricInterval, template.Spec.Provider, credentials, config)
+	if err != nil {
+		delete(p.entries, key)
+		return nil, err
+	}
+
+	p.entries[key] = poolEntry{version: version, secret: secretName, provider: provider}
+	return provider, nil
+}
+
+// InvalidateTemplate removes the providers built from the template
+func (p *Pool) InvalidateTemplate(namespace string, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key := range p.entries {
+		if key.namespace == namespace && key.name == name {
+			delete(p.entries, key)
+		}
+	}
+}
+
+// InvalidateSecret removes the providers using the secret credentials
+func (p *Pool) InvalidateSecret(namespace string, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, entry := range p.entries {
+		if key.namespace == namespace && entry.secret == name {
+			delete(p.entries, key)
+		}
+	}
+}
+
+// Len returns the number of pooled providers
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.entries)
+}
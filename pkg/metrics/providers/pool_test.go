@@ -0,0 +1,89 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestPool_Provider(t *testing.T) {
+	pool := NewPool(NewFactory(zap.NewNop().Sugar()))
+
+	template := &flaggerv1.MetricTemplate{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "latency", UID: "1", Generation: 1},
+		Spec: flaggerv1.MetricTemplateSpec{
+			Provider: flaggerv1.MetricTemplateProvider{
+				Type:      "prometheus",
+				Address:   "http://prometheus:9090",
+				SecretRef: &corev1.LocalObjectReference{Name: "prometheus"},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prometheus", UID: "2", ResourceVersion: "1"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+
+	first, err := pool.Provider("1m", template, secret, nil)
+	require.NoError(t, err)
+
+	cached, err := pool.Provider("1m", template, secret, nil)
+	require.NoError(t, err)
+	assert.Same(t, first, cached)
+
+	other, err := pool.Provider("5m", template, secret, nil)
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+	assert.Equal(t, 2, pool.Len())
+
+	// secret rotation
+	secret = secret.DeepCopy()
+	secret.ResourceVersion = "2"
+	rotated, err := pool.Provider("1m", template, secret, nil)
+	require.NoError(t, err)
+	assert.NotSame(t, first, rotated)
+
+	// template change
+	template = template.DeepCopy()
+	template.Generation = 2
+	changed, err := pool.Provider("1m", template, secret, nil)
+	require.NoError(t, err)
+	assert.NotSame(t, rotated, changed)
+	assert.Equal(t, 2, pool.Len())
+
+	pool.InvalidateSecret("default", "prometheus")
+	assert.Equal(t, 0, pool.Len())
+
+	_, err = pool.Provider("1m", template, secret, nil)
+	require.NoError(t, err)
+	pool.InvalidateTemplate("default", "latency")
+	assert.Equal(t, 0, pool.Len())
+
+	// invalid templates are not cached
+	template.Spec.Provider.Timeout = "invalid"
+	_, err = pool.Provider("1m", template, secret, nil)
+	require.Error(t, err)
+	assert.Equal(t, 0, pool.Len())
+}
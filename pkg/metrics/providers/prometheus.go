@@ -49,11 +49,9 @@ type PrometheusProvider struct {
 type prometheusResponse struct {
 	Data struct {
 		Result []struct {
-			Metric struct {
-				Name string `json:"name"`
-			}
-			Value  []interface{} `json:"value"`
-			Values []interface{} `json:"values"`
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+			Values []interface{}     `json:"values"`
 		}
 	}
 }
@@ -103,11 +101,86 @@ func NewPrometheusProvider(provider flaggerv1.MetricTemplateProvider, credential
 }
 
 // RunQuery executes the promQL query and returns the the first result as float64
-func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
+func (p *PrometheusProvider) RunQuery(ctx context.Context, query string) (float64, error) {
+	result, err := p.query(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	var value *float64
+	for _, v := range result.Data.Result {
+		if v.Values != nil {
+			return 0, fmt.Errorf("%w", ErrMultipleValuesReturned)
+		}
+		metricValue := v.Value[1]
+		switch metricValue.(type) {
+		case string:
+			f, err := strconv.ParseFloat(metricValue.(string), 64)
+			if err != nil {
+				return 0, err
+			}
+			value = &f
+		}
+	}
+	if value == nil || math.IsNaN(*value) {
+		return 0, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return *value, nil
+}
+
+// RunVectorQuery executes the promQL query and returns the value and labels of each series
+func (p *PrometheusProvider) RunVectorQuery(ctx context.Context, query string) ([]Sample, error) {
+	result, err := p.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []Sample
+	for _, v := range result.Data.Result {
+		if v.Values != nil {
+			return nil, fmt.Errorf("%w", ErrMultipleValuesReturned)
+		}
+		metricValue, ok := v.Value[1].(string)
+		if !ok {
+			continue
+		}
+		f, err := strconv.ParseFloat(metricValue, 64)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(f) {
+			continue
+		}
+		samples = append(samples, Sample{Labels: v.Metric, Value: f})
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("%w", ErrNoValuesFound)
+	}
+
+	return samples, nil
+}
+
+// IsOnline run simple Prometheus query and returns an error if the API is unreachable
+func (p *PrometheusProvider) IsOnline(ctx context.Context) (bool, error) {
+	value, err := p.RunQuery(ctx, prometheusOnlineQuery)
+	if err != nil {
+		return false, fmt.Errorf("running query failed: %w", err)
+	}
+
+	if value != float64(1) {
+		return false, fmt.Errorf("value is not 1 for query: %s", prometheusOnlineQuery)
+	}
+
+	return true, nil
+}
+
+// query executes the promQL query and returns the decoded API response
+func (p *PrometheusProvider) query(ctx context.Context, query string) (*prometheusResponse, error) {
 	query = url.QueryEscape(p.trimQuery(query))
 	u, err := url.Parse(fmt.Sprintf("./api/v1/query?query=%s", query))
 	if err != nil {
-		return 0, fmt.Errorf("url.Parse failed: %w", err)
+		return nil, fmt.Errorf("url.Parse failed: %w", err)
 	}
 	u.Path = path.Join(p.url.Path, u.Path)
 
@@ -115,77 +188,45 @@ func (p *PrometheusProvider) RunQuery(query string) (float64, error) {
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
-		return 0, fmt.Errorf("http.NewRequest failed: %w", err)
+		return nil, fmt.Errorf("http.NewRequest failed: %w", err)
 	}
 
+	// the headers are cloned as the provider is shared by the checks running in parallel
 	if p.headers != nil {
-		req.Header = p.headers
+		req.Header = p.headers.Clone()
 	}
 
 	if p.token != "" {
-		req.Header.Add("Authorization", "Bearer "+p.token)
+		req.Header.Set("Authorization", "Bearer "+p.token)
 	} else if p.username != "" && p.password != "" {
 		req.SetBasicAuth(p.username, p.password)
 	}
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(ctx, p.timeout)
 	defer cancel()
 
 	r, err := p.client.Do(req.WithContext(ctx))
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer r.Body.Close()
 
 	b, err := io.ReadAll(r.Body)
 	if err != nil {
-		return 0, fmt.Errorf("error reading body: %w", err)
+		return nil, fmt.Errorf("error reading body: %w", err)
 	}
 
 	if 400 <= r.StatusCode {
-		return 0, fmt.Errorf("error response: Status %d %s: %s", r.StatusCode, http.StatusText(r.StatusCode), string(b))
+		return nil, statusError(r.StatusCode, fmt.Errorf("error response: Status %d %s: %s", r.StatusCode, http.StatusText(r.StatusCode), string(b)))
 	}
 
 	var result prometheusResponse
 	err = json.Unmarshal(b, &result)
 	if err != nil {
-		return 0, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
-	}
-
-	var value *float64
-	for _, v := range result.Data.Result {
-		if v.Values != nil {
-			return 0, fmt.Errorf("%w", ErrMultipleValuesReturned)
-		}
-		metricValue := v.Value[1]
-		switch metricValue.(type) {
-		case string:
-			f, err := strconv.ParseFloat(metricValue.(string), 64)
-			if err != nil {
-				return 0, err
-			}
-			value = &f
-		}
-	}
-	if value == nil || math.IsNaN(*value) {
-		return 0, fmt.Errorf("%w", ErrNoValuesFound)
-	}
-
-	return *value, nil
-}
-
-// IsOnline run simple Prometheus query and returns an error if the API is unreachable
-func (p *PrometheusProvider) IsOnline() (bool, error) {
-	value, err := p.RunQuery(prometheusOnlineQuery)
-	if err != nil {
-		return false, fmt.Errorf("running query failed: %w", err)
+		return nil, fmt.Errorf("error unmarshaling result: %w, '%s'", err, string(b))
 	}
 
-	if value != float64(1) {
-		return false, fmt.Errorf("value is not 1 for query: %s", prometheusOnlineQuery)
-	}
-
-	return true, nil
+	return &result, nil
 }
 
 // trimQuery takes a promql query and removes whitespace
@@ -140,7 +140,7 @@ func TestPrometheusProvider_RunQueryWithBasicAuth(t *testing.T) {
 		prom, err := NewPrometheusProvider(template.Spec.Provider, secret.Data)
 		require.NoError(t, err)
 
-		val, err := prom.RunQuery(template.Spec.Query)
+		val, err := prom.RunQuery(context.TODO(), template.Spec.Query)
 		require.NoError(t, err)
 
 		assert.Equal(t, float64(100), val)
@@ -175,7 +175,7 @@ func TestPrometheusProvider_RunQueryWithBasicAuth(t *testing.T) {
 			prom, err := NewPrometheusProvider(template.Spec.Provider, secret.Data)
 			require.NoError(t, err)
 
-			_, err = prom.RunQuery(template.Spec.Query)
+			_, err = prom.RunQuery(context.TODO(), template.Spec.Query)
 			require.True(t, errors.Is(err, ErrNoValuesFound))
 		})
 	}
@@ -208,7 +208,7 @@ func TestPrometheusProvider_RunQueryWithBasicAuth(t *testing.T) {
 			prom, err := NewPrometheusProvider(template.Spec.Provider, secret.Data)
 			require.NoError(t, err)
 
-			_, err = prom.RunQuery(template.Spec.Query)
+			_, err = prom.RunQuery(context.TODO(), template.Spec.Query)
 			require.True(t, errors.Is(err, ErrMultipleValuesReturned))
 		})
 	}
@@ -244,7 +244,7 @@ func TestPrometheusProvider_RunQueryWithBearerAuth(t *testing.T) {
 		prom, err := NewPrometheusProvider(template.Spec.Provider, secret.Data)
 		require.NoError(t, err)
 
-		val, err := prom.RunQuery(template.Spec.Query)
+		val, err := prom.RunQuery(context.TODO(), template.Spec.Query)
 		require.NoError(t, err)
 
 		assert.Equal(t, float64(100), val)
@@ -268,7 +268,7 @@ func TestPrometheusProvider_IsOnline(t *testing.T) {
 		prom, err := NewPrometheusProvider(template.Spec.Provider, nil)
 		require.NoError(t, err)
 
-		ok, err := prom.IsOnline()
+		ok, err := prom.IsOnline(context.TODO())
 		assert.Error(t, err, "Got no error wanted %v", http.StatusBadGateway)
 		assert.False(t, ok)
 	})
@@ -301,7 +301,7 @@ func TestPrometheusProvider_IsOnline(t *testing.T) {
 		prom, err := NewPrometheusProvider(template.Spec.Provider, secret.Data)
 		require.NoError(t, err)
 
-		ok, err := prom.IsOnline()
+		ok, err := prom.IsOnline(context.TODO())
 		require.NoError(t, err)
 
 		assert.Equal(t, true, ok)
@@ -338,9 +338,46 @@ func TestPrometheusProvider_RunQueryWithProviderHeaders(t *testing.T) {
 		prom, err := NewPrometheusProvider(template.Spec.Provider, secret.Data)
 		require.NoError(t, err)
 
-		val, err := prom.RunQuery(template.Spec.Query)
+		val, err := prom.RunQuery(context.TODO(), template.Spec.Query)
 		require.NoError(t, err)
 
 		assert.Equal(t, float64(100), val)
 	})
 }
+
+func TestPrometheusProvider_RunVectorQuery(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json := `{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"pod":"podinfo-1"},"value":[1545905245.458,"1.5"]},
+				{"metric":{"pod":"podinfo-2"},"value":[1545905245.458,"NaN"]},
+				{"metric":{"pod":"podinfo-3"},"value":[1545905245.458,"3"]}]}}`
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		samples, err := prom.RunVectorQuery(context.TODO(), "errors_total")
+		require.NoError(t, err)
+
+		assert.Equal(t, []Sample{
+			{Labels: map[string]string{"pod": "podinfo-1"}, Value: 1.5},
+			{Labels: map[string]string{"pod": "podinfo-3"}, Value: 3},
+		}, samples)
+	})
+
+	t.Run("no values", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+		}))
+		defer ts.Close()
+
+		prom, err := NewPrometheusProvider(flaggerv1.MetricTemplateProvider{Type: "prometheus", Address: ts.URL}, nil)
+		require.NoError(t, err)
+
+		_, err = prom.RunVectorQuery(context.TODO(), "errors_total")
+		assert.True(t, errors.Is(err, ErrNoValuesFound))
+	})
+}
@@ -16,10 +16,24 @@ limitations under the License.
 
 package providers
 
+import (
+	"context"
+	"time"
+)
+
 type Interface interface {
 	// RunQuery executes the query and converts the first result to float64
-	RunQuery(query string) (float64, error)
+	RunQuery(ctx context.Context, query string) (float64, error)
 
 	// IsOnline calls the provider endpoint and returns an error if the API is unreachable
-	IsOnline() (bool, error)
+	IsOnline(ctx context.Context) (bool, error)
+}
+
+// withTimeout returns a context bound to the provider default timeout,
+// unless the caller has already set a deadline e.g. from the template timeout
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
 }
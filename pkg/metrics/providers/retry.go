@@ -0,0 +1,170 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+const defaultRetryBackoff = time.Second
+
+// retryPolicy holds the template timeout and retry settings
+type retryPolicy struct {
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+func newRetryPolicy(provider flaggerv1.MetricTemplateProvider) (retryPolicy, error) {
+	policy := retryPolicy{
+		retries: provider.Retries,
+		backoff: defaultRetryBackoff,
+	}
+
+	if provider.Retries < 0 {
+		return policy, fmt.Errorf("%s retries %d must be positive", provider.Type, provider.Retries)
+	}
+
+	if provider.Timeout != "" {
+		timeout, err := time.ParseDuration(provider.Timeout)
+		if err != nil || timeout <= 0 {
+			return policy, fmt.Errorf("%s timeout %s is not a valid duration", provider.Type, provider.Timeout)
+		}
+		policy.timeout = timeout
+	}
+
+	if provider.RetryBackoff != "" {
+		backoff, err := time.ParseDuration(provider.RetryBackoff)
+		if err != nil || backoff <= 0 {
+			return policy, fmt.Errorf("%s retry backoff %s is not a valid duration", provider.Type, provider.RetryBackoff)
+		}
+		policy.backoff = backoff
+	}
+
+	return policy, nil
+}
+
+// wrap returns the client unchanged when no timeout and retries are set,
+// the alerts and vector providers are wrapped so that they keep implementing
+// AlertsInterface and VectorInterface
+func (policy retryPolicy) wrap(client Interface) Interface {
+	if policy.timeout == 0 && policy.retries == 0 {
+		return client
+	}
+
+	rp := &retryProvider{client: client, policy: policy}
+	if alerts, ok := client.(AlertsInterface); ok {
+		return &retryAlertsProvider{retryProvider: rp, alerts: alerts}
+	}
+	if vector, ok := client.(VectorInterface); ok {
+		return &retryVectorProvider{retryProvider: rp, vector: vector}
+	}
+	return rp
+}
+
+// do calls fn with the per attempt timeout and retries it with exponential
+// backoff while the error is transient and the context is not done
+func (policy retryPolicy) do(ctx context.Context, fn func(ctx context.Context) error) error {
+	backoff := policy.backoff
+	for attempt := 0; ; attempt++ {
+		err := policy.try(ctx, fn)
+		if err == nil || !IsTransient(err) || attempt >= policy.retries {
+			if err != nil && attempt > 0 {
+				return fmt.Errorf("%w (after %d retries)", err, attempt)
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (policy retryPolicy) try(ctx context.Context, fn func(ctx context.Context) error) error {
+	if policy.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.timeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+// retryProvider applies the template retry policy to a provider
+type retryProvider struct {
+	client Interface
+	policy retryPolicy
+}
+
+func (p *retryProvider) RunQuery(ctx context.Context, query string) (float64, error) {
+	var val float64
+	err := p.policy.do(ctx, func(ctx context.Context) error {
+		var err error
+		val, err = p.client.RunQuery(ctx, query)
+		return err
+	})
+	return val, err
+}
+
+func (p *retryProvider) IsOnline(ctx context.Context) (bool, error) {
+	var ok bool
+	err := p.policy.do(ctx, func(ctx context.Context) error {
+		var err error
+		ok, err = p.client.IsOnline(ctx)
+		return err
+	})
+	return ok, err
+}
+
+// retryAlertsProvider applies the template retry policy to an alerts provider
+type retryAlertsProvider struct {
+	*retryProvider
+	alerts AlertsInterface
+}
+
+func (p *retryAlertsProvider) FiringAlerts(ctx context.Context, selector string) ([]string, error) {
+	var alerts []string
+	err := p.policy.do(ctx, func(ctx context.Context) error {
+		var err error
+		alerts, err = p.alerts.FiringAlerts(ctx, selector)
+		return err
+	})
+	return alerts, err
+}
+
+// retryVectorProvider applies the template retry policy to a vector provider
+type retryVectorProvider struct {
+	*retryProvider
+	vector VectorInterface
+}
+
+func (p *retryVectorProvider) RunVectorQuery(ctx context.Context, query string) ([]Sample, error) {
+	var samples []Sample
+	err := p.policy.do(ctx, func(ctx context.Context) error {
+		var err error
+		samples, err = p.vector.RunVectorQuery(ctx, query)
+		return err
+	})
+	return samples, err
+}
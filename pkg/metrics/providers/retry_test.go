@@ -0,0 +1,151 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestIsTransient(t *testing.T) {
+	assert.False(t, IsTransient(nil))
+	assert.False(t, IsTransient(errors.New("bad query")))
+	assert.False(t, IsTransient(fmt.Errorf("%w", ErrNoValuesFound)))
+	assert.True(t, IsTransient(fmt.Errorf("query: %w", context.DeadlineExceeded)))
+	assert.True(t, IsTransient(statusError(http.StatusBadGateway, errors.New("bad gateway"))))
+	assert.False(t, IsTransient(statusError(http.StatusBadRequest, errors.New("bad request"))))
+}
+
+func TestFactory_ProviderRetry(t *testing.T) {
+	json := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1545905245.458,"100"]}]}}`
+	factory := NewFactory(zap.NewNop().Sugar())
+
+	t.Run("retries transient errors", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		provider, err := factory.Provider("1m", flaggerv1.MetricTemplateProvider{
+			Type:         "prometheus",
+			Address:      ts.URL,
+			Retries:      2,
+			RetryBackoff: "1ms",
+		}, nil, nil)
+		require.NoError(t, err)
+
+		val, err := provider.RunQuery(context.TODO(), "vector(100)")
+		require.NoError(t, err)
+		assert.Equal(t, float64(100), val)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("does not retry query errors", func(t *testing.T) {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer ts.Close()
+
+		provider, err := factory.Provider("1m", flaggerv1.MetricTemplateProvider{
+			Type:         "prometheus",
+			Address:      ts.URL,
+			Retries:      2,
+			RetryBackoff: "1ms",
+		}, nil, nil)
+		require.NoError(t, err)
+
+		_, err = provider.RunQuery(context.TODO(), "vector(100)")
+		require.Error(t, err)
+		assert.False(t, IsTransient(err))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte(json))
+		}))
+		defer ts.Close()
+
+		provider, err := factory.Provider("1m", flaggerv1.MetricTemplateProvider{
+			Type:    "prometheus",
+			Address: ts.URL,
+			Timeout: "10ms",
+		}, nil, nil)
+		require.NoError(t, err)
+
+		_, err = provider.RunQuery(context.TODO(), "vector(100)")
+		require.Error(t, err)
+		assert.True(t, IsTransient(err))
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		ts.Close()
+
+		provider, err := factory.Provider("1m", flaggerv1.MetricTemplateProvider{
+			Type:    "prometheus",
+			Address: ts.URL,
+			Retries: 3,
+		}, nil, nil)
+		require.NoError(t, err)
+
+		_, err = provider.RunQuery(context.TODO(), "vector(100)")
+		require.Error(t, err)
+		assert.False(t, IsTransient(err))
+	})
+
+	t.Run("keeps alerts interface", func(t *testing.T) {
+		provider, err := factory.Provider("1m", flaggerv1.MetricTemplateProvider{
+			Type:    "alertmanager",
+			Address: "http://alertmanager:9093",
+			Retries: 1,
+		}, nil, nil)
+		require.NoError(t, err)
+
+		_, ok := provider.(AlertsInterface)
+		assert.True(t, ok)
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		_, err := factory.Provider("1m", flaggerv1.MetricTemplateProvider{
+			Type:    "prometheus",
+			Address: "http://prometheus:9090",
+			Timeout: "1x",
+		}, nil, nil)
+		require.Error(t, err)
+	})
+}
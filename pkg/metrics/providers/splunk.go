@@ -96,13 +96,13 @@ func NewSplunkProvider(metricInterval string,
 }
 
 // RunQuery executes the query and converts the first result to float64
-func (p *SplunkProvider) RunQuery(query string) (float64, error) {
+func (p *SplunkProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 	c, err := signalflow.NewClient(signalflow.StreamURL(p.metricsQueryEndpoint), signalflow.AccessToken(p.token))
 	if err != nil {
 		return 0, fmt.Errorf("error creating signalflow client: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	ctx, cancel := withTimeout(ctx, p.timeout)
 	defer cancel()
 
 	now := time.Now().UnixMilli()
@@ -164,7 +164,7 @@ func (p *SplunkProvider) receivePaylods(comp *signalflow.Computation) []messages
 }
 
 // IsOnline calls the provider endpoint and returns an error if the API is unreachable
-func (p *SplunkProvider) IsOnline() (bool, error) {
+func (p *SplunkProvider) IsOnline(ctx context.Context) (bool, error) {
 	req, err := http.NewRequest("GET", p.apiValidationEndpoint, nil)
 	if err != nil {
 		return false, fmt.Errorf("error http.NewRequest: %w", err)
@@ -172,7 +172,7 @@ func (p *SplunkProvider) IsOnline() (bool, error) {
 
 	req.Header.Add(signalFxTokenHeaderKey, p.token)
 
-	ctx, cancel := context.WithTimeout(req.Context(), p.timeout)
+	ctx, cancel := withTimeout(ctx, p.timeout)
 	defer cancel()
 	r, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
@@ -187,7 +187,7 @@ func (p *SplunkProvider) IsOnline() (bool, error) {
 	}
 
 	if r.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("error response: %s", string(b))
+		return false, statusError(r.StatusCode, fmt.Errorf("error response: %s", string(b)))
 	}
 
 	return true, nil
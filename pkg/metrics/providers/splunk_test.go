@@ -17,6 +17,7 @@ limitations under the License.
 package providers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -86,7 +87,7 @@ func TestSplunkProvider_RunQuery(t *testing.T) {
 		)
 		require.NoError(t, err)
 
-		f, err := sp.RunQuery(pg)
+		f, err := sp.RunQuery(context.TODO(), pg)
 		require.NoError(t, err)
 		assert.Equal(t, expected, f)
 	})
@@ -119,7 +120,7 @@ func TestSplunkProvider_RunQuery(t *testing.T) {
 			},
 		)
 		require.NoError(t, err)
-		_, err = sp.RunQuery(pg)
+		_, err = sp.RunQuery(context.TODO(), pg)
 		require.True(t, errors.Is(err, ErrNoValuesFound))
 	})
 
@@ -151,7 +152,7 @@ func TestSplunkProvider_RunQuery(t *testing.T) {
 			},
 		)
 		require.NoError(t, err)
-		_, err = sp.RunQuery(pg)
+		_, err = sp.RunQuery(context.TODO(), pg)
 		require.True(t, errors.Is(err, ErrMultipleValuesReturned))
 	})
 }
@@ -181,7 +182,7 @@ func TestSplunkProvider_IsOnline(t *testing.T) {
 			)
 			require.NoError(t, err)
 
-			_, err = sp.IsOnline()
+			_, err = sp.IsOnline(context.TODO())
 			if c.errExpected {
 				require.Error(t, err)
 			} else {
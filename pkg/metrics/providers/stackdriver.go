@@ -75,8 +75,7 @@ func NewStackDriverProvider(provider flaggerv1.MetricTemplateProvider,
 
 // RunQuery executes Monitoring Query Language(MQL) queries against the
 // Cloud Monitoring API
-func (s *StackDriverProvider) RunQuery(query string) (float64, error) {
-	ctx := context.Background()
+func (s *StackDriverProvider) RunQuery(ctx context.Context, query string) (float64, error) {
 	req := &monitoringpb.QueryTimeSeriesRequest{
 		Name:  s.project,
 		Query: query,
@@ -96,7 +95,12 @@ func (s *StackDriverProvider) RunQuery(query string) (float64, error) {
 				errStr = errStr + " Error Detail: " + d.String()
 			}
 
-			return 0, fmt.Errorf("error requesting stackdriver: %s", err)
+			err = fmt.Errorf("error requesting stackdriver: %s", err)
+			switch s.Code() {
+			case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+				return 0, newTransientError(err)
+			}
+			return 0, err
 		}
 		return 0, fmt.Errorf("error requesting stackdriver: %s", err)
 	}
@@ -118,8 +122,7 @@ func (s *StackDriverProvider) RunQuery(query string) (float64, error) {
 // and returns an error if the returned status code is NOT grpc.InvalidArgument.
 // For example, if the flagger does not the authorization scope `https://www.googleapis.com/auth/monitoring.read`,
 // the returned status code would be grpc.PermissionDenied
-func (s *StackDriverProvider) IsOnline() (bool, error) {
-	ctx := context.Background()
+func (s *StackDriverProvider) IsOnline(ctx context.Context) (bool, error) {
 	req := &monitoringpb.QueryTimeSeriesRequest{
 		Name:  s.project,
 		Query: "",
@@ -105,7 +105,7 @@ func TestStackDriverProvider_IsOnline(t *testing.T) {
 			t.Fatal(err)
 		}
 		p := StackDriverProvider{client: c}
-		actual, err := p.IsOnline()
+		actual, err := p.IsOnline(context.TODO())
 		assert.NoError(t, err)
 		assert.True(t, actual)
 	})
@@ -117,7 +117,7 @@ func TestStackDriverProvider_IsOnline(t *testing.T) {
 			t.Fatal(err)
 		}
 		p := StackDriverProvider{client: c}
-		actual, err := p.IsOnline()
+		actual, err := p.IsOnline(context.TODO())
 		assert.Error(t, err)
 		assert.False(t, actual)
 	})
@@ -164,7 +164,7 @@ func TestStackDriverProvider_RunQuery(t *testing.T) {
 			t.Fatal(err)
 		}
 		p := StackDriverProvider{client: c}
-		actual, err := p.RunQuery(query)
+		actual, err := p.RunQuery(context.TODO(), query)
 		assert.NoError(t, err)
 		assert.Equal(t, actual, exp)
 	})
@@ -183,7 +183,7 @@ func TestStackDriverProvider_RunQuery(t *testing.T) {
 			t.Fatal(err)
 		}
 		p := StackDriverProvider{client: c}
-		_, err = p.RunQuery(query)
+		_, err = p.RunQuery(context.TODO(), query)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, ErrNoValuesFound)
 	})
@@ -0,0 +1,62 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VectorInterface is implemented by the providers that can return
+// one labelled value per series e.g. per pod or per route
+type VectorInterface interface {
+	// RunVectorQuery executes the query and returns every series of the result
+	RunVectorQuery(ctx context.Context, query string) ([]Sample, error)
+}
+
+// Sample is the value of a series and its label set
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// LabelSet formats the sample labels as a Prometheus label set e.g. {pod="podinfo-1"}
+func (s Sample) LabelSet() string {
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, s.Labels[k]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Matches returns true if the sample has all the given labels
+func (s Sample) Matches(labels map[string]string) bool {
+	for k, v := range labels {
+		if s.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
+)
+
+// knativeTagHeader is the header used by Knative to route requests to a tagged revision
+const knativeTagHeader = "knative-serving-tag"
+
+// ValidateABTesting returns an error if the router of the provider
+// can't route the requests matching the canary analysis conditions
+func ValidateABTesting(provider string, canary *flaggerv1.Canary) error {
+	analysis := canary.GetAnalysis()
+	if analysis == nil || len(analysis.Match) == 0 {
+		return nil
+	}
+
+	switch {
+	case provider == flaggerv1.KumaProvider || provider == flaggerv1.OsmProvider ||
+		strings.HasPrefix(provider, flaggerv1.SMIProvider):
+		return fmt.Errorf("A/B testing is not supported by the %s provider", provider)
+	case provider == flaggerv1.TraefikProvider:
+		if canary.Spec.RouteRef == nil || canary.Spec.RouteRef.Name == "" {
+			return fmt.Errorf("A/B testing with the %s provider requires a routeRef to the IngressRoute", provider)
+		}
+		return validateHeaderMatch(provider, analysis.Match)
+	case provider == flaggerv1.ApisixProvider:
+		return validateHeaderMatch(provider, analysis.Match)
+	case provider == flaggerv1.SkipperProvider:
+		if len(analysis.Match) > 1 {
+			return fmt.Errorf("A/B testing with the %s provider supports a single match condition", provider)
+		}
+		return validateHeaderMatch(provider, analysis.Match)
+	case provider == flaggerv1.ALBProvider:
+		if len(analysis.Match) > 1 {
+			return fmt.Errorf("A/B testing with the %s provider supports a single match condition", provider)
+		}
+		for name, value := range analysis.Match[0].Headers {
+			if value.Regex != "" {
+				return fmt.Errorf("A/B testing with the %s provider doesn't support regex matching, header %s is invalid", provider, name)
+			}
+		}
+		return validateHeaderMatch(provider, analysis.Match)
+	case provider == flaggerv1.HAProxyProvider:
+		if len(analysis.Match) > 1 || len(analysis.Match[0].Headers) > 1 {
+			return fmt.Errorf("A/B testing with the %s provider supports a single header condition", provider)
+		}
+		return validateHeaderMatch(provider, analysis.Match)
+	case provider == flaggerv1.KnativeProvider:
+		if _, err := knativeTag(analysis.Match); err != nil {
+			return fmt.Errorf("A/B testing with the %s provider %w", provider, err)
+		}
+	}
+	return nil
+}
+
+// validateHeaderMatch returns an error if the match conditions contain anything else than headers
+func validateHeaderMatch(provider string, match []istiov1beta1.HTTPMatchRequest) error {
+	for i, m := range match {
+		if len(m.Headers) == 0 || m.Uri != nil || m.Scheme != nil || m.Method != nil || m.Authority != nil ||
+			len(m.QueryParams) > 0 || len(m.WithoutHeaders) > 0 || len(m.SourceLabels) > 0 {
+			return fmt.Errorf("A/B testing with the %s provider supports header matching only, match %d is invalid", provider, i)
+		}
+	}
+	return nil
+}
+
+// knativeTag returns the revision tag set by the match condition on the Knative-Serving-Tag header
+func knativeTag(match []istiov1beta1.HTTPMatchRequest) (string, error) {
+	if len(match) != 1 || len(match[0].Headers) != 1 {
+		return "", fmt.Errorf("supports a single match condition on the Knative-Serving-Tag header")
+	}
+	for name, value := range match[0].Headers {
+		if strings.ToLower(name) != knativeTagHeader || value.Exact == "" {
+			return "", fmt.Errorf("supports only an exact match on the Knative-Serving-Tag header")
+		}
+		return value.Exact, nil
+	}
+	return "", nil
+}
+
+// sortedHeaders returns the header names of a match condition in alphabetical order,
+// so that the generated routes don't change between reconciliations
+func sortedHeaders(headers map[string]istiov1alpha1.StringMatch) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// stringMatchRegex returns the regular expression equivalent of a string match
+func stringMatchRegex(match istiov1alpha1.StringMatch) string {
+	switch {
+	case match.Regex != "":
+		return match.Regex
+	case match.Prefix != "":
+		return "^" + regexp.QuoteMeta(match.Prefix)
+	case match.Suffix != "":
+		return regexp.QuoteMeta(match.Suffix) + "$"
+	default:
+		return "^" + regexp.QuoteMeta(match.Exact) + "$"
+	}
+}
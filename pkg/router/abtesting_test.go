@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
+)
+
+func TestValidateABTesting(t *testing.T) {
+	headerMatch := istiov1beta1.HTTPMatchRequest{
+		Headers: map[string]istiov1alpha1.StringMatch{
+			"x-canary": {Exact: "insider"},
+		},
+	}
+	tagMatch := istiov1beta1.HTTPMatchRequest{
+		Headers: map[string]istiov1alpha1.StringMatch{
+			"Knative-Serving-Tag": {Exact: "insider"},
+		},
+	}
+	regexMatch := istiov1beta1.HTTPMatchRequest{
+		Headers: map[string]istiov1alpha1.StringMatch{
+			"x-canary": {Regex: "^insider$"},
+		},
+	}
+	uriMatch := istiov1beta1.HTTPMatchRequest{
+		Uri: &istiov1alpha1.StringMatch{Prefix: "/api"},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		match    []istiov1beta1.HTTPMatchRequest
+		routeRef *flaggerv1.LocalObjectReference
+		wantErr  bool
+	}{
+		{name: "istio", provider: flaggerv1.IstioProvider, match: []istiov1beta1.HTTPMatchRequest{uriMatch}},
+		{name: "kuma", provider: flaggerv1.KumaProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch}, wantErr: true},
+		{name: "smi", provider: "smi:v1alpha3", match: []istiov1beta1.HTTPMatchRequest{headerMatch}, wantErr: true},
+		{name: "kuma without match", provider: flaggerv1.KumaProvider},
+		{name: "apisix", provider: flaggerv1.ApisixProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch, headerMatch}},
+		{name: "apisix uri", provider: flaggerv1.ApisixProvider, match: []istiov1beta1.HTTPMatchRequest{uriMatch}, wantErr: true},
+		{name: "skipper", provider: flaggerv1.SkipperProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch}},
+		{name: "skipper conditions", provider: flaggerv1.SkipperProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch, headerMatch}, wantErr: true},
+		{name: "alb", provider: flaggerv1.ALBProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch}},
+		{name: "alb regex", provider: flaggerv1.ALBProvider, match: []istiov1beta1.HTTPMatchRequest{regexMatch}, wantErr: true},
+		{name: "haproxy", provider: flaggerv1.HAProxyProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch}},
+		{name: "haproxy conditions", provider: flaggerv1.HAProxyProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch, headerMatch}, wantErr: true},
+		{name: "traefik", provider: flaggerv1.TraefikProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch},
+			routeRef: &flaggerv1.LocalObjectReference{Name: "podinfo"}},
+		{name: "traefik without routeRef", provider: flaggerv1.TraefikProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch}, wantErr: true},
+		{name: "knative", provider: flaggerv1.KnativeProvider, match: []istiov1beta1.HTTPMatchRequest{tagMatch}},
+		{name: "knative header", provider: flaggerv1.KnativeProvider, match: []istiov1beta1.HTTPMatchRequest{headerMatch}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canary := &flaggerv1.Canary{
+				Spec: flaggerv1.CanarySpec{
+					RouteRef: tt.routeRef,
+					Analysis: &flaggerv1.CanaryAnalysis{Match: tt.match},
+				},
+			}
+			err := ValidateABTesting(tt.provider, canary)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
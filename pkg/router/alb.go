@@ -0,0 +1,402 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+)
+
+const (
+	// albActionsAnnotationPrefix is the prefix of the AWS Load Balancer Controller custom actions
+	albActionsAnnotationPrefix = "alb.ingress.kubernetes.io/actions."
+	// albConditionsAnnotationPrefix is the prefix of the AWS Load Balancer Controller custom conditions
+	albConditionsAnnotationPrefix = "alb.ingress.kubernetes.io/conditions."
+	// albUseAnnotation is the backend port name that makes the controller use the custom action
+	// named after the backend service instead of the service itself
+	albUseAnnotation = "use-annotation"
+)
+
+// albAction is an AWS Load Balancer Controller custom action
+type albAction struct {
+	Type          string            `json:"type"`
+	ForwardConfig *albForwardConfig `json:"forwardConfig,omitempty"`
+}
+
+// albForwardConfig forwards the requests to one or more target groups
+type albForwardConfig struct {
+	TargetGroups []albTargetGroup `json:"targetGroups"`
+}
+
+// albTargetGroup is the target group of a Kubernetes service
+type albTargetGroup struct {
+	ServiceName string `json:"serviceName"`
+	ServicePort string `json:"servicePort"`
+	Weight      *int   `json:"weight,omitempty"`
+}
+
+// albCondition is an AWS Load Balancer Controller custom condition
+type albCondition struct {
+	Field            string               `json:"field"`
+	HTTPHeaderConfig *albHTTPHeaderConfig `json:"httpHeaderConfig,omitempty"`
+}
+
+// albHTTPHeaderConfig matches the requests with the header values,
+// the values can contain the * and ? wildcards
+type albHTTPHeaderConfig struct {
+	HTTPHeaderName string   `json:"httpHeaderName"`
+	Values         []string `json:"values"`
+}
+
+// ALBRouter is managing the AWS Load Balancer Controller ingress actions
+type ALBRouter struct {
+	kubeClient kubernetes.Interface
+	logger     *zap.SugaredLogger
+}
+
+// Reconcile points the ingress paths of the apex service to the weighted forward action
+func (ar *ALBRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
+		return fmt.Errorf("ingress selector is empty")
+	}
+
+	apexName, _, _ := canary.GetServiceNames()
+	ingress, err := ar.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s get query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
+	}
+
+	iClone := ingress.DeepCopy()
+
+	// change the backend port of <apex> to use-annotation
+	backendExists := false
+	for _, path := range albPaths(iClone) {
+		if path.Backend.Service != nil && path.Backend.Service.Name == apexName {
+			path.Backend.Service.Port = netv1.ServiceBackendPort{Name: albUseAnnotation}
+			backendExists = true
+		}
+	}
+
+	if !backendExists {
+		return fmt.Errorf("backend %s not found in ingress %s", apexName, canary.Spec.IngressRef.Name)
+	}
+
+	if iClone.Annotations == nil {
+		iClone.Annotations = make(map[string]string)
+	}
+
+	// initialise the weighted action with all the traffic routed to the primary
+	if _, _, err := ar.parseWeights(canary, iClone.Annotations[albActionsAnnotationPrefix+apexName]); err != nil {
+		action, err := ar.makeForwardAction(canary, 100, 0)
+		if err != nil {
+			return err
+		}
+		iClone.Annotations[albActionsAnnotationPrefix+apexName] = action
+	}
+
+	if cmp.Diff(ingress.Spec, iClone.Spec) == "" && cmp.Diff(ingress.Annotations, iClone.Annotations) == "" {
+		return nil
+	}
+
+	_, err = ar.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s update error: %w", iClone.Name, iClone.Namespace, err)
+	}
+
+	ar.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("Ingress %s.%s updated", iClone.Name, iClone.Namespace)
+	return nil
+}
+
+// GetRoutes returns the destinations weight of the forward action
+func (ar *ALBRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	apexName, _, _ := canary.GetServiceNames()
+	ingress, err := ar.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("ingress %s.%s get query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
+		return
+	}
+
+	// A/B testing
+	if len(canary.GetAnalysis().Match) > 0 {
+		if _, ok := ingress.Annotations[albActionsAnnotationPrefix+ar.abTestingActionName(canary)]; ok {
+			return 0, 100, false, nil
+		}
+	}
+
+	primaryWeight, canaryWeight, err = ar.parseWeights(canary, ingress.Annotations[albActionsAnnotationPrefix+apexName])
+	if err != nil {
+		err = fmt.Errorf("ingress %s.%s action %s: %w", ingress.Name, ingress.Namespace, apexName, err)
+	}
+	return
+}
+
+// SetRoutes updates the weights of the forward action, for A/B testing the requests
+// matching the conditions are routed to the canary by a dedicated action
+func (ar *ALBRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	_ bool,
+) error {
+	apexName, _, _ := canary.GetServiceNames()
+	ingress, err := ar.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s get query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
+	}
+
+	iClone := ingress.DeepCopy()
+	if iClone.Annotations == nil {
+		iClone.Annotations = make(map[string]string)
+	}
+	ar.removeABTesting(canary, iClone)
+
+	if len(canary.GetAnalysis().Match) > 0 {
+		if canaryWeight > 0 {
+			if err := ar.addABTesting(canary, iClone); err != nil {
+				return err
+			}
+		}
+		primaryWeight, canaryWeight = 100, 0
+	}
+
+	action, err := ar.makeForwardAction(canary, primaryWeight, canaryWeight)
+	if err != nil {
+		return err
+	}
+	iClone.Annotations[albActionsAnnotationPrefix+apexName] = action
+
+	_, err = ar.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s update error: %w", iClone.Name, iClone.Namespace, err)
+	}
+
+	return nil
+}
+
+// Finalize points the ingress paths back to the apex service and removes the actions
+func (ar *ALBRouter) Finalize(canary *flaggerv1.Canary) error {
+	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
+		return nil
+	}
+
+	apexName, _, _ := canary.GetServiceNames()
+	ingress, err := ar.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s get query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
+	}
+
+	iClone := ingress.DeepCopy()
+	ar.removeABTesting(canary, iClone)
+	delete(iClone.Annotations, albActionsAnnotationPrefix+apexName)
+	for _, path := range albPaths(iClone) {
+		if path.Backend.Service != nil && path.Backend.Service.Name == apexName {
+			path.Backend.Service.Port = netv1.ServiceBackendPort{Number: canary.Spec.Service.Port}
+		}
+	}
+
+	_, err = ar.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s update error: %w", iClone.Name, iClone.Namespace, err)
+	}
+	return nil
+}
+
+// addABTesting adds the A/B testing action and conditions, and inserts a path
+// routed to the action in front of each path of the apex service
+func (ar *ALBRouter) addABTesting(canary *flaggerv1.Canary, ingress *netv1.Ingress) error {
+	apexName, _, canaryName := canary.GetServiceNames()
+	actionName := ar.abTestingActionName(canary)
+
+	action, err := json.Marshal(albAction{
+		Type: "forward",
+		ForwardConfig: &albForwardConfig{
+			TargetGroups: []albTargetGroup{
+				{ServiceName: canaryName, ServicePort: strconv.Itoa(int(canary.Spec.Service.Port))},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("action %s marshal error: %w", actionName, err)
+	}
+
+	conditions, err := json.Marshal(ar.makeConditions(canary))
+	if err != nil {
+		return fmt.Errorf("conditions %s marshal error: %w", actionName, err)
+	}
+
+	ingress.Annotations[albActionsAnnotationPrefix+actionName] = string(action)
+	ingress.Annotations[albConditionsAnnotationPrefix+actionName] = string(conditions)
+
+	for i, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		var paths []netv1.HTTPIngressPath
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil && path.Backend.Service.Name == apexName {
+				abPath := *path.DeepCopy()
+				abPath.Backend.Service.Name = actionName
+				abPath.Backend.Service.Port = netv1.ServiceBackendPort{Name: albUseAnnotation}
+				paths = append(paths, abPath)
+			}
+			paths = append(paths, path)
+		}
+		ingress.Spec.Rules[i].HTTP.Paths = paths
+	}
+	return nil
+}
+
+// removeABTesting removes the A/B testing action, conditions and paths
+func (ar *ALBRouter) removeABTesting(canary *flaggerv1.Canary, ingress *netv1.Ingress) {
+	actionName := ar.abTestingActionName(canary)
+	delete(ingress.Annotations, albActionsAnnotationPrefix+actionName)
+	delete(ingress.Annotations, albConditionsAnnotationPrefix+actionName)
+
+	for i, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		var paths []netv1.HTTPIngressPath
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil || path.Backend.Service.Name != actionName {
+				paths = append(paths, path)
+			}
+		}
+		ingress.Spec.Rules[i].HTTP.Paths = paths
+	}
+}
+
+// makeForwardAction returns the action that splits the traffic between the primary and canary
+func (ar *ALBRouter) makeForwardAction(canary *flaggerv1.Canary, primaryWeight, canaryWeight int) (string, error) {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	port := strconv.Itoa(int(canary.Spec.Service.Port))
+
+	action, err := json.Marshal(albAction{
+		Type: "forward",
+		ForwardConfig: &albForwardConfig{
+			TargetGroups: []albTargetGroup{
+				{ServiceName: primaryName, ServicePort: port, Weight: &primaryWeight},
+				{ServiceName: canaryName, ServicePort: port, Weight: &canaryWeight},
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("action marshal error: %w", err)
+	}
+	return string(action), nil
+}
+
+// makeConditions returns the header conditions of the A/B testing action
+func (ar *ALBRouter) makeConditions(canary *flaggerv1.Canary) []albCondition {
+	var conditions []albCondition
+	for _, m := range canary.GetAnalysis().Match {
+		for _, name := range sortedHeaders(m.Headers) {
+			conditions = append(conditions, albCondition{
+				Field: "http-header",
+				HTTPHeaderConfig: &albHTTPHeaderConfig{
+					HTTPHeaderName: name,
+					Values:         []string{albHeaderValue(m.Headers[name])},
+				},
+			})
+		}
+	}
+	return conditions
+}
+
+// parseWeights returns the primary and canary weights of a forward action
+func (ar *ALBRouter) parseWeights(canary *flaggerv1.Canary, annotation string) (primaryWeight int, canaryWeight int, err error) {
+	if annotation == "" {
+		return 0, 0, fmt.Errorf("action not found")
+	}
+
+	var action albAction
+	if err := json.Unmarshal([]byte(annotation), &action); err != nil {
+		return 0, 0, fmt.Errorf("action unmarshal error: %w", err)
+	}
+	if action.ForwardConfig == nil {
+		return 0, 0, fmt.Errorf("action forward config not found")
+	}
+
+	_, primaryName, canaryName := canary.GetServiceNames()
+	primaryFound, canaryFound := false, false
+	for _, tg := range action.ForwardConfig.TargetGroups {
+		if tg.ServicePort != strconv.Itoa(int(canary.Spec.Service.Port)) || tg.Weight == nil {
+			continue
+		}
+		switch tg.ServiceName {
+		case primaryName:
+			primaryWeight, primaryFound = *tg.Weight, true
+		case canaryName:
+			canaryWeight, canaryFound = *tg.Weight, true
+		}
+	}
+
+	if !primaryFound || !canaryFound {
+		return 0, 0, fmt.Errorf("target groups %s and %s not found", primaryName, canaryName)
+	}
+	return
+}
+
+// abTestingActionName returns the name of the action that routes
+// the requests matching the A/B testing conditions to the canary
+func (ar *ALBRouter) abTestingActionName(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	return fmt.Sprintf("%s-ab-testing", apexName)
+}
+
+// albPaths returns pointers to the HTTP paths of all the ingress rules
+func albPaths(ingress *netv1.Ingress) []*netv1.HTTPIngressPath {
+	var paths []*netv1.HTTPIngressPath
+	for i := range ingress.Spec.Rules {
+		if ingress.Spec.Rules[i].HTTP == nil {
+			continue
+		}
+		for x := range ingress.Spec.Rules[i].HTTP.Paths {
+			paths = append(paths, &ingress.Spec.Rules[i].HTTP.Paths[x])
+		}
+	}
+	return paths
+}
+
+// albHeaderValue returns the ALB wildcard pattern equivalent of a header string match
+func albHeaderValue(match istiov1alpha1.StringMatch) string {
+	switch {
+	case match.Prefix != "":
+		return match.Prefix + "*"
+	case match.Suffix != "":
+		return "*" + match.Suffix
+	default:
+		return match.Exact
+	}
+}
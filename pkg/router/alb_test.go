@@ -0,0 +1,170 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
+)
+
+func TestALBRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ALBRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	ingress, err := router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+	assert.Equal(t, "podinfo", backend.Name)
+	assert.Equal(t, "use-annotation", backend.Port.Name)
+	assert.JSONEq(t,
+		`{"type":"forward","forwardConfig":{"targetGroups":[{"serviceName":"podinfo-primary","servicePort":"9898","weight":100},{"serviceName":"podinfo-canary","servicePort":"9898","weight":0}]}}`,
+		ingress.Annotations["alb.ingress.kubernetes.io/actions.podinfo"])
+
+	// test idempotency
+	err = router.SetRoutes(mocks.ingressCanary, 60, 40, false)
+	require.NoError(t, err)
+	err = router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	p, c, _, err := router.GetRoutes(mocks.ingressCanary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+}
+
+func TestALBRouter_GetSetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ALBRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(mocks.ingressCanary, 90, 10, false)
+	require.NoError(t, err)
+
+	p, c, m, err := router.GetRoutes(mocks.ingressCanary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, p)
+	assert.Equal(t, 10, c)
+	assert.False(t, m)
+
+	// test promotion
+	err = router.SetRoutes(mocks.ingressCanary, 100, 0, false)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(mocks.ingressCanary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+}
+
+func TestALBRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ALBRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+	}
+
+	canary := mocks.ingressCanary
+	canary.Spec.Analysis.Iterations = 1
+	canary.Spec.Analysis.Match = []istiov1beta1.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-canary": {Exact: "insider"},
+				"x-user":   {Prefix: "test"},
+			},
+		},
+	}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(canary, 0, 100, false)
+	require.NoError(t, err)
+
+	ingress, err := router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	paths := ingress.Spec.Rules[0].HTTP.Paths
+	require.Len(t, paths, 2)
+	assert.Equal(t, "podinfo-ab-testing", paths[0].Backend.Service.Name)
+	assert.Equal(t, "podinfo", paths[1].Backend.Service.Name)
+	assert.JSONEq(t,
+		`{"type":"forward","forwardConfig":{"targetGroups":[{"serviceName":"podinfo-canary","servicePort":"9898"}]}}`,
+		ingress.Annotations["alb.ingress.kubernetes.io/actions.podinfo-ab-testing"])
+	assert.JSONEq(t,
+		`[{"field":"http-header","httpHeaderConfig":{"httpHeaderName":"x-canary","values":["insider"]}},{"field":"http-header","httpHeaderConfig":{"httpHeaderName":"x-user","values":["test*"]}}]`,
+		ingress.Annotations["alb.ingress.kubernetes.io/conditions.podinfo-ab-testing"])
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	// test promotion
+	err = router.SetRoutes(canary, 100, 0, false)
+	require.NoError(t, err)
+
+	ingress, err = router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, ingress.Spec.Rules[0].HTTP.Paths, 1)
+	assert.NotContains(t, ingress.Annotations, "alb.ingress.kubernetes.io/actions.podinfo-ab-testing")
+
+	p, c, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+}
+
+func TestALBRouter_Finalize(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &ALBRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	err = router.Finalize(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	ingress, err := router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	backend := ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service
+	assert.Equal(t, "podinfo", backend.Name)
+	assert.Equal(t, int32(9898), backend.Port.Number)
+	assert.NotContains(t, ingress.Annotations, "alb.ingress.kubernetes.io/actions.podinfo")
+}
@@ -19,6 +19,8 @@ package router
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -30,17 +32,24 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
 )
 
 // ApisixRouter is managing Apisix Route
 type ApisixRouter struct {
 	apisixClient clientset.Interface
+	kubeClient   kubernetes.Interface
 	logger       *zap.SugaredLogger
 	setOwnerRefs bool
 }
 
 const maxPriority = 10000
 
+const (
+	stickyRouteSuffix     = "-sticky"
+	responseRewritePlugin = "response-rewrite"
+)
+
 // Reconcile creates or updates the Apisix Route
 func (ar *ApisixRouter) Reconcile(canary *flaggerv1.Canary) error {
 	if canary.Spec.RouteRef == nil || canary.Spec.RouteRef.Name == "" {
@@ -86,8 +95,14 @@ func (ar *ApisixRouter) Reconcile(canary *flaggerv1.Canary) error {
 		Subset:             primaryBackend.Subset,
 	}
 
+	// session affinity: APISIX proxies to the canary ClusterIP so that the
+	// responses of the canary can be told apart by the upstream address
+	if hasSessionAffinity(canary) {
+		canaryBackend.ResolveGranularity = "service"
+	}
+
 	targetHttpRoute.Backends = append(targetHttpRoute.Backends, canaryBackend)
-	apisixRouteClone.Spec.HTTP = []a6v2.ApisixRouteHTTP{*targetHttpRoute}
+	apisixRouteClone.Spec.HTTP = ar.makeHTTPRoutes(canary, *targetHttpRoute)
 
 	canaryApisixRouteName := fmt.Sprintf("%s-%s-canary", canary.Spec.RouteRef.Name, apexName)
 	canaryApisixRoute, err := ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Get(context.TODO(), canaryApisixRouteName, metav1.GetOptions{})
@@ -125,8 +140,22 @@ func (ar *ApisixRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("APISIX route %s.%s query error: %w", canaryApisixRouteName, canary.Namespace, err)
 	}
 
-	if diff := cmp.Diff(canaryApisixRoute.Spec, apisixRouteClone.Spec,
-		cmpopts.IgnoreFields(a6v2.ApisixRouteHTTPBackend{}, "Weight")); diff != "" {
+	ignoreCmpOptions := []cmp.Option{
+		cmpopts.IgnoreFields(a6v2.ApisixRouteHTTPBackend{}, "Weight"),
+	}
+	if hasSessionAffinity(canary) {
+		// ignore the routes that match the session affinity cookies and the plugin setting the cookie
+		ignoreCmpOptions = append(ignoreCmpOptions,
+			cmpopts.IgnoreSliceElements(func(route a6v2.ApisixRouteHTTP) bool {
+				return strings.HasSuffix(route.Name, stickyRouteSuffix)
+			}),
+			cmpopts.IgnoreSliceElements(func(plugin a6v2.ApisixRoutePlugin) bool {
+				return plugin.Name == responseRewritePlugin
+			}),
+		)
+	}
+
+	if diff := cmp.Diff(canaryApisixRoute.Spec, apisixRouteClone.Spec, ignoreCmpOptions...); diff != "" {
 		iClone := canaryApisixRoute.DeepCopy()
 		iClone.Spec = apisixRouteClone.Spec
 
@@ -141,6 +170,46 @@ func (ar *ApisixRouter) Reconcile(canary *flaggerv1.Canary) error {
 	return nil
 }
 
+// makeHTTPRoutes returns the weighted route, or for A/B testing a weighted route per match condition
+// and a route with a lower priority that sends the rest of the requests to the primary
+func (ar *ApisixRouter) makeHTTPRoutes(canary *flaggerv1.Canary, weighted a6v2.ApisixRouteHTTP) []a6v2.ApisixRouteHTTP {
+	if len(canary.GetAnalysis().Match) == 0 {
+		return []a6v2.ApisixRouteHTTP{weighted}
+	}
+
+	var routes []a6v2.ApisixRouteHTTP
+	for i, match := range canary.GetAnalysis().Match {
+		route := weighted.DeepCopy()
+		route.Name = fmt.Sprintf("%s-ab-%d", weighted.Name, i)
+		for _, name := range sortedHeaders(match.Headers) {
+			value := match.Headers[name]
+			expr := a6v2.ApisixRouteHTTPMatchExpr{
+				Subject: a6v2.ApisixRouteHTTPMatchExprSubject{
+					Scope: "Header",
+					Name:  name,
+				},
+			}
+			if value.Exact != "" {
+				expr.Op = "Equal"
+				expr.Value = &value.Exact
+			} else {
+				regex := stringMatchRegex(value)
+				expr.Op = "RegexMatch"
+				expr.Value = &regex
+			}
+			route.Match.NginxVars = append(route.Match.NginxVars, expr)
+		}
+		routes = append(routes, *route)
+	}
+
+	primaryWeight := 100
+	primary := weighted.DeepCopy()
+	primary.Priority = maxPriority - 1
+	primary.Backends = primary.Backends[:1]
+	primary.Backends[0].Weight = &primaryWeight
+	return append(routes, *primary)
+}
+
 func (ar *ApisixRouter) getTargetHttpRoute(canary *flaggerv1.Canary, apisixRoute *a6v2.ApisixRoute, serviceName string) (*a6v2.ApisixRouteHTTP, int, error) {
 	for index, item := range apisixRoute.Spec.HTTP {
 		for _, backend := range item.Backends {
@@ -203,19 +272,34 @@ func (ar *ApisixRouter) SetRoutes(
 		return fmt.Errorf("apisix route %s.%s query error: %w", canaryApisixRouteName, canary.Namespace, err)
 	}
 
-	_, targetIndex, err := ar.getTargetHttpRoute(canary, apisixRoute, primaryName)
-	if err != nil {
+	if _, _, err := ar.getTargetHttpRoute(canary, apisixRoute, primaryName); err != nil {
 		return err
 	}
-	backends := apisixRoute.Spec.HTTP[targetIndex].Backends
-	for i, backend := range backends {
-		if backend.ServiceName == primaryName {
-			backends[i].Weight = &primaryWeight
-		} else if backend.ServiceName == canaryName {
-			backends[i].Weight = &canaryWeight
+
+	// set the weights of every route with a canary backend, for A/B testing
+	// the route of the requests that don't match keeps sending them to the primary
+	for _, route := range apisixRoute.Spec.HTTP {
+		if !slices.ContainsFunc(route.Backends, func(backend a6v2.ApisixRouteHTTPBackend) bool {
+			return backend.ServiceName == canaryName
+		}) {
+			continue
+		}
+		for i, backend := range route.Backends {
+			if backend.ServiceName == primaryName {
+				route.Backends[i].Weight = &primaryWeight
+			} else if backend.ServiceName == canaryName {
+				route.Backends[i].Weight = &canaryWeight
+			}
+		}
+	}
+
+	if hasSessionAffinity(canary) {
+		routes, err := ar.makeSessionAffinityRoutes(canary, canaryWeight, apisixRoute.Spec.HTTP)
+		if err != nil {
+			return err
 		}
+		apisixRoute.Spec.HTTP = routes
 	}
-	apisixRoute.Spec.HTTP[targetIndex].Backends = backends
 
 	_, err = ar.apisixClient.ApisixV2().ApisixRoutes(canary.Namespace).Update(context.TODO(), apisixRoute, metav1.UpdateOptions{})
 	if err != nil {
@@ -224,6 +308,101 @@ func (ar *ApisixRouter) SetRoutes(
 	return nil
 }
 
+// makeSessionAffinityRoutes returns the weighted route with a plugin that sets the canary cookie
+// on the responses of the canary and a route that sends the requests with the cookie to the canary.
+// After the canary run, the route matching the previous canary cookie expires it.
+func (ar *ApisixRouter) makeSessionAffinityRoutes(canary *flaggerv1.Canary, canaryWeight int,
+	httpRoutes []a6v2.ApisixRouteHTTP) ([]a6v2.ApisixRouteHTTP, error) {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	sessionAffinity := canary.Spec.Analysis.SessionAffinity
+
+	var routes []a6v2.ApisixRouteHTTP
+	for _, route := range httpRoutes {
+		if strings.HasSuffix(route.Name, stickyRouteSuffix) {
+			continue
+		}
+		route.Plugins = slices.DeleteFunc(route.Plugins, func(plugin a6v2.ApisixRoutePlugin) bool {
+			return plugin.Name == responseRewritePlugin
+		})
+		routes = append(routes, route)
+	}
+
+	weighted, index, err := ar.getTargetHttpRoute(canary, &a6v2.ApisixRoute{Spec: a6v2.ApisixRouteSpec{HTTP: routes}}, canaryName)
+	if err != nil {
+		return nil, err
+	}
+
+	canaryCookie, _, previousCookie := sessionAffinityCookies(canary, canaryWeight)
+	if canaryWeight == 0 {
+		if previousCookie == "" {
+			return routes, nil
+		}
+		expireRoute := ar.makeCookieRoute(*weighted, previousCookie, primaryName)
+		expireRoute.Plugins = append(expireRoute.Plugins, makeSetCookiePlugin(expiredCookie(previousCookie), ""))
+		return append(routes, expireRoute), nil
+	}
+
+	svc, err := ar.kubeClient.CoreV1().Services(canary.Namespace).Get(context.TODO(), canaryName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("service %s.%s get query error: %w", canaryName, canary.Namespace, err)
+	}
+	upstreamAddr := fmt.Sprintf("%s:%d", svc.Spec.ClusterIP, canary.Spec.Service.Port)
+
+	routes[index].Plugins = append(slices.Clone(weighted.Plugins), makeSetCookiePlugin(
+		sessionAffinity.BuildCookie(canaryCookie, sessionAffinity.GetMaxAge()), upstreamAddr))
+
+	return append(routes, ar.makeCookieRoute(*weighted, canaryCookie, canaryName)), nil
+}
+
+// makeCookieRoute returns a copy of the weighted route with a higher priority
+// that matches the cookie and sends all requests to the specified service
+func (ar *ApisixRouter) makeCookieRoute(weighted a6v2.ApisixRouteHTTP, cookie string, serviceName string) a6v2.ApisixRouteHTTP {
+	name, value := splitCookie(cookie)
+	weight := 100
+
+	route := weighted.DeepCopy()
+	route.Name = weighted.Name + stickyRouteSuffix
+	route.Priority = weighted.Priority + 1
+	route.Match.NginxVars = append(route.Match.NginxVars, a6v2.ApisixRouteHTTPMatchExpr{
+		Subject: a6v2.ApisixRouteHTTPMatchExprSubject{
+			Scope: "Cookie",
+			Name:  name,
+		},
+		Op:    "Equal",
+		Value: &value,
+	})
+	route.Backends = slices.DeleteFunc(route.Backends, func(backend a6v2.ApisixRouteHTTPBackend) bool {
+		return backend.ServiceName != serviceName
+	})
+	for i := range route.Backends {
+		route.Backends[i].Weight = &weight
+	}
+	return *route
+}
+
+// makeSetCookiePlugin returns the response-rewrite plugin that sets the cookie,
+// if an upstream address is specified only on the responses of that upstream
+func makeSetCookiePlugin(cookie string, upstreamAddr string) a6v2.ApisixRoutePlugin {
+	config := a6v2.ApisixRoutePluginConfig{
+		"headers": map[string]interface{}{
+			"set": map[string]interface{}{
+				setCookieHeader: cookie,
+			},
+		},
+	}
+	if upstreamAddr != "" {
+		config["vars"] = []interface{}{
+			[]interface{}{"upstream_addr", "==", upstreamAddr},
+		}
+	}
+
+	return a6v2.ApisixRoutePlugin{
+		Name:   responseRewritePlugin,
+		Enable: true,
+		Config: config,
+	}
+}
+
 func (ar *ApisixRouter) Finalize(_ *flaggerv1.Canary) error {
 	return nil
 }
@@ -19,11 +19,15 @@ package router
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -86,3 +90,136 @@ func TestApisixRouter_GetSetRoutes(t *testing.T) {
 	assert.Equal(t, 50, *arRouter.Spec.HTTP[0].Backends[0].Weight)
 	assert.Equal(t, 50, *arRouter.Spec.HTTP[0].Backends[1].Weight)
 }
+
+func TestApisixRouter_SessionAffinity(t *testing.T) {
+	mocks := newFixture(nil)
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.RouteRef = &v1beta1.LocalObjectReference{
+		Name:       "podinfo",
+		Kind:       "ApisixRoute",
+		APIVersion: "apisix.apache.org/v2",
+	}
+	canary.Spec.Analysis.SessionAffinity = &v1beta1.SessionAffinity{
+		CookieName: "flagger-cookie",
+		MaxAge:     600,
+	}
+	_, err := mocks.kubeClient.CoreV1().Services("default").Create(context.TODO(), &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo-canary", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.10"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	router := &ApisixRouter{
+		apisixClient: mocks.flaggerClient,
+		kubeClient:   mocks.kubeClient,
+		logger:       mocks.logger,
+	}
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.SetRoutes(canary, 90, 10, false))
+
+	arRouter, err := router.apisixClient.ApisixV2().ApisixRoutes("default").Get(context.TODO(), "podinfo-podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, arRouter.Spec.HTTP, 2)
+
+	// the weighted route sets the cookie on the responses of the canary service
+	weighted := arRouter.Spec.HTTP[0]
+	assert.Equal(t, "service", weighted.Backends[1].ResolveGranularity)
+	plugin := weighted.Plugins[len(weighted.Plugins)-1]
+	assert.Equal(t, "response-rewrite", plugin.Name)
+	assert.Equal(t, map[string]interface{}{
+		"set": map[string]interface{}{
+			"Set-Cookie": fmt.Sprintf("%s; Max-Age=600", canary.Status.SessionAffinityCookie),
+		},
+	}, plugin.Config["headers"])
+	assert.Equal(t, []interface{}{[]interface{}{"upstream_addr", "==", "10.96.0.10:9898"}}, plugin.Config["vars"])
+
+	// the sticky route sends the requests with the cookie to the canary
+	_, value, _ := strings.Cut(canary.Status.SessionAffinityCookie, "=")
+	sticky := arRouter.Spec.HTTP[1]
+	assert.Equal(t, weighted.Name+"-sticky", sticky.Name)
+	assert.Equal(t, weighted.Priority+1, sticky.Priority)
+	assert.Equal(t, "Cookie", sticky.Match.NginxVars[0].Subject.Scope)
+	assert.Equal(t, "flagger-cookie", sticky.Match.NginxVars[0].Subject.Name)
+	assert.Equal(t, value, *sticky.Match.NginxVars[0].Value)
+	require.Len(t, sticky.Backends, 1)
+	assert.Equal(t, "podinfo-canary", sticky.Backends[0].ServiceName)
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, p)
+	assert.Equal(t, 10, c)
+
+	// after promotion the sticky route expires the previous canary cookie
+	cookie := canary.Status.SessionAffinityCookie
+	require.NoError(t, router.SetRoutes(canary, 100, 0, false))
+
+	arRouter, err = router.apisixClient.ApisixV2().ApisixRoutes("default").Get(context.TODO(), "podinfo-podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, arRouter.Spec.HTTP, 2)
+	for _, plugin := range arRouter.Spec.HTTP[0].Plugins {
+		assert.NotEqual(t, "response-rewrite", plugin.Name)
+	}
+	expire := arRouter.Spec.HTTP[1]
+	assert.Equal(t, "podinfo-primary", expire.Backends[0].ServiceName)
+	assert.Equal(t, map[string]interface{}{
+		"set": map[string]interface{}{
+			"Set-Cookie": fmt.Sprintf("%s; Max-Age=-1", cookie),
+		},
+	}, expire.Plugins[len(expire.Plugins)-1].Config["headers"])
+}
+
+func TestApisixRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.RouteRef = &v1beta1.LocalObjectReference{
+		Name:       "podinfo",
+		Kind:       "ApisixRoute",
+		APIVersion: "apisix.apache.org/v2",
+	}
+	canary.Spec.Analysis.Iterations = 10
+	canary.Spec.Analysis.Match = []istiov1beta1.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-canary": {Exact: "insider"},
+			},
+		},
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"cookie": {Regex: "^(.*?;)?(canary=always)(;.*)?$"},
+			},
+		},
+	}
+	router := &ApisixRouter{
+		apisixClient: mocks.flaggerClient,
+		logger:       mocks.logger,
+	}
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.SetRoutes(canary, 0, 100, false))
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	arCanary, err := router.apisixClient.ApisixV2().ApisixRoutes("default").Get(context.TODO(), "podinfo-podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	routes := arCanary.Spec.HTTP
+	require.Len(t, routes, 3)
+
+	assert.Equal(t, "method-ab-0", routes[0].Name)
+	require.Len(t, routes[0].Match.NginxVars, 1)
+	assert.Equal(t, "x-canary", routes[0].Match.NginxVars[0].Subject.Name)
+	assert.Equal(t, "Equal", routes[0].Match.NginxVars[0].Op)
+	assert.Equal(t, "insider", *routes[0].Match.NginxVars[0].Value)
+	assert.Equal(t, 100, *routes[0].Backends[1].Weight)
+
+	assert.Equal(t, "method-ab-1", routes[1].Name)
+	assert.Equal(t, "RegexMatch", routes[1].Match.NginxVars[0].Op)
+
+	// the requests that don't match are routed to the primary
+	assert.Equal(t, "method", routes[2].Name)
+	assert.Less(t, routes[2].Priority, routes[0].Priority)
+	require.Len(t, routes[2].Backends, 1)
+	assert.Equal(t, "podinfo-primary", routes[2].Backends[0].ServiceName)
+	assert.Equal(t, 100, *routes[2].Backends[0].Weight)
+}
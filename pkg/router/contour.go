@@ -206,12 +206,23 @@ func (cr *ContourRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("HTTPProxy %s.%s get query error: %w", apexName, canary.Namespace, err)
 	}
 
-	// update HTTPProxy but keep the original destination weights
+	ignoreCmpOptions := []cmp.Option{
+		cmpopts.IgnoreFields(contourv1.Service{}, "Weight", "Mirror"),
+	}
+	if canary.Spec.Analysis.SessionAffinity != nil {
+		// ignore the routes that match the session affinity cookies and the cookies set by the services
+		ignoreCmpOptions = append(ignoreCmpOptions,
+			cmpopts.IgnoreSliceElements(isContourCookieRoute),
+			cmpopts.IgnoreFields(contourv1.Service{}, "ResponseHeadersPolicy"),
+		)
+	}
+
+	// update HTTPProxy but keep the original destination weights and mirror
 	if proxy != nil {
 		specDiff := cmp.Diff(
 			newSpec,
 			proxy.Spec,
-			cmpopts.IgnoreFields(contourv1.Service{}, "Weight"),
+			ignoreCmpOptions...,
 		)
 		labelsDiff := cmp.Diff(newMetadata.Labels, proxy.Labels, cmpopts.EquateEmpty())
 		annotationsDiff := cmp.Diff(newMetadata.Annotations, proxy.Annotations, cmpopts.EquateEmpty())
@@ -254,10 +265,11 @@ func (cr *ContourRouter) GetRoutes(canary *flaggerv1.Canary) (
 	}
 
 	for _, dst := range proxy.Spec.Routes[0].Services {
-		if dst.Name == primaryName {
+		if dst.Mirror {
+			mirrored = true
+		} else if dst.Name == primaryName {
 			primaryWeight = int(dst.Weight)
 			canaryWeight = 100 - primaryWeight
-			return
 		}
 	}
 
@@ -269,7 +281,7 @@ func (cr *ContourRouter) SetRoutes(
 	canary *flaggerv1.Canary,
 	primaryWeight int,
 	canaryWeight int,
-	_ bool,
+	mirrored bool,
 ) error {
 	apexName, primaryName, canaryName := canary.GetServiceNames()
 
@@ -382,6 +394,24 @@ func (cr *ContourRouter) SetRoutes(
 		}
 	}
 
+	// mirror the traffic of the primary to the canary
+	if mirrored && len(canary.GetAnalysis().Match) == 0 {
+		for i, svc := range proxy.Spec.Routes[0].Services {
+			if svc.Name == canaryName {
+				proxy.Spec.Routes[0].Services[i].Mirror = true
+				proxy.Spec.Routes[0].Services[i].Weight = int64(canary.GetAnalysis().MirrorWeight)
+			}
+		}
+	}
+
+	if hasSessionAffinity(canary) {
+		routes, err := cr.makeSessionAffinityRoutes(canary, canaryWeight, proxy.Spec.Routes[0])
+		if err != nil {
+			return err
+		}
+		proxy.Spec.Routes = routes
+	}
+
 	if cr.ingressClass != "" {
 		proxy.Spec.IngressClassName = cr.ingressClass
 	}
@@ -393,6 +423,92 @@ func (cr *ContourRouter) SetRoutes(
 	return nil
 }
 
+// makeSessionAffinityRoutes returns the weighted route with the services setting the session
+// affinity cookies and the routes that send the requests with a cookie to the same service.
+// After the canary run, the route matching the previous canary cookie expires it.
+func (cr *ContourRouter) makeSessionAffinityRoutes(canary *flaggerv1.Canary, canaryWeight int,
+	weighted contourv1.Route) ([]contourv1.Route, error) {
+	_, primaryName, canaryName := canary.GetServiceNames()
+	sessionAffinity := canary.Spec.Analysis.SessionAffinity
+	canaryCookie, primaryCookie, previousCookie := sessionAffinityCookies(canary, canaryWeight)
+
+	if canaryWeight == 0 {
+		if previousCookie == "" {
+			return []contourv1.Route{weighted}, nil
+		}
+		expireRoute := cr.makeCookieRoute(weighted, previousCookie, primaryName)
+		expireRoute.ResponseHeadersPolicy = makeSetCookiePolicy(expiredCookie(previousCookie))
+		return []contourv1.Route{weighted, expireRoute}, nil
+	}
+
+	routes := []contourv1.Route{weighted, cr.makeCookieRoute(weighted, canaryCookie, canaryName)}
+	if primaryCookie != "" {
+		routes = append(routes, cr.makeCookieRoute(weighted, primaryCookie, primaryName))
+	}
+
+	weightedRoute := weighted.DeepCopy()
+	for i, svc := range weightedRoute.Services {
+		switch svc.Name {
+		case canaryName:
+			weightedRoute.Services[i].ResponseHeadersPolicy = makeSetCookiePolicy(
+				sessionAffinity.BuildCookie(canaryCookie, sessionAffinity.GetMaxAge()))
+		case primaryName:
+			if primaryCookie == "" {
+				continue
+			}
+			maxAge, err := primaryCookieMaxAge(canary)
+			if err != nil {
+				return nil, err
+			}
+			weightedRoute.Services[i].ResponseHeadersPolicy = makeSetCookiePolicy(
+				sessionAffinity.BuildCookie(primaryCookie, maxAge))
+		}
+	}
+	routes[0] = *weightedRoute
+
+	return routes, nil
+}
+
+// makeCookieRoute returns a copy of the weighted route that matches the cookie
+// and sends all requests to the specified service
+func (cr *ContourRouter) makeCookieRoute(weighted contourv1.Route, cookie string, serviceName string) contourv1.Route {
+	route := weighted.DeepCopy()
+	route.Conditions = append(route.Conditions, contourv1.MatchCondition{
+		Header: &contourv1.HeaderMatchCondition{
+			Name:     cookieHeader,
+			Contains: cookie,
+		},
+	})
+	for i, svc := range route.Services {
+		route.Services[i].Weight = 0
+		if svc.Name == serviceName {
+			route.Services[i].Weight = 100
+		}
+	}
+	return *route
+}
+
+// isContourCookieRoute returns true if the route matches the Cookie header
+func isContourCookieRoute(route contourv1.Route) bool {
+	for _, condition := range route.Conditions {
+		if condition.Header != nil && condition.Header.Name == cookieHeader {
+			return true
+		}
+	}
+	return false
+}
+
+func makeSetCookiePolicy(cookie string) *contourv1.HeadersPolicy {
+	return &contourv1.HeadersPolicy{
+		Set: []contourv1.HeaderValue{
+			{
+				Name:  setCookieHeader,
+				Value: cookie,
+			},
+		},
+	}
+}
+
 func (cr *ContourRouter) makePrefix(canary *flaggerv1.Canary) string {
 	prefix := "/"
 
@@ -18,8 +18,11 @@ package router
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	contourv1 "github.com/fluxcd/flagger/pkg/apis/projectcontour/v1"
 
 	"github.com/stretchr/testify/assert"
@@ -156,3 +159,116 @@ func TestContourRouter_Routes(t *testing.T) {
 	primary = proxy.Spec.Routes[1].Services[0]
 	assert.Equal(t, int64(100), primary.Weight)
 }
+
+func TestContourRouter_SessionAffinity(t *testing.T) {
+	canary := newTestCanary()
+	canary.Spec.Analysis.Interval = "1m"
+	canary.Spec.Analysis.SessionAffinity = &flaggerv1.SessionAffinity{
+		CookieName:        "flagger-cookie",
+		PrimaryCookieName: "primary-cookie",
+		MaxAge:            600,
+	}
+	mocks := newFixture(canary)
+	router := &ContourRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		contourClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.SetRoutes(canary, 90, 10, false))
+
+	proxy, err := router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, proxy.Spec.Routes, 3)
+	assert.True(t, strings.HasPrefix(canary.Status.SessionAffinityCookie, "flagger-cookie="))
+	assert.True(t, strings.HasPrefix(canary.Status.PrimarySessionAffinityCookie, "primary-cookie="))
+
+	// the weighted route sets the cookies
+	weighted := proxy.Spec.Routes[0]
+	assert.Equal(t, int64(90), weighted.Services[0].Weight)
+	assert.Equal(t, fmt.Sprintf("%s; Max-Age=60", canary.Status.PrimarySessionAffinityCookie),
+		weighted.Services[0].ResponseHeadersPolicy.Set[0].Value)
+	assert.Equal(t, fmt.Sprintf("%s; Max-Age=600", canary.Status.SessionAffinityCookie),
+		weighted.Services[1].ResponseHeadersPolicy.Set[0].Value)
+
+	// the cookie routes send the requests to the service that set the cookie
+	canaryRoute := proxy.Spec.Routes[1]
+	assert.Equal(t, canary.Status.SessionAffinityCookie, canaryRoute.Conditions[1].Header.Contains)
+	assert.Equal(t, int64(0), canaryRoute.Services[0].Weight)
+	assert.Equal(t, int64(100), canaryRoute.Services[1].Weight)
+	assert.Nil(t, canaryRoute.Services[1].ResponseHeadersPolicy)
+	primaryRoute := proxy.Spec.Routes[2]
+	assert.Equal(t, canary.Status.PrimarySessionAffinityCookie, primaryRoute.Conditions[1].Header.Contains)
+	assert.Equal(t, int64(100), primaryRoute.Services[0].Weight)
+
+	_, cw, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 10, cw)
+
+	// reconcile keeps the cookie routes
+	require.NoError(t, router.Reconcile(canary))
+	proxy, err = router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, proxy.Spec.Routes, 3)
+
+	// after promotion the previous canary cookie is expired
+	cookie := canary.Status.SessionAffinityCookie
+	require.NoError(t, router.SetRoutes(canary, 100, 0, false))
+	assert.Empty(t, canary.Status.SessionAffinityCookie)
+	assert.Equal(t, cookie, canary.Status.PreviousSessionAffinityCookie)
+
+	proxy, err = router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, proxy.Spec.Routes, 2)
+	assert.Nil(t, proxy.Spec.Routes[0].Services[1].ResponseHeadersPolicy)
+	expireRoute := proxy.Spec.Routes[1]
+	assert.Equal(t, cookie, expireRoute.Conditions[1].Header.Contains)
+	assert.Equal(t, int64(100), expireRoute.Services[0].Weight)
+	assert.Equal(t, fmt.Sprintf("%s; Max-Age=-1", cookie), expireRoute.ResponseHeadersPolicy.Set[0].Value)
+}
+
+func TestContourRouter_Mirror(t *testing.T) {
+	canary := newTestCanary()
+	canary.Spec.Analysis.MirrorWeight = 50
+	mocks := newFixture(canary)
+	router := &ContourRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		contourClient: mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.SetRoutes(canary, 100, 0, true))
+
+	proxy, err := router.contourClient.ProjectcontourV1().HTTPProxies("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	services := proxy.Spec.Routes[0].Services
+	assert.Equal(t, int64(100), services[0].Weight)
+	assert.False(t, services[0].Mirror)
+	assert.Equal(t, "podinfo-canary", services[1].Name)
+	assert.True(t, services[1].Mirror)
+	assert.Equal(t, int64(50), services[1].Weight)
+
+	p, c, m, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.True(t, m)
+
+	// reconcile keeps the mirror
+	require.NoError(t, router.Reconcile(canary))
+	_, _, m, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.True(t, m)
+
+	// stop mirroring
+	require.NoError(t, router.SetRoutes(canary, 0, 100, false))
+	p, c, m, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+	assert.False(t, m)
+}
@@ -38,6 +38,7 @@ type Factory struct {
 	ingressClass             string
 	logger                   *zap.SugaredLogger
 	setOwnerRefs             bool
+	linkerdGatewayAPI        bool
 }
 
 func NewFactory(kubeConfig *restclient.Config, kubeClient kubernetes.Interface,
@@ -47,7 +48,8 @@ func NewFactory(kubeConfig *restclient.Config, kubeClient kubernetes.Interface,
 	ingressClass string,
 	logger *zap.SugaredLogger,
 	meshClient clientset.Interface,
-	setOwnerRefs bool) *Factory {
+	setOwnerRefs bool,
+	linkerdGatewayAPI bool) *Factory {
 	return &Factory{
 		kubeConfig:               kubeConfig,
 		meshClient:               meshClient,
@@ -58,11 +60,12 @@ func NewFactory(kubeConfig *restclient.Config, kubeClient kubernetes.Interface,
 		ingressClass:             ingressClass,
 		logger:                   logger,
 		setOwnerRefs:             setOwnerRefs,
+		linkerdGatewayAPI:        linkerdGatewayAPI,
 	}
 }
 
 // KubernetesRouter returns a KubernetesRouter interface implementation
-func (factory *Factory) KubernetesRouter(kind string, labelSelector string, labelValue string, ports map[string]int32) KubernetesRouter {
+func (factory *Factory) KubernetesRouter(kind string, labelSelector string, labelValue string, ports map[string]int32, provider string) KubernetesRouter {
 	switch kind {
 	case "Service":
 		return &KubernetesNoopRouter{}
@@ -74,6 +77,7 @@ func (factory *Factory) KubernetesRouter(kind string, labelSelector string, labe
 			labelSelector: labelSelector,
 			labelValue:    labelValue,
 			ports:         ports,
+			provider:      provider,
 		}
 	}
 }
@@ -98,6 +102,14 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			appmeshClient: factory.meshClient,
 			setOwnerRefs:  factory.setOwnerRefs,
 		}
+	case provider == flaggerv1.LinkerdProvider && factory.linkerdGatewayAPI:
+		return &LinkerdRouter{
+			logger:           factory.logger,
+			kubeClient:       factory.kubeClient,
+			gatewayAPIClient: factory.meshClient,
+			smiClient:        factory.meshClient,
+			setOwnerRefs:     factory.setOwnerRefs,
+		}
 	case provider == flaggerv1.LinkerdProvider:
 		return &SmiRouter{
 			logger:        factory.logger,
@@ -173,6 +185,17 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			annotationsPrefix: factory.ingressAnnotationsPrefix,
 			setOwnerRefs:      factory.setOwnerRefs,
 		}
+	case provider == flaggerv1.ALBProvider:
+		return &ALBRouter{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+		}
+	case provider == flaggerv1.HAProxyProvider:
+		return &HAProxyRouter{
+			logger:       factory.logger,
+			kubeClient:   factory.kubeClient,
+			setOwnerRefs: factory.setOwnerRefs,
+		}
 	case provider == flaggerv1.SkipperProvider:
 		return &SkipperRouter{
 			logger:       factory.logger,
@@ -189,6 +212,7 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 		return &ApisixRouter{
 			logger:       factory.logger,
 			apisixClient: factory.meshClient,
+			kubeClient:   factory.kubeClient,
 			setOwnerRefs: factory.setOwnerRefs,
 		}
 	case provider == flaggerv1.OsmProvider:
@@ -222,7 +246,10 @@ func (factory *Factory) MeshRouter(provider string, labelSelector string) Interf
 			setOwnerRefs:     factory.setOwnerRefs,
 		}
 	case provider == flaggerv1.KubernetesProvider:
-		return &NopRouter{}
+		return &KubernetesReplicaRouter{
+			logger:     factory.logger,
+			kubeClient: factory.kubeClient,
+		}
 	default:
 		factory.logger.Warnf("unknown mesh router provider '%s', using istio", provider)
 		return &IstioRouter{
@@ -0,0 +1,173 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+)
+
+// haproxyRouteACLAnnotation sets the condition that routes the requests of an Ingress
+// to its backends before the path based routing of the HAProxy Kubernetes Ingress controller
+const haproxyRouteACLAnnotation = "haproxy.org/route-acl"
+
+// haproxyWeightACLPrefix is the route ACL prefix of a weighted canary
+const haproxyWeightACLPrefix = "rand(100) lt "
+
+// HAProxyRouter is managing HAProxy Kubernetes Ingress controller canary ingresses
+type HAProxyRouter struct {
+	kubeClient   kubernetes.Interface
+	logger       *zap.SugaredLogger
+	setOwnerRefs bool
+}
+
+// Reconcile creates or updates the canary ingress cloned from the apex ingress
+func (hr *HAProxyRouter) Reconcile(canary *flaggerv1.Canary) error {
+	return reconcileCanaryIngress(hr.kubeClient, hr.logger, hr.setOwnerRefs, canary,
+		func(annotations map[string]string) map[string]string {
+			return hr.makeAnnotations(annotations, hr.makeWeightACL(0))
+		})
+}
+
+// GetRoutes returns the destinations weight of the canary ingress route ACL
+func (hr *HAProxyRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	canaryIngressName := hr.canaryIngressName(canary)
+	canaryIngress, err := hr.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canaryIngressName, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("ingress %s.%s get query error: %w", canaryIngressName, canary.Namespace, err)
+		return
+	}
+
+	acl := canaryIngress.Annotations[haproxyRouteACLAnnotation]
+
+	// A/B testing
+	if len(canary.GetAnalysis().Match) > 0 && acl != "" && !strings.HasPrefix(acl, haproxyWeightACLPrefix) {
+		return 0, 100, false, nil
+	}
+
+	// Canary
+	if strings.HasPrefix(acl, haproxyWeightACLPrefix) {
+		val, errAtoi := strconv.Atoi(strings.TrimPrefix(acl, haproxyWeightACLPrefix))
+		if errAtoi != nil {
+			err = fmt.Errorf("failed to parse route ACL %s: %w", acl, errAtoi)
+			return
+		}
+		canaryWeight = val
+	}
+
+	primaryWeight = 100 - canaryWeight
+	return
+}
+
+// SetRoutes updates the canary ingress route ACL with the canary weight
+// or with the A/B testing header condition
+func (hr *HAProxyRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	_ int,
+	canaryWeight int,
+	_ bool,
+) error {
+	canaryIngressName := hr.canaryIngressName(canary)
+	canaryIngress, err := hr.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canaryIngressName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s get query error: %w", canaryIngressName, canary.Namespace, err)
+	}
+
+	acl := hr.makeWeightACL(canaryWeight)
+	if len(canary.GetAnalysis().Match) > 0 && canaryWeight > 0 {
+		acl = hr.makeHeaderACL(canary)
+	}
+
+	iClone := canaryIngress.DeepCopy()
+	iClone.Annotations = hr.makeAnnotations(iClone.Annotations, acl)
+
+	_, err = hr.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s update error: %w", iClone.Name, iClone.Namespace, err)
+	}
+
+	return nil
+}
+
+// Finalize is a no-op, the canary ingress is garbage collected with the canary
+func (hr *HAProxyRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (hr *HAProxyRouter) canaryIngressName(canary *flaggerv1.Canary) string {
+	return fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
+}
+
+// makeAnnotations returns the apex ingress annotations with the canary route ACL
+func (hr *HAProxyRouter) makeAnnotations(annotations map[string]string, acl string) map[string]string {
+	res := make(map[string]string)
+	for k, v := range filterMetadata(annotations) {
+		if k != haproxyRouteACLAnnotation && !strings.Contains(k, "kubectl.kubernetes.io/last-applied-configuration") {
+			res[k] = v
+		}
+	}
+
+	res[haproxyRouteACLAnnotation] = acl
+	return res
+}
+
+// makeWeightACL returns the route ACL that sends a percentage of the requests to the canary
+func (hr *HAProxyRouter) makeWeightACL(canaryWeight int) string {
+	return fmt.Sprintf("%s%d", haproxyWeightACLPrefix, canaryWeight)
+}
+
+// makeHeaderACL returns the route ACL that sends the requests matching
+// the A/B testing header condition to the canary
+func (hr *HAProxyRouter) makeHeaderACL(canary *flaggerv1.Canary) string {
+	for _, m := range canary.GetAnalysis().Match {
+		for _, name := range sortedHeaders(m.Headers) {
+			return haproxyHeaderCondition(name, m.Headers[name])
+		}
+	}
+	return hr.makeWeightACL(0)
+}
+
+// haproxyHeaderCondition returns the HAProxy condition equivalent of a header string match,
+// an exact match on the cookie header routes the requests carrying the named cookie like NGINX does
+func haproxyHeaderCondition(name string, match istiov1alpha1.StringMatch) string {
+	switch {
+	case strings.ToLower(name) == "cookie" && match.Exact != "":
+		return fmt.Sprintf("req.cook(%s) -m found", match.Exact)
+	case match.Regex != "":
+		return fmt.Sprintf("req.hdr(%s) -m reg %s", name, match.Regex)
+	case match.Prefix != "":
+		return fmt.Sprintf("req.hdr(%s) -m beg %s", name, match.Prefix)
+	case match.Suffix != "":
+		return fmt.Sprintf("req.hdr(%s) -m end %s", name, match.Suffix)
+	default:
+		return fmt.Sprintf("req.hdr(%s) -m str %s", name, match.Exact)
+	}
+}
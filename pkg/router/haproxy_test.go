@@ -0,0 +1,121 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
+)
+
+func TestHAProxyRouter_Reconcile(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &HAProxyRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	inCanary, err := router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// test initialisation
+	assert.Equal(t, "rand(100) lt 0", inCanary.Annotations[haproxyRouteACLAnnotation])
+	assert.Equal(t, "podinfo-canary", inCanary.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name)
+}
+
+func TestHAProxyRouter_GetSetRoutes(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &HAProxyRouter{
+		logger:     mocks.logger,
+		kubeClient: mocks.kubeClient,
+	}
+
+	err := router.Reconcile(mocks.ingressCanary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(mocks.ingressCanary, 70, 30, false)
+	require.NoError(t, err)
+
+	inCanary, err := router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "rand(100) lt 30", inCanary.Annotations[haproxyRouteACLAnnotation])
+
+	p, c, m, err := router.GetRoutes(mocks.ingressCanary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, p)
+	assert.Equal(t, 30, c)
+	assert.False(t, m)
+
+	// test promotion
+	err = router.SetRoutes(mocks.ingressCanary, 100, 0, false)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(mocks.ingressCanary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+}
+
+func TestHAProxyRouter_ABTest(t *testing.T) {
+	tables := []struct {
+		header string
+		match  istiov1alpha1.StringMatch
+		acl    string
+	}{
+		{header: "x-user-type", match: istiov1alpha1.StringMatch{Exact: "test"}, acl: "req.hdr(x-user-type) -m str test"},
+		{header: "x-user-type", match: istiov1alpha1.StringMatch{Regex: "^(insider|test)$"}, acl: "req.hdr(x-user-type) -m reg ^(insider|test)$"},
+		{header: "x-user-type", match: istiov1alpha1.StringMatch{Prefix: "test"}, acl: "req.hdr(x-user-type) -m beg test"},
+		{header: "cookie", match: istiov1alpha1.StringMatch{Exact: "canary"}, acl: "req.cook(canary) -m found"},
+	}
+
+	for _, table := range tables {
+		mocks := newFixture(nil)
+		router := &HAProxyRouter{
+			logger:     mocks.logger,
+			kubeClient: mocks.kubeClient,
+		}
+		canary := mocks.ingressCanary
+		canary.Spec.Analysis.Iterations = 1
+		canary.Spec.Analysis.Match = []istiov1beta1.HTTPMatchRequest{
+			{Headers: map[string]istiov1alpha1.StringMatch{table.header: table.match}},
+		}
+
+		err := router.Reconcile(canary)
+		require.NoError(t, err)
+
+		err = router.SetRoutes(canary, 0, 100, false)
+		require.NoError(t, err)
+
+		inCanary, err := router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), "podinfo-canary", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, table.acl, inCanary.Annotations[haproxyRouteACLAnnotation])
+
+		p, c, _, err := router.GetRoutes(canary)
+		require.NoError(t, err)
+		assert.Equal(t, 0, p)
+		assert.Equal(t, 100, c)
+	}
+}
@@ -41,15 +41,27 @@ type IngressRouter struct {
 }
 
 func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
+	return reconcileCanaryIngress(i.kubeClient, i.logger, i.setOwnerRefs, canary, i.makeAnnotations)
+}
+
+// reconcileCanaryIngress creates or updates the <ingress>-canary ingress cloned from the apex ingress,
+// the backends of the apex service are pointed to the canary service. The annotations
+// of a new canary ingress are computed by makeAnnotations from the apex ingress annotations.
+func reconcileCanaryIngress(
+	kubeClient kubernetes.Interface,
+	logger *zap.SugaredLogger,
+	setOwnerRefs bool,
+	canary *flaggerv1.Canary,
+	makeAnnotations func(annotations map[string]string) map[string]string,
+) error {
 	if canary.Spec.IngressRef == nil || canary.Spec.IngressRef.Name == "" {
 		return fmt.Errorf("ingress selector is empty")
 	}
 
-	apexName, _, _ := canary.GetServiceNames()
-	canaryName := fmt.Sprintf("%s-canary", apexName)
+	apexName, _, canaryName := canary.GetServiceNames()
 	canaryIngressName := fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
 
-	ingress, err := i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	ingress, err := kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("ingress %s.%s get query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
 	}
@@ -59,6 +71,9 @@ func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
 	// change backend to <deployment-name>-canary
 	backendExists := false
 	for k, v := range ingressClone.Spec.Rules {
+		if v.HTTP == nil {
+			continue
+		}
 		for x, y := range v.HTTP.Paths {
 			if y.Backend.Service != nil && y.Backend.Service.Name == apexName {
 				ingressClone.Spec.Rules[k].HTTP.Paths[x].Backend.Service.Name = canaryName
@@ -71,19 +86,19 @@ func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("backend %s not found in ingress %s", apexName, canary.Spec.IngressRef.Name)
 	}
 
-	canaryIngress, err := i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canaryIngressName, metav1.GetOptions{})
+	canaryIngress, err := kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canaryIngressName, metav1.GetOptions{})
 
 	if errors.IsNotFound(err) {
 		ing := &netv1.Ingress{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:        canaryIngressName,
 				Namespace:   canary.Namespace,
-				Annotations: i.makeAnnotations(ingressClone.Annotations),
+				Annotations: makeAnnotations(ingressClone.Annotations),
 				Labels:      ingressClone.Labels,
 			},
 			Spec: ingressClone.Spec,
 		}
-		if i.setOwnerRefs {
+		if setOwnerRefs {
 			ing.OwnerReferences = []metav1.OwnerReference{
 				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
 					Group:   flaggerv1.SchemeGroupVersion.Group,
@@ -93,12 +108,12 @@ func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
 			}
 		}
 
-		_, err := i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Create(context.TODO(), ing, metav1.CreateOptions{})
+		_, err := kubeClient.NetworkingV1().Ingresses(canary.Namespace).Create(context.TODO(), ing, metav1.CreateOptions{})
 		if err != nil {
 			return fmt.Errorf("ingress %s.%s create error: %w", ing.Name, ing.Namespace, err)
 		}
 
-		i.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 			Infof("Ingress %s.%s created", ing.GetName(), canary.Namespace)
 		return nil
 	} else if err != nil {
@@ -109,12 +124,12 @@ func (i *IngressRouter) Reconcile(canary *flaggerv1.Canary) error {
 		iClone := canaryIngress.DeepCopy()
 		iClone.Spec = ingressClone.Spec
 
-		_, err := i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
+		_, err := kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
 		if err != nil {
 			return fmt.Errorf("ingress %s.%s update error: %w", canaryIngressName, iClone.Namespace, err)
 		}
 
-		i.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
 			Infof("Ingress %s updated", canaryIngressName)
 	}
 
@@ -158,7 +173,14 @@ func (i *IngressRouter) GetRoutes(canary *flaggerv1.Canary) (
 	}
 
 	primaryWeight = 100 - canaryWeight
-	mirrored = false
+
+	// mirroring is configured on the main ingress, NGINX ignores it on canary ingresses
+	ingress, err := i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		err = fmt.Errorf("ingress %s.%s get query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
+		return
+	}
+	mirrored = ingress.Annotations[i.GetAnnotationWithPrefix("mirror-target")] == i.makeMirrorTarget(canary)
 	return
 }
 
@@ -166,8 +188,12 @@ func (i *IngressRouter) SetRoutes(
 	canary *flaggerv1.Canary,
 	_ int,
 	canaryWeight int,
-	_ bool,
+	mirrored bool,
 ) error {
+	if err := i.setMirror(canary, mirrored && len(canary.GetAnalysis().Match) == 0); err != nil {
+		return err
+	}
+
 	canaryIngressName := fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
 	canaryIngress, err := i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canaryIngressName, metav1.GetOptions{})
 	if err != nil {
@@ -195,6 +221,12 @@ func (i *IngressRouter) SetRoutes(
 	} else {
 		// canary
 		iClone.Annotations[i.GetAnnotationWithPrefix("canary-weight")] = fmt.Sprintf("%v", canaryWeight)
+
+		// session affinity: NGINX sets a cookie that keeps the client on the canary once routed to it
+		if hasSessionAffinity(canary) {
+			trackSessionAffinityCookieName(canary, canaryWeight)
+			iClone.Annotations = i.makeSessionAffinityAnnotations(iClone.Annotations, canary.Spec.Analysis.SessionAffinity)
+		}
 	}
 
 	// toggle canary
@@ -212,6 +244,42 @@ func (i *IngressRouter) SetRoutes(
 	return nil
 }
 
+// setMirror adds or removes the mirror target annotation of the main ingress
+func (i *IngressRouter) setMirror(canary *flaggerv1.Canary, mirrored bool) error {
+	ingress, err := i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), canary.Spec.IngressRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s get query error: %w", canary.Spec.IngressRef.Name, canary.Namespace, err)
+	}
+
+	key := i.GetAnnotationWithPrefix("mirror-target")
+	target := i.makeMirrorTarget(canary)
+	if (ingress.Annotations[key] == target) == mirrored {
+		return nil
+	}
+
+	iClone := ingress.DeepCopy()
+	if mirrored {
+		if iClone.Annotations == nil {
+			iClone.Annotations = make(map[string]string)
+		}
+		iClone.Annotations[key] = target
+	} else {
+		delete(iClone.Annotations, key)
+	}
+
+	_, err = i.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Update(context.TODO(), iClone, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("ingress %s.%s update error: %w", iClone.Name, iClone.Namespace, err)
+	}
+	return nil
+}
+
+// makeMirrorTarget returns the canary service URL that receives the mirrored requests
+func (i *IngressRouter) makeMirrorTarget(canary *flaggerv1.Canary) string {
+	_, _, canaryName := canary.GetServiceNames()
+	return fmt.Sprintf("http://%s.%s.svc:%d$request_uri", canaryName, canary.Namespace, canary.Spec.Service.Port)
+}
+
 func (i *IngressRouter) makeAnnotations(annotations map[string]string) map[string]string {
 	res := make(map[string]string)
 	for k, v := range filterMetadata(annotations) {
@@ -262,6 +330,39 @@ func (i *IngressRouter) makeHeaderAnnotations(annotations map[string]string,
 	return res
 }
 
+func (i *IngressRouter) makeSessionAffinityAnnotations(annotations map[string]string,
+	sessionAffinity *flaggerv1.SessionAffinity) map[string]string {
+	res := make(map[string]string)
+	for k, v := range annotations {
+		if !strings.Contains(k, i.GetAnnotationWithPrefix("session-cookie")) {
+			res[k] = v
+		}
+	}
+
+	res[i.GetAnnotationWithPrefix("affinity")] = "cookie"
+	res[i.GetAnnotationWithPrefix("affinity-canary-behavior")] = "sticky"
+	res[i.GetAnnotationWithPrefix("session-cookie-name")] = sessionAffinity.CookieName
+	res[i.GetAnnotationWithPrefix("session-cookie-max-age")] = strconv.Itoa(sessionAffinity.GetMaxAge())
+
+	if sessionAffinity.Domain != "" {
+		res[i.GetAnnotationWithPrefix("session-cookie-domain")] = sessionAffinity.Domain
+	}
+
+	if sessionAffinity.Path != "" {
+		res[i.GetAnnotationWithPrefix("session-cookie-path")] = sessionAffinity.Path
+	}
+
+	if sessionAffinity.SameSite != "" {
+		res[i.GetAnnotationWithPrefix("session-cookie-samesite")] = sessionAffinity.SameSite
+	}
+
+	if sessionAffinity.Secure {
+		res[i.GetAnnotationWithPrefix("session-cookie-secure")] = "true"
+	}
+
+	return res
+}
+
 func (i *IngressRouter) GetAnnotationWithPrefix(suffix string) string {
 	return fmt.Sprintf("%v/%v", i.annotationsPrefix, suffix)
 }
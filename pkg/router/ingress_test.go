@@ -183,3 +183,79 @@ func TestIngressRouter_ABTest(t *testing.T) {
 		assert.Equal(t, "test", inCanary.Annotations[table.annotation])
 	}
 }
+
+func TestIngressRouter_SessionAffinity(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IngressRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		annotationsPrefix: "nginx.ingress.kubernetes.io",
+	}
+
+	canary := mocks.ingressCanary.DeepCopy()
+	canary.Spec.Analysis.SessionAffinity = &flaggerv1.SessionAffinity{
+		CookieName: "flagger-cookie",
+		MaxAge:     600,
+		Path:       "/",
+	}
+
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.SetRoutes(canary, 90, 10, false))
+
+	canaryName := fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name)
+	inCanary, err := router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), canaryName, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "10", inCanary.Annotations["nginx.ingress.kubernetes.io/canary-weight"])
+	assert.Equal(t, "cookie", inCanary.Annotations["nginx.ingress.kubernetes.io/affinity"])
+	assert.Equal(t, "sticky", inCanary.Annotations["nginx.ingress.kubernetes.io/affinity-canary-behavior"])
+	assert.Equal(t, "flagger-cookie", inCanary.Annotations["nginx.ingress.kubernetes.io/session-cookie-name"])
+	assert.Equal(t, "600", inCanary.Annotations["nginx.ingress.kubernetes.io/session-cookie-max-age"])
+	assert.Equal(t, "/", inCanary.Annotations["nginx.ingress.kubernetes.io/session-cookie-path"])
+	assert.Equal(t, "flagger-cookie", canary.Status.SessionAffinityCookie)
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 90, p)
+	assert.Equal(t, 10, c)
+
+	require.NoError(t, router.SetRoutes(canary, 100, 0, false))
+	assert.Empty(t, canary.Status.SessionAffinityCookie)
+	assert.Equal(t, "flagger-cookie", canary.Status.PreviousSessionAffinityCookie)
+}
+
+func TestIngressRouter_Mirror(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &IngressRouter{
+		logger:            mocks.logger,
+		kubeClient:        mocks.kubeClient,
+		annotationsPrefix: "nginx.ingress.kubernetes.io",
+	}
+
+	require.NoError(t, router.Reconcile(mocks.ingressCanary))
+	require.NoError(t, router.SetRoutes(mocks.ingressCanary, 100, 0, true))
+
+	// the mirror target is set on the main ingress
+	ingress, err := router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), mocks.ingressCanary.Spec.IngressRef.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "http://podinfo-canary.default.svc:9898$request_uri",
+		ingress.Annotations["nginx.ingress.kubernetes.io/mirror-target"])
+
+	p, c, m, err := router.GetRoutes(mocks.ingressCanary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.True(t, m)
+
+	// stop mirroring
+	require.NoError(t, router.SetRoutes(mocks.ingressCanary, 0, 100, false))
+	ingress, err = router.kubeClient.NetworkingV1().Ingresses("default").Get(context.TODO(), mocks.ingressCanary.Spec.IngressRef.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, ingress.Annotations, "nginx.ingress.kubernetes.io/mirror-target")
+
+	p, c, m, err = router.GetRoutes(mocks.ingressCanary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+	assert.False(t, m)
+}
@@ -52,11 +52,27 @@ func (kr *KnativeRouter) SetRoutes(cd *flaggerv1.Canary, primaryWeight int, cana
 
 	canaryPercent := int64(canaryWeight)
 	primaryPercent := int64(primaryWeight)
+	var canaryTag string
+
+	// A/B testing: the untagged requests are routed to the primary revision and
+	// the requests with the Knative-Serving-Tag header to the tagged latest revision
+	if len(cd.GetAnalysis().Match) > 0 {
+		tag, err := knativeTag(cd.GetAnalysis().Match)
+		if err != nil {
+			return fmt.Errorf("Knative Service %s.%s A/B testing %w", cd.Spec.TargetRef.Name, cd.Namespace, err)
+		}
+		if canaryWeight > 0 {
+			canaryTag = tag
+		}
+		canaryPercent, primaryPercent = 0, 100
+	}
+
 	latestRevision := true
 	traffic := []serving.TrafficTarget{
 		{
 			LatestRevision: &latestRevision,
 			Percent:        &canaryPercent,
+			Tag:            canaryTag,
 		},
 		{
 			RevisionName: primaryName,
@@ -104,6 +120,14 @@ func (kr *KnativeRouter) GetRoutes(cd *flaggerv1.Canary) (primaryWeight int, can
 		return
 	}
 
+	// A/B testing: the canary receives the tagged requests
+	if len(cd.GetAnalysis().Match) > 0 {
+		if service.Status.Traffic[canaryRevisionIdx].Tag != "" {
+			return 0, 100, false, nil
+		}
+		return 100, 0, false, nil
+	}
+
 	return int(*service.Status.Traffic[primaryRevisionIdx].Percent), int(*service.Status.Traffic[canaryRevisionIdx].Percent), false, nil
 }
 
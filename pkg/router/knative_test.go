@@ -8,6 +8,9 @@ import (
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	serving "knative.dev/serving/pkg/apis/serving/v1"
+
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
 )
 
 func TestKnativeRouter_Reconcile(t *testing.T) {
@@ -94,3 +97,45 @@ func TestKnativeRouter_GetRoutes(t *testing.T) {
 	assert.Equal(t, pWeight, 10)
 	assert.Equal(t, cWeight, 90)
 }
+
+func TestKnativeRouter_ABTest(t *testing.T) {
+	canary := newTestKnativeCanary()
+	canary.Spec.Analysis.Iterations = 10
+	canary.Spec.Analysis.Match = []istiov1beta1.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"Knative-Serving-Tag": {Exact: "insider"},
+			},
+		},
+	}
+	mocks := newFixture(canary)
+
+	router := &KnativeRouter{
+		knativeClient: mocks.knativeClient,
+		logger:        mocks.logger,
+	}
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.SetRoutes(canary, 0, 100, false))
+
+	service, err := mocks.knativeClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, service.Spec.Traffic, 2)
+	assert.Equal(t, "insider", service.Spec.Traffic[0].Tag)
+	assert.Equal(t, int64(0), *service.Spec.Traffic[0].Percent)
+	assert.Equal(t, int64(100), *service.Spec.Traffic[1].Percent)
+
+	service.Status.Traffic = service.Spec.Traffic
+	_, err = mocks.knativeClient.ServingV1().Services("default").Update(context.TODO(), service, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	// promotion removes the tag
+	require.NoError(t, router.SetRoutes(canary, 100, 0, false))
+	service, err = mocks.knativeClient.ServingV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, service.Spec.Traffic[0].Tag)
+}
@@ -43,6 +43,7 @@ type KubernetesDefaultRouter struct {
 	labelSelector string
 	labelValue    string
 	ports         map[string]int32
+	provider      string
 }
 
 // Initialize creates the primary and canary services
@@ -50,13 +51,13 @@ func (c *KubernetesDefaultRouter) Initialize(canary *flaggerv1.Canary) error {
 	_, primaryName, canaryName := canary.GetServiceNames()
 
 	// canary svc
-	err := c.reconcileService(canary, canaryName, c.labelValue, canary.Spec.Service.Canary)
+	err := c.reconcileService(canary, canaryName, c.podSelector(c.labelValue), canary.Spec.Service.Canary)
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
 
 	// primary svc
-	err = c.reconcileService(canary, primaryName, fmt.Sprintf("%s-primary", c.labelValue), canary.Spec.Service.Primary)
+	err = c.reconcileService(canary, primaryName, c.podSelector(fmt.Sprintf("%s-primary", c.labelValue)), canary.Spec.Service.Primary)
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
@@ -64,12 +65,26 @@ func (c *KubernetesDefaultRouter) Initialize(canary *flaggerv1.Canary) error {
 	return nil
 }
 
-// Reconcile creates or updates the main service
+// Reconcile creates or updates the main service, the service selects the primary pods
+// unless the canary traffic is shifted with replica ratios during the analysis
 func (c *KubernetesDefaultRouter) Reconcile(canary *flaggerv1.Canary) error {
 	apexName, _, _ := canary.GetServiceNames()
 
+	selector := c.podSelector(fmt.Sprintf("%s-primary", c.labelValue))
+	switch canary.Status.Phase {
+	case flaggerv1.CanaryPhaseProgressing, flaggerv1.CanaryPhaseWaitingPromotion,
+		flaggerv1.CanaryPhasePromoting, flaggerv1.CanaryPhaseFinalising:
+		shared, err := replicaRatioSelector(c.kubeClient, c.provider, canary)
+		if err != nil {
+			return fmt.Errorf("replicaRatioSelector failed: %w", err)
+		}
+		if len(shared) > 0 {
+			selector = shared
+		}
+	}
+
 	// main svc
-	err := c.reconcileService(canary, apexName, fmt.Sprintf("%s-primary", c.labelValue), canary.Spec.Service.Apex)
+	err := c.reconcileService(canary, apexName, selector, canary.Spec.Service.Apex)
 	if err != nil {
 		return fmt.Errorf("reconcileService failed: %w", err)
 	}
@@ -85,7 +100,11 @@ func (c *KubernetesDefaultRouter) GetRoutes(_ *flaggerv1.Canary) (primaryRoute i
 	return 0, 0, nil
 }
 
-func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, name string, podSelector string, metadata *flaggerv1.CustomMetadata) error {
+func (c *KubernetesDefaultRouter) podSelector(value string) map[string]string {
+	return map[string]string{c.labelSelector: value}
+}
+
+func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, name string, podSelector map[string]string, metadata *flaggerv1.CustomMetadata) error {
 	portName := canary.Spec.Service.PortName
 	if portName == "" {
 		portName = "http"
@@ -103,7 +122,7 @@ func (c *KubernetesDefaultRouter) reconcileService(canary *flaggerv1.Canary, nam
 	// set pod selector and apex port
 	svcSpec := corev1.ServiceSpec{
 		Type:     corev1.ServiceTypeClusterIP,
-		Selector: map[string]string{c.labelSelector: podSelector},
+		Selector: podSelector,
 		Ports: []corev1.ServicePort{
 			{
 				Name:       portName,
@@ -299,7 +318,7 @@ func (c *KubernetesDefaultRouter) Finalize(canary *flaggerv1.Canary) error {
 				return fmt.Errorf("service %s update error: %w", clone.Name, err)
 			}
 		} else {
-			err = c.reconcileService(canary, apexName, c.labelValue, nil)
+			err = c.reconcileService(canary, apexName, c.podSelector(c.labelValue), nil)
 			if err != nil {
 				return fmt.Errorf("reconcileService failed: %w", err)
 			}
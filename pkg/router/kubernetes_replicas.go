@@ -0,0 +1,171 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// KubernetesReplicaRouter approximates the traffic split for the kubernetes provider
+// by scaling the canary deployment in proportion to the primary replicas,
+// the apex service selects the pods of both deployments by the apex label during the analysis
+type KubernetesReplicaRouter struct {
+	kubeClient kubernetes.Interface
+	logger     *zap.SugaredLogger
+}
+
+// UsesReplicaRatio returns true if the canary traffic is shifted by scaling the canary deployment,
+// this requires the kubernetes provider, the canary strategy, the replicaRatio opt-in
+// and a primary deployment created or promoted with the apex label
+func UsesReplicaRatio(kubeClient kubernetes.Interface, provider string, canary *flaggerv1.Canary) (bool, error) {
+	selector, err := replicaRatioSelector(kubeClient, provider, canary)
+	if err != nil {
+		return false, err
+	}
+	return len(selector) > 0, nil
+}
+
+// Reconcile keeps the replica ratio in sync with the canary weight while the primary is being autoscaled
+func (kr *KubernetesReplicaRouter) Reconcile(canary *flaggerv1.Canary) error {
+	if canary.Status.Phase != flaggerv1.CanaryPhaseProgressing || canary.Status.CanaryWeight == 0 {
+		return nil
+	}
+	return kr.SetRoutes(canary, 100-canary.Status.CanaryWeight, canary.Status.CanaryWeight, false)
+}
+
+// SetRoutes scales the canary deployment to match the canary weight
+func (kr *KubernetesReplicaRouter) SetRoutes(canary *flaggerv1.Canary, primaryWeight int, canaryWeight int, _ bool) error {
+	ok, err := UsesReplicaRatio(kr.kubeClient, flaggerv1.KubernetesProvider, canary)
+	if err != nil || !ok {
+		return err
+	}
+
+	targetName := canary.Spec.TargetRef.Name
+	primaryName := fmt.Sprintf("%s-primary", targetName)
+	primary, err := kr.kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", primaryName, canary.Namespace, err)
+	}
+	target, err := kr.kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.TODO(), targetName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s get query error: %w", targetName, canary.Namespace, err)
+	}
+
+	primaryReplicas := int32(1)
+	if primary.Spec.Replicas != nil {
+		primaryReplicas = *primary.Spec.Replicas
+	}
+	replicas := canaryReplicas(primaryReplicas, primaryWeight, canaryWeight)
+	apexName, _, _ := canary.GetServiceNames()
+	hasApexLabel := target.Spec.Template.Labels[flaggerv1.ApexLabel] == apexName
+	if hasApexLabel && target.Spec.Replicas != nil && *target.Spec.Replicas == replicas {
+		return nil
+	}
+
+	// the canary pods are selected by the apex service once they carry the apex label
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas": %d}}`, replicas))
+	if !hasApexLabel {
+		patch = []byte(fmt.Sprintf(`{"spec":{"replicas": %d,"template":{"metadata":{"labels":{%q:%q}}}}}`,
+			replicas, flaggerv1.ApexLabel, apexName))
+	}
+	_, err = kr.kubeClient.AppsV1().Deployments(canary.Namespace).Patch(context.TODO(), targetName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("deployment %s.%s patch query error: %w", targetName, canary.Namespace, err)
+	}
+
+	kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Debugf("Deployment %s.%s scaled to %d replicas for canary weight %d", targetName, canary.Namespace, replicas, canaryWeight)
+	return nil
+}
+
+// GetRoutes returns the canary weight recorded in status when the traffic is shifted with replica ratios,
+// otherwise it behaves like the NopRouter used for blue/green deployments
+func (kr *KubernetesReplicaRouter) GetRoutes(canary *flaggerv1.Canary) (primaryWeight int, canaryWeight int, mirrored bool, err error) {
+	ok, err := UsesReplicaRatio(kr.kubeClient, flaggerv1.KubernetesProvider, canary)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	if !ok {
+		if canary.Status.Iterations > 0 {
+			return 0, 100, false, nil
+		}
+		return 100, 0, false, nil
+	}
+
+	return 100 - canary.Status.CanaryWeight, canary.Status.CanaryWeight, false, nil
+}
+
+// Finalize is a no-op, the apex service is reverted by the Kubernetes router
+func (kr *KubernetesReplicaRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+// canaryReplicas returns the number of canary pods that receive the canary weight
+// of the apex service traffic, next to the primary pods. A single canary pod is kept
+// at zero weight so that the canary service can be tested by the pre-rollout hooks,
+// and the canary is never scaled above the primary replicas.
+func canaryReplicas(primaryReplicas int32, primaryWeight int, canaryWeight int) int32 {
+	if canaryWeight <= 0 || primaryReplicas < 1 {
+		return 1
+	}
+	if primaryWeight <= 0 || canaryWeight >= primaryWeight {
+		return primaryReplicas
+	}
+
+	replicas := int32(math.Ceil(float64(primaryReplicas) * float64(canaryWeight) / float64(primaryWeight)))
+	if replicas > primaryReplicas {
+		replicas = primaryReplicas
+	}
+	return replicas
+}
+
+// replicaRatioSelector returns the apex label selector that matches the primary and canary pods.
+// The result is empty if the canary traffic can't be shifted by scaling the canary deployment,
+// the primary gets the apex label when it's created or promoted with the replicaRatio opt-in.
+func replicaRatioSelector(kubeClient kubernetes.Interface, provider string, canary *flaggerv1.Canary) (map[string]string, error) {
+	if provider != flaggerv1.KubernetesProvider ||
+		canary.Spec.TargetRef.Kind != "Deployment" ||
+		canary.DeploymentStrategy() != flaggerv1.DeploymentStrategyCanary ||
+		!canary.UsesReplicaRatio() {
+		return nil, nil
+	}
+
+	primaryName := fmt.Sprintf("%s-primary", canary.Spec.TargetRef.Name)
+	primary, err := kubeClient.AppsV1().Deployments(canary.Namespace).Get(context.TODO(), primaryName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("deployment %s.%s get query error: %w", primaryName, canary.Namespace, err)
+	}
+
+	apexName, _, _ := canary.GetServiceNames()
+	if primary.Spec.Template.Labels[flaggerv1.ApexLabel] != apexName {
+		return nil, nil
+	}
+	return map[string]string{flaggerv1.ApexLabel: apexName}, nil
+}
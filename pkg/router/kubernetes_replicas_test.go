@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func newReplicaRatioFixture(t *testing.T, replicaRatio bool) fixture {
+	mocks := newFixture(nil)
+	mocks.canary.Spec.Analysis = &flaggerv1.CanaryAnalysis{ReplicaRatio: replicaRatio}
+
+	canaryDep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	primaryDep := canaryDep.DeepCopy()
+	primaryDep.ObjectMeta = metav1.ObjectMeta{Name: "podinfo-primary", Namespace: "default"}
+	replicas := int32(4)
+	primaryDep.Spec.Replicas = &replicas
+	primaryDep.Spec.Template.Labels["app"] = "podinfo-primary"
+	primaryDep.Spec.Template.Labels[flaggerv1.ApexLabel] = "podinfo"
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Create(context.TODO(), primaryDep, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	return mocks
+}
+
+func TestKubernetesReplicaRouter_SetRoutes(t *testing.T) {
+	mocks := newReplicaRatioFixture(t, true)
+	router := &KubernetesReplicaRouter{
+		kubeClient: mocks.kubeClient,
+		logger:     mocks.logger,
+	}
+
+	tests := []struct {
+		primaryWeight int
+		canaryWeight  int
+		replicas      int32
+	}{
+		{primaryWeight: 100, canaryWeight: 0, replicas: 1},
+		{primaryWeight: 80, canaryWeight: 20, replicas: 1},
+		{primaryWeight: 60, canaryWeight: 40, replicas: 3},
+		{primaryWeight: 50, canaryWeight: 50, replicas: 4},
+		{primaryWeight: 0, canaryWeight: 100, replicas: 4},
+	}
+
+	for _, tt := range tests {
+		err := router.SetRoutes(mocks.canary, tt.primaryWeight, tt.canaryWeight, false)
+		require.NoError(t, err)
+
+		dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, tt.replicas, *dep.Spec.Replicas, "canary weight %d", tt.canaryWeight)
+		assert.Equal(t, "podinfo", dep.Spec.Template.Labels[flaggerv1.ApexLabel])
+	}
+
+	mocks.canary.Status.CanaryWeight = 40
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+	assert.False(t, m)
+}
+
+func TestKubernetesReplicaRouter_BlueGreen(t *testing.T) {
+	mocks := newReplicaRatioFixture(t, false)
+	router := &KubernetesReplicaRouter{
+		kubeClient: mocks.kubeClient,
+		logger:     mocks.logger,
+	}
+
+	ok, err := UsesReplicaRatio(mocks.kubeClient, flaggerv1.KubernetesProvider, mocks.canary)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// the primary must carry the apex label
+	mocks.canary.Spec.Analysis.ReplicaRatio = true
+	primary, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo-primary", metav1.GetOptions{})
+	require.NoError(t, err)
+	delete(primary.Spec.Template.Labels, flaggerv1.ApexLabel)
+	_, err = mocks.kubeClient.AppsV1().Deployments("default").Update(context.TODO(), primary, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	ok, err = UsesReplicaRatio(mocks.kubeClient, flaggerv1.KubernetesProvider, mocks.canary)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	err = router.SetRoutes(mocks.canary, 50, 50, false)
+	require.NoError(t, err)
+
+	dep, err := mocks.kubeClient.AppsV1().Deployments("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, dep.Spec.Replicas)
+
+	mocks.canary.Status.Iterations = 1
+	p, c, _, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+}
+
+func TestServiceRouter_ReplicaRatioSelector(t *testing.T) {
+	mocks := newReplicaRatioFixture(t, true)
+	router := &KubernetesDefaultRouter{
+		kubeClient:    mocks.kubeClient,
+		flaggerClient: mocks.flaggerClient,
+		logger:        mocks.logger,
+		labelSelector: "app",
+		labelValue:    "podinfo",
+		provider:      flaggerv1.KubernetesProvider,
+	}
+
+	mocks.canary.Status.Phase = flaggerv1.CanaryPhaseInitialized
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	apex, err := mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "podinfo-primary"}, apex.Spec.Selector)
+
+	mocks.canary.Status.Phase = flaggerv1.CanaryPhaseProgressing
+	require.NoError(t, router.Reconcile(mocks.canary))
+
+	apex, err = mocks.kubeClient.CoreV1().Services("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{flaggerv1.ApexLabel: "podinfo"}, apex.Spec.Selector)
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
@@ -14,6 +15,7 @@ import (
 
 	"go.uber.org/zap"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
 
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 )
@@ -41,7 +43,7 @@ func (kr *KumaRouter) Reconcile(canary *flaggerv1.Canary) error {
 		Destinations: []*kumav1alpha1.Selector{
 			{
 				Match: map[string]string{
-					"kuma.io/service": fmt.Sprintf("%s_%s_svc_%d", apexName, canary.Namespace, canary.Spec.Service.Port),
+					"kuma.io/service": kr.makeServiceName(canary, apexName),
 				},
 			},
 		},
@@ -50,13 +52,13 @@ func (kr *KumaRouter) Reconcile(canary *flaggerv1.Canary) error {
 				{
 					Weight: uint32(100),
 					Destination: map[string]string{
-						"kuma.io/service": fmt.Sprintf("%s_%s_svc_%d", primaryName, canary.Namespace, canary.Spec.Service.Port),
+						"kuma.io/service": kr.makeServiceName(canary, primaryName),
 					},
 				},
 				{
 					Weight: uint32(0),
 					Destination: map[string]string{
-						"kuma.io/service": fmt.Sprintf("%s_%s_svc_%d", canaryName, canary.Namespace, canary.Spec.Service.Port),
+						"kuma.io/service": kr.makeServiceName(canary, canaryName),
 					},
 				},
 			},
@@ -161,8 +163,7 @@ func (kr *KumaRouter) GetRoutes(canary *flaggerv1.Canary) (
 			apexName, primaryName, canaryName)
 	}
 
-	mirrored = false
-
+	mirrored, err = kr.isMirrored(canary)
 	return
 }
 
@@ -171,9 +172,14 @@ func (kr *KumaRouter) SetRoutes(
 	canary *flaggerv1.Canary,
 	primaryWeight int,
 	canaryWeight int,
-	_ bool,
+	mirrored bool,
 ) error {
 	apexName, primaryName, canaryName := canary.GetServiceNames()
+
+	if err := kr.setMirror(canary, mirrored); err != nil {
+		return err
+	}
+
 	tr, err := kr.kumaClient.KumaV1alpha1().TrafficRoutes().Get(context.TODO(), apexName, metav1.GetOptions{})
 
 	if err != nil {
@@ -185,13 +191,13 @@ func (kr *KumaRouter) SetRoutes(
 			{
 				Weight: uint32(primaryWeight),
 				Destination: map[string]string{
-					"kuma.io/service": fmt.Sprintf("%s_%s_svc_%d", primaryName, canary.Namespace, canary.Spec.Service.Port),
+					"kuma.io/service": kr.makeServiceName(canary, primaryName),
 				},
 			},
 			{
 				Weight: uint32(canaryWeight),
 				Destination: map[string]string{
-					"kuma.io/service": fmt.Sprintf("%s_%s_svc_%d", canaryName, canary.Namespace, canary.Spec.Service.Port),
+					"kuma.io/service": kr.makeServiceName(canary, canaryName),
 				},
 			},
 		},
@@ -208,6 +214,140 @@ func (kr *KumaRouter) SetRoutes(
 	return nil
 }
 
-func (kr *KumaRouter) Finalize(_ *flaggerv1.Canary) error {
+// setMirror creates the MeshHTTPRoute that sends the traffic to the primary and mirrors it
+// to the canary, TrafficRoute doesn't support mirroring. The route is deleted when the
+// mirroring ends, the TrafficRoute takes over the routing of the apex service.
+func (kr *KumaRouter) setMirror(canary *flaggerv1.Canary, mirrored bool) error {
+	if !mirrored {
+		return kr.deleteMirrorRoute(canary)
+	}
+
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	meshName, ok := canary.Annotations["kuma.io/mesh"]
+	if !ok {
+		meshName = "default"
+	}
+
+	var percentage *string
+	if mw := canary.GetAnalysis().MirrorWeight; mw > 0 {
+		percentage = ptr.To(strconv.Itoa(mw))
+	}
+
+	spec := kumav1alpha1.MeshHTTPRouteSpec{
+		TargetRef: kumav1alpha1.TargetRef{
+			Kind: "Mesh",
+		},
+		To: []kumav1alpha1.MeshHTTPRouteTo{
+			{
+				TargetRef: kumav1alpha1.TargetRef{
+					Kind: "MeshService",
+					Name: kr.makeServiceName(canary, apexName),
+				},
+				Rules: []kumav1alpha1.MeshHTTPRouteRule{
+					{
+						Matches: []kumav1alpha1.MeshHTTPRouteMatch{
+							{
+								Path: &kumav1alpha1.PathMatch{
+									Type:  "PathPrefix",
+									Value: "/",
+								},
+							},
+						},
+						Default: kumav1alpha1.MeshHTTPRouteConf{
+							Filters: []kumav1alpha1.MeshHTTPRouteFilter{
+								{
+									Type: "RequestMirror",
+									RequestMirror: &kumav1alpha1.RequestMirror{
+										BackendRef: kumav1alpha1.BackendRef{
+											TargetRef: kumav1alpha1.TargetRef{
+												Kind: "MeshService",
+												Name: kr.makeServiceName(canary, canaryName),
+											},
+										},
+										Percentage: percentage,
+									},
+								},
+							},
+							BackendRefs: []kumav1alpha1.BackendRef{
+								{
+									TargetRef: kumav1alpha1.TargetRef{
+										Kind: "MeshService",
+										Name: kr.makeServiceName(canary, primaryName),
+									},
+									Weight: ptr.To(uint32(100)),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	routeName := fmt.Sprintf("%s-mirror", apexName)
+	route, err := kr.kumaClient.KumaV1alpha1().MeshHTTPRoutes(canary.Namespace).Get(context.TODO(), routeName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		route = &kumav1alpha1.MeshHTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      routeName,
+				Namespace: canary.Namespace,
+				Labels: map[string]string{
+					"kuma.io/mesh": meshName,
+				},
+			},
+			Spec: spec,
+		}
+		_, err = kr.kumaClient.KumaV1alpha1().MeshHTTPRoutes(canary.Namespace).Create(context.TODO(), route, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("MeshHTTPRoute %s.%s create error: %w", routeName, canary.Namespace, err)
+		}
+		kr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("MeshHTTPRoute %s.%s created", routeName, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("MeshHTTPRoute %s.%s get query error: %w", routeName, canary.Namespace, err)
+	}
+
+	if diff := cmp.Diff(spec, route.Spec); diff != "" {
+		routeClone := route.DeepCopy()
+		routeClone.Spec = spec
+		_, err = kr.kumaClient.KumaV1alpha1().MeshHTTPRoutes(canary.Namespace).Update(context.TODO(), routeClone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("MeshHTTPRoute %s.%s update error: %w", routeName, canary.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// isMirrored returns true if the MeshHTTPRoute mirroring the traffic to the canary exists
+func (kr *KumaRouter) isMirrored(canary *flaggerv1.Canary) (bool, error) {
+	apexName, _, _ := canary.GetServiceNames()
+	routeName := fmt.Sprintf("%s-mirror", apexName)
+	_, err := kr.kumaClient.KumaV1alpha1().MeshHTTPRoutes(canary.Namespace).Get(context.TODO(), routeName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("MeshHTTPRoute %s.%s get query error: %w", routeName, canary.Namespace, err)
+	}
+	return true, nil
+}
+
+func (kr *KumaRouter) deleteMirrorRoute(canary *flaggerv1.Canary) error {
+	apexName, _, _ := canary.GetServiceNames()
+	routeName := fmt.Sprintf("%s-mirror", apexName)
+	err := kr.kumaClient.KumaV1alpha1().MeshHTTPRoutes(canary.Namespace).Delete(context.TODO(), routeName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("MeshHTTPRoute %s.%s delete error: %w", routeName, canary.Namespace, err)
+	}
 	return nil
 }
+
+// makeServiceName returns the kuma.io/service tag of a Kubernetes service
+func (kr *KumaRouter) makeServiceName(canary *flaggerv1.Canary, name string) string {
+	return fmt.Sprintf("%s_%s_svc_%d", name, canary.Namespace, canary.Spec.Service.Port)
+}
+
+// Finalize deletes the MeshHTTPRoute used for mirroring
+func (kr *KumaRouter) Finalize(canary *flaggerv1.Canary) error {
+	return kr.deleteMirrorRoute(canary)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -76,3 +77,43 @@ func TestKumaRouter_Routes(t *testing.T) {
 	assert.Equal(t, uint32(50), primary.Weight)
 
 }
+
+func TestKumaRouter_Mirror(t *testing.T) {
+	canary := newTestSMICanary()
+	canary.Spec.Analysis.MirrorWeight = 50
+	mocks := newFixture(canary)
+	router := &KumaRouter{
+		logger:        mocks.logger,
+		flaggerClient: mocks.flaggerClient,
+		kumaClient:    mocks.meshClient,
+		kubeClient:    mocks.kubeClient,
+	}
+
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.SetRoutes(canary, 100, 0, true))
+
+	route, err := router.kumaClient.KumaV1alpha1().MeshHTTPRoutes("default").Get(context.TODO(), "podinfo-mirror", metav1.GetOptions{})
+	require.NoError(t, err)
+	conf := route.Spec.To[0].Rules[0].Default
+	assert.Equal(t, "podinfo_default_svc_80", route.Spec.To[0].TargetRef.Name)
+	assert.Equal(t, "podinfo-primary_default_svc_80", conf.BackendRefs[0].Name)
+	assert.Equal(t, "RequestMirror", conf.Filters[0].Type)
+	assert.Equal(t, "podinfo-canary_default_svc_80", conf.Filters[0].RequestMirror.BackendRef.Name)
+	assert.Equal(t, "50", *conf.Filters[0].RequestMirror.Percentage)
+
+	p, c, m, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.True(t, m)
+
+	// stop mirroring
+	require.NoError(t, router.SetRoutes(canary, 0, 100, false))
+	_, err = router.kumaClient.KumaV1alpha1().MeshHTTPRoutes("default").Get(context.TODO(), "podinfo-mirror", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	_, c, m, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, c)
+	assert.False(t, m)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	"github.com/fluxcd/flagger/pkg/apis/gatewayapi/v1beta1"
+	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
+)
+
+// LinkerdRouter manages the weighted backends of a Gateway API HTTPRoute
+// attached to the apex service, Linkerd applies the route to the meshed clients
+type LinkerdRouter struct {
+	kubeClient       kubernetes.Interface
+	gatewayAPIClient clientset.Interface
+	smiClient        clientset.Interface
+	logger           *zap.SugaredLogger
+	setOwnerRefs     bool
+}
+
+// Reconcile creates or updates the HTTPRoute and migrates the SMI traffic split if one exists
+func (lr *LinkerdRouter) Reconcile(canary *flaggerv1.Canary) error {
+	meshCanary := withServiceParent(canary)
+	if err := lr.gatewayAPIRouter().Reconcile(meshCanary); err != nil {
+		return err
+	}
+	return lr.migrateTrafficSplit(meshCanary)
+}
+
+// GetRoutes returns the destinations weight for primary and canary
+func (lr *LinkerdRouter) GetRoutes(canary *flaggerv1.Canary) (
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+	err error,
+) {
+	return lr.gatewayAPIRouter().GetRoutes(withServiceParent(canary))
+}
+
+// SetRoutes updates the destinations weight for primary and canary
+func (lr *LinkerdRouter) SetRoutes(
+	canary *flaggerv1.Canary,
+	primaryWeight int,
+	canaryWeight int,
+	mirrored bool,
+) error {
+	return lr.gatewayAPIRouter().SetRoutes(withServiceParent(canary), primaryWeight, canaryWeight, mirrored)
+}
+
+func (lr *LinkerdRouter) Finalize(_ *flaggerv1.Canary) error {
+	return nil
+}
+
+func (lr *LinkerdRouter) gatewayAPIRouter() *GatewayAPIRouter {
+	return &GatewayAPIRouter{
+		gatewayAPIClient: lr.gatewayAPIClient,
+		kubeClient:       lr.kubeClient,
+		logger:           lr.logger,
+		setOwnerRefs:     lr.setOwnerRefs,
+	}
+}
+
+// migrateTrafficSplit copies the weights of the TrafficSplit created by the SMI router
+// to the HTTPRoute and deletes the TrafficSplit, so that an analysis in progress
+// continues from the current weights
+func (lr *LinkerdRouter) migrateTrafficSplit(canary *flaggerv1.Canary) error {
+	apexName, primaryName, canaryName := canary.GetServiceNames()
+	ts, err := lr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("TrafficSplit %s.%s get query error: %w", apexName, canary.Namespace, err)
+	}
+
+	var primaryWeight, canaryWeight int
+	for _, backend := range ts.Spec.Backends {
+		w, _ := backend.Weight.AsInt64()
+		if backend.Service == primaryName {
+			primaryWeight = int(w)
+		}
+		if backend.Service == canaryName {
+			canaryWeight = int(w)
+		}
+	}
+	if canaryWeight > 0 {
+		if err := lr.gatewayAPIRouter().SetRoutes(canary, primaryWeight, canaryWeight, false); err != nil {
+			return err
+		}
+	}
+
+	err = lr.smiClient.SplitV1alpha1().TrafficSplits(canary.Namespace).Delete(context.TODO(), apexName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("TrafficSplit %s.%s delete error: %w", apexName, canary.Namespace, err)
+	}
+
+	lr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+		Infof("TrafficSplit %s.%s migrated to HTTPRoute", apexName, canary.Namespace)
+	return nil
+}
+
+// withServiceParent returns a copy of the canary with the apex service as the HTTPRoute parent,
+// the hosts are removed since Linkerd matches the requests by the parent service
+func withServiceParent(canary *flaggerv1.Canary) *flaggerv1.Canary {
+	apexName, _, _ := canary.GetServiceNames()
+	group := v1beta1.Group("core")
+	kind := v1beta1.Kind("Service")
+	port := v1beta1.PortNumber(canary.Spec.Service.Port)
+
+	meshCanary := canary.DeepCopy()
+	meshCanary.Spec.Service.GatewayRefs = []v1beta1.ParentReference{
+		{
+			Group: &group,
+			Kind:  &kind,
+			Name:  v1beta1.ObjectName(apexName),
+			Port:  &port,
+		},
+	}
+	meshCanary.Spec.Service.Hosts = nil
+	return meshCanary
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	smiv1 "github.com/fluxcd/flagger/pkg/apis/smi/v1alpha1"
+)
+
+func TestLinkerdRouter_Reconcile(t *testing.T) {
+	canary := newTestSMICanary()
+	canary.Spec.Service.Hosts = []string{"podinfo.example.com"}
+	mocks := newFixture(canary)
+	router := &LinkerdRouter{
+		logger:           mocks.logger,
+		kubeClient:       mocks.kubeClient,
+		gatewayAPIClient: mocks.meshClient,
+		smiClient:        mocks.meshClient,
+	}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	httpRoute, err := mocks.meshClient.GatewayapiV1().HTTPRoutes("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, httpRoute.Spec.ParentRefs, 1)
+	parentRef := httpRoute.Spec.ParentRefs[0]
+	assert.Equal(t, "core", string(*parentRef.Group))
+	assert.Equal(t, "Service", string(*parentRef.Kind))
+	assert.Equal(t, "podinfo", string(parentRef.Name))
+	assert.Equal(t, int32(80), int32(*parentRef.Port))
+	assert.Empty(t, httpRoute.Spec.Hostnames)
+
+	backendRefs := httpRoute.Spec.Rules[0].BackendRefs
+	require.Len(t, backendRefs, 2)
+	assert.Equal(t, "podinfo-primary", string(backendRefs[0].Name))
+	assert.Equal(t, int32(100), *backendRefs[0].Weight)
+	assert.Equal(t, "podinfo-canary", string(backendRefs[1].Name))
+	assert.Equal(t, int32(0), *backendRefs[1].Weight)
+
+	// the canary is not modified
+	assert.Empty(t, canary.Spec.Service.GatewayRefs)
+}
+
+func TestLinkerdRouter_Routes(t *testing.T) {
+	canary := newTestSMICanary()
+	mocks := newFixture(canary)
+	router := &LinkerdRouter{
+		logger:           mocks.logger,
+		kubeClient:       mocks.kubeClient,
+		gatewayAPIClient: mocks.meshClient,
+		smiClient:        mocks.meshClient,
+	}
+
+	err := router.Reconcile(canary)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(canary, 70, 30, false)
+	require.NoError(t, err)
+
+	p, c, m, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 70, p)
+	assert.Equal(t, 30, c)
+	assert.False(t, m)
+}
+
+func TestLinkerdRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &LinkerdRouter{
+		logger:           mocks.logger,
+		kubeClient:       mocks.kubeClient,
+		gatewayAPIClient: mocks.meshClient,
+		smiClient:        mocks.meshClient,
+	}
+
+	err := router.Reconcile(mocks.abtest)
+	require.NoError(t, err)
+
+	err = router.SetRoutes(mocks.abtest, 0, 100, false)
+	require.NoError(t, err)
+
+	httpRoute, err := mocks.meshClient.GatewayapiV1().HTTPRoutes("default").Get(context.TODO(), "abtest", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	rules := httpRoute.Spec.Rules
+	require.Len(t, rules, 2)
+	require.Len(t, rules[0].Matches[0].Headers, 1)
+	assert.Equal(t, "x-user-type", string(rules[0].Matches[0].Headers[0].Name))
+	assert.Equal(t, "test", rules[0].Matches[0].Headers[0].Value)
+	assert.Equal(t, int32(100), *rules[0].BackendRefs[1].Weight)
+	require.Len(t, rules[1].BackendRefs, 1)
+	assert.Equal(t, "abtest-primary", string(rules[1].BackendRefs[0].Name))
+}
+
+func TestLinkerdRouter_MigrateTrafficSplit(t *testing.T) {
+	canary := newTestSMICanary()
+	mocks := newFixture(canary)
+
+	_, err := mocks.meshClient.SplitV1alpha1().TrafficSplits("default").Create(context.TODO(), &smiv1.TrafficSplit{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: smiv1.TrafficSplitSpec{
+			Service: "podinfo",
+			Backends: []smiv1.TrafficSplitBackend{
+				{Service: "podinfo-canary", Weight: resource.NewQuantity(40, resource.DecimalExponent)},
+				{Service: "podinfo-primary", Weight: resource.NewQuantity(60, resource.DecimalExponent)},
+			},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	router := &LinkerdRouter{
+		logger:           mocks.logger,
+		kubeClient:       mocks.kubeClient,
+		gatewayAPIClient: mocks.meshClient,
+		smiClient:        mocks.meshClient,
+	}
+
+	err = router.Reconcile(canary)
+	require.NoError(t, err)
+
+	_, err = mocks.meshClient.SplitV1alpha1().TrafficSplits("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 60, p)
+	assert.Equal(t, 40, c)
+}
+
+func TestFactory_LinkerdRouter(t *testing.T) {
+	mocks := newFixture(nil)
+
+	factory := NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, nil, "", "", mocks.logger, mocks.meshClient, true, false)
+	assert.IsType(t, &SmiRouter{}, factory.MeshRouter(flaggerv1.LinkerdProvider, ""))
+
+	factory = NewFactory(nil, mocks.kubeClient, mocks.flaggerClient, nil, "", "", mocks.logger, mocks.meshClient, true, true)
+	assert.IsType(t, &LinkerdRouter{}, factory.MeshRouter(flaggerv1.LinkerdProvider, ""))
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+// ValidateSessionAffinity returns an error if the session affinity settings
+// are not supported by the provider
+func ValidateSessionAffinity(provider string, canary *flaggerv1.Canary) error {
+	analysis := canary.GetAnalysis()
+	if analysis == nil || analysis.SessionAffinity == nil || analysis.SessionAffinity.PrimaryCookieName == "" {
+		return nil
+	}
+
+	switch provider {
+	case flaggerv1.NGINXProvider, flaggerv1.TraefikProvider, flaggerv1.ApisixProvider:
+		return fmt.Errorf("session affinity with the %s provider doesn't support primaryCookieName", provider)
+	}
+	return nil
+}
+
+// hasSessionAffinity returns true if the canary run uses cookie based session affinity,
+// A/B testing takes precedence over session affinity
+func hasSessionAffinity(canary *flaggerv1.Canary) bool {
+	return canary.Spec.Analysis != nil &&
+		canary.Spec.Analysis.SessionAffinity != nil &&
+		len(canary.GetAnalysis().Match) == 0
+}
+
+// sessionAffinityCookies returns the canary and primary cookies of the current canary run
+// and records them in the canary status. When the canary weight is zero, the canary cookie
+// becomes the previous cookie that the routers have to expire.
+func sessionAffinityCookies(canary *flaggerv1.Canary, canaryWeight int) (canaryCookie, primaryCookie, previousCookie string) {
+	sessionAffinity := canary.Spec.Analysis.SessionAffinity
+	if canaryWeight != 0 {
+		if canary.Status.SessionAffinityCookie == "" {
+			canary.Status.SessionAffinityCookie = fmt.Sprintf("%s=%s", sessionAffinity.CookieName, randSeq())
+		}
+		if sessionAffinity.PrimaryCookieName != "" {
+			if canary.Status.PrimarySessionAffinityCookie == "" {
+				canary.Status.PrimarySessionAffinityCookie = fmt.Sprintf("%s=%s", sessionAffinity.PrimaryCookieName, randSeq())
+			}
+			primaryCookie = canary.Status.PrimarySessionAffinityCookie
+		}
+		return canary.Status.SessionAffinityCookie, primaryCookie, ""
+	}
+
+	if canary.Status.SessionAffinityCookie != "" {
+		canary.Status.PreviousSessionAffinityCookie = canary.Status.SessionAffinityCookie
+	}
+	canary.Status.SessionAffinityCookie = ""
+	return "", "", canary.Status.PreviousSessionAffinityCookie
+}
+
+// trackSessionAffinityCookieName records the name of the cookie issued by the proxy in the
+// canary status, for the routers that rely on the proxy's sticky sessions
+func trackSessionAffinityCookieName(canary *flaggerv1.Canary, canaryWeight int) {
+	if canaryWeight != 0 {
+		canary.Status.SessionAffinityCookie = canary.Spec.Analysis.SessionAffinity.CookieName
+		return
+	}
+
+	if canary.Status.SessionAffinityCookie != "" {
+		canary.Status.PreviousSessionAffinityCookie = canary.Status.SessionAffinityCookie
+	}
+	canary.Status.SessionAffinityCookie = ""
+}
+
+// primaryCookieMaxAge returns the max age of the primary cookie, the clients are pinned
+// to the primary for a single analysis interval
+func primaryCookieMaxAge(canary *flaggerv1.Canary) (int, error) {
+	interval, err := time.ParseDuration(canary.Spec.Analysis.Interval)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse canary interval: %w", err)
+	}
+	return int(interval.Seconds()), nil
+}
+
+// expiredCookie returns the Set-Cookie value that deletes the cookie from the client
+func expiredCookie(cookie string) string {
+	return fmt.Sprintf("%s; %s=%d", cookie, maxAgeAttr, -1)
+}
+
+// splitCookie returns the name and value of a cookie formatted as name=value
+func splitCookie(cookie string) (name, value string) {
+	name, value, _ = strings.Cut(cookie, "=")
+	return
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+)
+
+func TestValidateSessionAffinity(t *testing.T) {
+	tests := []struct {
+		name              string
+		provider          string
+		primaryCookieName string
+		wantErr           bool
+	}{
+		{name: "istio", provider: flaggerv1.IstioProvider, primaryCookieName: "primary-cookie"},
+		{name: "contour", provider: flaggerv1.ContourProvider, primaryCookieName: "primary-cookie"},
+		{name: "nginx", provider: flaggerv1.NGINXProvider},
+		{name: "nginx primary cookie", provider: flaggerv1.NGINXProvider, primaryCookieName: "primary-cookie", wantErr: true},
+		{name: "traefik primary cookie", provider: flaggerv1.TraefikProvider, primaryCookieName: "primary-cookie", wantErr: true},
+		{name: "apisix primary cookie", provider: flaggerv1.ApisixProvider, primaryCookieName: "primary-cookie", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			canary := newTestCanary()
+			canary.Spec.Analysis.SessionAffinity = &flaggerv1.SessionAffinity{
+				CookieName:        "flagger-cookie",
+				PrimaryCookieName: tt.primaryCookieName,
+			}
+			err := ValidateSessionAffinity(tt.provider, canary)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSessionAffinityCookies(t *testing.T) {
+	canary := newTestCanary()
+	canary.Spec.Analysis.SessionAffinity = &flaggerv1.SessionAffinity{
+		CookieName: "flagger-cookie",
+	}
+
+	canaryCookie, primaryCookie, previousCookie := sessionAffinityCookies(canary, 10)
+	name, value := splitCookie(canaryCookie)
+	assert.Equal(t, "flagger-cookie", name)
+	assert.Len(t, value, 10)
+	assert.Empty(t, primaryCookie)
+	assert.Empty(t, previousCookie)
+
+	// the cookie is kept for the whole canary run
+	next, _, _ := sessionAffinityCookies(canary, 20)
+	assert.Equal(t, canaryCookie, next)
+
+	_, _, previousCookie = sessionAffinityCookies(canary, 0)
+	assert.Equal(t, canaryCookie, previousCookie)
+	assert.Empty(t, canary.Status.SessionAffinityCookie)
+	assert.Equal(t, "flagger-cookie="+value+"; Max-Age=-1", expiredCookie(previousCookie))
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/google/go-cmp/cmp"
@@ -30,6 +31,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
 )
 
 /*
@@ -186,7 +188,21 @@ func (skp *SkipperRouter) SetRoutes(canary *flaggerv1.Canary, primaryWeight, can
 
 	iClone := canaryIngress.DeepCopy()
 
-	// TODO: A/B testing
+	// A/B testing: the header predicates are added to the predicates of the apex ingress,
+	// so that the predicates of a previous match condition are replaced
+	if len(canary.GetAnalysis().Match) > 0 {
+		apexIngressName, _ := skp.getIngressNames(canary.Spec.IngressRef.Name)
+		apexIngress, err := skp.kubeClient.NetworkingV1().Ingresses(canary.Namespace).Get(context.TODO(), apexIngressName, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("apexIngress %s.%s get query error: %w", apexIngressName, canary.Namespace, err)
+		}
+		predicates := []string{}
+		if apexPredicates := strings.TrimSpace(apexIngress.Annotations[skipperpredicateAnnotationKey]); apexPredicates != "" {
+			predicates = append(predicates, apexPredicates)
+		}
+		predicates = append(predicates, skipperHeaderPredicates(canary.GetAnalysis().Match[0])...)
+		iClone.Annotations[skipperpredicateAnnotationKey] = strings.Join(predicates, " && ")
+	}
 
 	// Canary
 	iClone.Annotations = skp.makeAnnotations(iClone.Annotations, map[string]int{
@@ -255,6 +271,20 @@ func (skp *SkipperRouter) getIngressNames(name string) (apexName, canaryName str
 	return name, fmt.Sprintf(canaryPatternf, name)
 }
 
+// skipperHeaderPredicates returns the Header and HeaderRegexp predicates of a match condition
+func skipperHeaderPredicates(match istiov1beta1.HTTPMatchRequest) []string {
+	var predicates []string
+	for _, name := range sortedHeaders(match.Headers) {
+		value := match.Headers[name]
+		if value.Exact != "" {
+			predicates = append(predicates, fmt.Sprintf("Header(%s, %s)", strconv.Quote(name), strconv.Quote(value.Exact)))
+		} else {
+			predicates = append(predicates, fmt.Sprintf("HeaderRegexp(%s, %s)", strconv.Quote(name), strconv.Quote(stringMatchRegex(value))))
+		}
+	}
+	return predicates
+}
+
 func insertPredicate(raw, insert string) string {
 	// ensuring it at first place
 	predicates := []string{insert}
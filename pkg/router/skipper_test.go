@@ -22,7 +22,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
 )
 
 func TestSkipperRouter_Reconcile(t *testing.T) {
@@ -122,6 +126,44 @@ func TestSkipperRouter_GetSetRoutes(t *testing.T) {
 
 }
 
+func TestSkipperRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	canary := mocks.ingressCanary.DeepCopy()
+	canary.Spec.Analysis.Iterations = 10
+	canary.Spec.Analysis.Match = []istiov1beta1.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"X-Canary": {Exact: "insider"},
+				"Cookie":   {Regex: "^(.*?;)?(canary=always)(;.*)?$"},
+			},
+		},
+	}
+
+	router := &SkipperRouter{logger: mocks.logger, kubeClient: mocks.kubeClient}
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.SetRoutes(canary, 0, 100, false))
+
+	inCanary, err := mocks.kubeClient.NetworkingV1().Ingresses("default").Get(
+		context.TODO(), fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name), metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `Weight(100) && HeaderRegexp("Cookie", "^(.*?;)?(canary=always)(;.*)?$") && Header("X-Canary", "insider")`,
+		inCanary.Annotations[skipperpredicateAnnotationKey])
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	// the header predicates are not duplicated and the route is disabled after promotion
+	require.NoError(t, router.SetRoutes(canary, 0, 100, false))
+	require.NoError(t, router.SetRoutes(canary, 100, 0, false))
+	inCanary, err = mocks.kubeClient.NetworkingV1().Ingresses("default").Get(
+		context.TODO(), fmt.Sprintf("%s-canary", canary.Spec.IngressRef.Name), metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `False() && HeaderRegexp("Cookie", "^(.*?;)?(canary=always)(;.*)?$") && Header("X-Canary", "insider")`,
+		inCanary.Annotations[skipperpredicateAnnotationKey])
+}
+
 func Test_insertPredicate(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -19,8 +19,11 @@ package router
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
 	traefikv1alpha1 "github.com/fluxcd/flagger/pkg/apis/traefik/v1alpha1"
 	clientset "github.com/fluxcd/flagger/pkg/client/clientset/versioned"
 	"github.com/google/go-cmp/cmp"
@@ -99,9 +102,11 @@ func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
 		return fmt.Errorf("TraefikService %s.%s get query error: %w", apexName, canary.Namespace, err)
 	}
 
-	// update TraefikService but keep the original service weights
+	// update TraefikService but keep the original service weights and mirror
 	if traefikService != nil {
-		if len(traefikService.Spec.Weighted.Services) == 2 {
+		if traefikService.Spec.Mirroring != nil {
+			newSpec = traefikService.Spec
+		} else if len(traefikService.Spec.Weighted.Services) == 2 {
 			newSpec.Weighted.Services = append(
 				newSpec.Weighted.Services,
 				traefikv1alpha1.Service{
@@ -111,6 +116,7 @@ func (tr *TraefikRouter) Reconcile(canary *flaggerv1.Canary) error {
 					Weight:    100,
 				},
 			)
+			newSpec.Weighted.Sticky = traefikService.Spec.Weighted.Sticky
 		}
 
 		specDiff := cmp.Diff(
@@ -147,13 +153,31 @@ func (tr *TraefikRouter) GetRoutes(canary *flaggerv1.Canary) (
 ) {
 	apexName, primaryName, _ := canary.GetServiceNames()
 
+	// A/B testing: the canary receives the requests matched by the canary IngressRoute
+	if len(canary.GetAnalysis().Match) > 0 {
+		canaryRouteName := tr.getCanaryRouteName(canary)
+		_, err = tr.traefikClient.TraefikV1alpha1().IngressRoutes(canary.Namespace).Get(context.TODO(), canaryRouteName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return 100, 0, false, nil
+		} else if err != nil {
+			err = fmt.Errorf("IngressRoute %s.%s query error: %w", canaryRouteName, canary.Namespace, err)
+			return
+		}
+		return 0, 100, false, nil
+	}
+
 	traefikService, err := tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Get(context.TODO(), apexName, metav1.GetOptions{})
 	if err != nil {
 		err = fmt.Errorf("TraefikService %s.%s query error: %w", apexName, canary.Namespace, err)
 		return
 	}
 
-	if len(traefikService.Spec.Weighted.Services) < 1 {
+	// mirroring: the primary receives all the traffic and the canary a copy of it
+	if traefikService.Spec.Mirroring != nil {
+		return 100, 0, true, nil
+	}
+
+	if traefikService.Spec.Weighted == nil || len(traefikService.Spec.Weighted.Services) < 1 {
 		err = fmt.Errorf("TraefikService %s.%s services not found", apexName, canary.Namespace)
 		return
 	}
@@ -175,7 +199,7 @@ func (tr *TraefikRouter) SetRoutes(
 	canary *flaggerv1.Canary,
 	primaryWeight int,
 	canaryWeight int,
-	_ bool,
+	mirrored bool,
 ) error {
 	apexName, primaryName, canaryName := canary.GetServiceNames()
 
@@ -187,6 +211,19 @@ func (tr *TraefikRouter) SetRoutes(
 		return fmt.Errorf("TraefikService %s.%s query error: %w", apexName, canary.Namespace, err)
 	}
 
+	// A/B testing: the TraefikService routes all requests to the primary and
+	// the canary IngressRoute routes the requests matching the headers to the canary
+	if len(canary.GetAnalysis().Match) > 0 {
+		if canaryWeight > 0 {
+			if err := tr.reconcileCanaryRoute(canary); err != nil {
+				return err
+			}
+		} else if err := tr.deleteCanaryRoute(canary); err != nil {
+			return err
+		}
+		primaryWeight, canaryWeight = 100, 0
+	}
+
 	services := []traefikv1alpha1.Service{
 		{
 			Name:      primaryName,
@@ -204,7 +241,24 @@ func (tr *TraefikRouter) SetRoutes(
 		})
 	}
 
-	traefikService.Spec.Weighted.Services = services
+	traefikService.Spec = traefikv1alpha1.ServiceSpec{
+		Weighted: &traefikv1alpha1.WeightedRoundRobin{
+			Services: services,
+		},
+	}
+
+	// mirroring: route all traffic to the primary and mirror it to the canary
+	if mirrored && len(canary.GetAnalysis().Match) == 0 {
+		traefikService.Spec = tr.makeMirroringSpec(canary)
+	}
+
+	// session affinity: Traefik sets a cookie that pins the client to the service it was routed to
+	if hasSessionAffinity(canary) {
+		trackSessionAffinityCookieName(canary, canaryWeight)
+		if canaryWeight > 0 {
+			traefikService.Spec.Weighted.Sticky = tr.makeSticky(canary)
+		}
+	}
 
 	_, err = tr.traefikClient.TraefikV1alpha1().TraefikServices(canary.Namespace).Update(context.TODO(), traefikService, metav1.UpdateOptions{})
 	if err != nil {
@@ -213,6 +267,185 @@ func (tr *TraefikRouter) SetRoutes(
 	return nil
 }
 
-func (tr *TraefikRouter) Finalize(_ *flaggerv1.Canary) error {
+func (tr *TraefikRouter) Finalize(canary *flaggerv1.Canary) error {
+	if canary.Spec.RouteRef == nil || canary.Spec.RouteRef.Name == "" {
+		return nil
+	}
+	return tr.deleteCanaryRoute(canary)
+}
+
+// getCanaryRouteName returns the name of the IngressRoute generated for A/B testing
+func (tr *TraefikRouter) getCanaryRouteName(canary *flaggerv1.Canary) string {
+	apexName, _, _ := canary.GetServiceNames()
+	return fmt.Sprintf("%s-%s-canary", canary.Spec.RouteRef.Name, apexName)
+}
+
+// makeMirroringSpec returns the TraefikService spec that sends the traffic
+// to the primary and mirrors it to the canary
+func (tr *TraefikRouter) makeMirroringSpec(canary *flaggerv1.Canary) traefikv1alpha1.ServiceSpec {
+	_, primaryName, canaryName := canary.GetServiceNames()
+
+	percent := 100
+	if mw := canary.GetAnalysis().MirrorWeight; mw > 0 {
+		percent = mw
+	}
+
+	return traefikv1alpha1.ServiceSpec{
+		Mirroring: &traefikv1alpha1.Mirroring{
+			Name:      primaryName,
+			Namespace: canary.Namespace,
+			Port:      canary.Spec.Service.Port,
+			Mirrors: []traefikv1alpha1.MirrorService{
+				{
+					Name:      canaryName,
+					Namespace: canary.Namespace,
+					Port:      canary.Spec.Service.Port,
+					Percent:   percent,
+				},
+			},
+		},
+	}
+}
+
+// makeSticky returns the sticky cookie configuration of the weighted TraefikService
+func (tr *TraefikRouter) makeSticky(canary *flaggerv1.Canary) *traefikv1alpha1.Sticky {
+	sessionAffinity := canary.Spec.Analysis.SessionAffinity
+	return &traefikv1alpha1.Sticky{
+		Cookie: &traefikv1alpha1.Cookie{
+			Name:     sessionAffinity.CookieName,
+			Secure:   sessionAffinity.Secure,
+			HTTPOnly: sessionAffinity.HttpOnly,
+			SameSite: strings.ToLower(sessionAffinity.SameSite),
+			MaxAge:   sessionAffinity.GetMaxAge(),
+			Path:     sessionAffinity.Path,
+			Domain:   sessionAffinity.Domain,
+		},
+	}
+}
+
+// reconcileCanaryRoute creates or updates the canary IngressRoute from the routes of the
+// referenced IngressRoute that target the apex TraefikService, the match rule of each route
+// is extended with the header matchers and the requests are sent to the canary service
+func (tr *TraefikRouter) reconcileCanaryRoute(canary *flaggerv1.Canary) error {
+	if canary.Spec.RouteRef == nil || canary.Spec.RouteRef.Name == "" {
+		return fmt.Errorf("IngressRoute selector is empty")
+	}
+	apexName, _, canaryName := canary.GetServiceNames()
+
+	ingressRoute, err := tr.traefikClient.TraefikV1alpha1().IngressRoutes(canary.Namespace).Get(context.TODO(), canary.Spec.RouteRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("IngressRoute %s.%s query error: %w", canary.Spec.RouteRef.Name, canary.Namespace, err)
+	}
+
+	newSpec := traefikv1alpha1.IngressRouteSpec{
+		EntryPoints: ingressRoute.Spec.EntryPoints,
+		TLS:         ingressRoute.Spec.TLS,
+	}
+	for _, route := range ingressRoute.Spec.Routes {
+		if !slices.ContainsFunc(route.Services, func(service traefikv1alpha1.Service) bool {
+			return service.Kind == "TraefikService" && service.Name == apexName
+		}) {
+			continue
+		}
+
+		canaryRoute := route.DeepCopy()
+		canaryRoute.Match = fmt.Sprintf("(%s) && (%s)", route.Match, traefikHeaderMatchers(canary.GetAnalysis().Match, route.Syntax))
+		if route.Priority > 0 {
+			canaryRoute.Priority = route.Priority + 1
+		}
+		canaryRoute.Services = []traefikv1alpha1.Service{
+			{
+				Name:      canaryName,
+				Namespace: canary.Namespace,
+				Port:      canary.Spec.Service.Port,
+			},
+		}
+		newSpec.Routes = append(newSpec.Routes, *canaryRoute)
+	}
+	if len(newSpec.Routes) == 0 {
+		return fmt.Errorf("IngressRoute %s.%s has no route to the TraefikService %s",
+			canary.Spec.RouteRef.Name, canary.Namespace, apexName)
+	}
+
+	canaryRouteName := tr.getCanaryRouteName(canary)
+	canaryRoute, err := tr.traefikClient.TraefikV1alpha1().IngressRoutes(canary.Namespace).Get(context.TODO(), canaryRouteName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		canaryRoute = &traefikv1alpha1.IngressRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      canaryRouteName,
+				Namespace: canary.Namespace,
+			},
+			Spec: newSpec,
+		}
+		if tr.setOwnerRefs {
+			canaryRoute.OwnerReferences = []metav1.OwnerReference{
+				*metav1.NewControllerRef(canary, schema.GroupVersionKind{
+					Group:   flaggerv1.SchemeGroupVersion.Group,
+					Version: flaggerv1.SchemeGroupVersion.Version,
+					Kind:    flaggerv1.CanaryKind,
+				}),
+			}
+		}
+
+		_, err = tr.traefikClient.TraefikV1alpha1().IngressRoutes(canary.Namespace).Create(context.TODO(), canaryRoute, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("IngressRoute %s.%s create error: %w", canaryRouteName, canary.Namespace, err)
+		}
+		tr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("IngressRoute %s.%s created", canaryRouteName, canary.Namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("IngressRoute %s.%s query error: %w", canaryRouteName, canary.Namespace, err)
+	}
+
+	if diff := cmp.Diff(newSpec, canaryRoute.Spec, cmpopts.EquateEmpty()); diff != "" {
+		clone := canaryRoute.DeepCopy()
+		clone.Spec = newSpec
+		_, err = tr.traefikClient.TraefikV1alpha1().IngressRoutes(canary.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("IngressRoute %s.%s update error: %w", canaryRouteName, canary.Namespace, err)
+		}
+		tr.logger.With("canary", fmt.Sprintf("%s.%s", canary.Name, canary.Namespace)).
+			Infof("IngressRoute %s.%s updated", canaryRouteName, canary.Namespace)
+	}
+	return nil
+}
+
+// deleteCanaryRoute removes the canary IngressRoute generated for A/B testing
+func (tr *TraefikRouter) deleteCanaryRoute(canary *flaggerv1.Canary) error {
+	canaryRouteName := tr.getCanaryRouteName(canary)
+	err := tr.traefikClient.TraefikV1alpha1().IngressRoutes(canary.Namespace).Delete(context.TODO(), canaryRouteName, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("IngressRoute %s.%s delete error: %w", canaryRouteName, canary.Namespace, err)
+	}
 	return nil
 }
+
+// traefikHeaderMatchers returns the header matchers of the match conditions,
+// the matchers of a condition are combined with AND and the conditions with OR.
+// The v2 rule syntax uses the Headers and HeadersRegexp matchers,
+// the v3 syntax uses Header and HeaderRegexp.
+func traefikHeaderMatchers(match []istiov1beta1.HTTPMatchRequest, syntax string) string {
+	headerMatcher, regexpMatcher := "Header", "HeaderRegexp"
+	if syntax == "v2" {
+		headerMatcher, regexpMatcher = "Headers", "HeadersRegexp"
+	}
+
+	conditions := make([]string, 0, len(match))
+	for _, m := range match {
+		var matchers []string
+		for _, name := range sortedHeaders(m.Headers) {
+			value := m.Headers[name]
+			if value.Exact != "" {
+				matchers = append(matchers, fmt.Sprintf("%s(`%s`, `%s`)", headerMatcher, name, value.Exact))
+			} else {
+				matchers = append(matchers, fmt.Sprintf("%s(`%s`, `%s`)", regexpMatcher, name, stringMatchRegex(value)))
+			}
+		}
+		conditions = append(conditions, strings.Join(matchers, " && "))
+	}
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+	return "(" + strings.Join(conditions, ") || (") + ")"
+}
@@ -21,6 +21,9 @@ import (
 	"testing"
 
 	flaggerv1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
+	istiov1alpha1 "github.com/fluxcd/flagger/pkg/apis/istio/common/v1alpha1"
+	istiov1beta1 "github.com/fluxcd/flagger/pkg/apis/istio/v1beta1"
+	traefikv1alpha1 "github.com/fluxcd/flagger/pkg/apis/traefik/v1alpha1"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -139,6 +142,92 @@ func TestTraefikRouter_SetRoutes(t *testing.T) {
 	}
 }
 
+func TestTraefikRouter_SessionAffinity(t *testing.T) {
+	canary := newTestCanary()
+	canary.Spec.Analysis.SessionAffinity = &flaggerv1.SessionAffinity{
+		CookieName: "flagger-cookie",
+		MaxAge:     600,
+		SameSite:   "Strict",
+		Secure:     true,
+	}
+	mocks := newFixture(canary)
+	router := &TraefikRouter{
+		traefikClient: mocks.meshClient,
+		logger:        mocks.logger,
+	}
+
+	require.NoError(t, router.Reconcile(canary))
+	require.NoError(t, router.SetRoutes(canary, 80, 20, false))
+
+	ts, err := router.traefikClient.TraefikV1alpha1().TraefikServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, ts.Spec.Weighted.Sticky)
+	assert.Equal(t, &traefikv1alpha1.Cookie{
+		Name:     "flagger-cookie",
+		Secure:   true,
+		SameSite: "strict",
+		MaxAge:   600,
+	}, ts.Spec.Weighted.Sticky.Cookie)
+	assert.Equal(t, "flagger-cookie", canary.Status.SessionAffinityCookie)
+
+	// reconcile keeps the sticky cookie
+	require.NoError(t, router.Reconcile(canary))
+	ts, err = router.traefikClient.TraefikV1alpha1().TraefikServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotNil(t, ts.Spec.Weighted.Sticky)
+
+	require.NoError(t, router.SetRoutes(canary, 100, 0, false))
+	ts, err = router.traefikClient.TraefikV1alpha1().TraefikServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, ts.Spec.Weighted.Sticky)
+	assert.Empty(t, canary.Status.SessionAffinityCookie)
+	assert.Equal(t, "flagger-cookie", canary.Status.PreviousSessionAffinityCookie)
+}
+
+func TestTraefikRouter_Mirror(t *testing.T) {
+	mocks := newFixture(nil)
+	router := &TraefikRouter{
+		traefikClient: mocks.meshClient,
+		logger:        mocks.logger,
+	}
+
+	require.NoError(t, router.Reconcile(mocks.canary))
+	require.NoError(t, router.SetRoutes(mocks.canary, 100, 0, true))
+
+	ts, err := router.traefikClient.TraefikV1alpha1().TraefikServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, ts.Spec.Weighted)
+	require.NotNil(t, ts.Spec.Mirroring)
+	assert.Equal(t, "podinfo-primary", ts.Spec.Mirroring.Name)
+	assert.Equal(t, []traefikv1alpha1.MirrorService{
+		{Name: "podinfo-canary", Namespace: "default", Port: 9898, Percent: 100},
+	}, ts.Spec.Mirroring.Mirrors)
+
+	p, c, m, err := router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+	assert.True(t, m)
+
+	// reconcile keeps the mirror
+	require.NoError(t, router.Reconcile(mocks.canary))
+	_, _, m, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.True(t, m)
+
+	// stop mirroring
+	require.NoError(t, router.SetRoutes(mocks.canary, 0, 100, false))
+	ts, err = router.traefikClient.TraefikV1alpha1().TraefikServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, ts.Spec.Mirroring)
+
+	p, c, m, err = router.GetRoutes(mocks.canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+	assert.False(t, m)
+}
+
 func TestTraefikRouter_GetRoutes(t *testing.T) {
 	mocks := newFixture(nil)
 	router := &TraefikRouter{
@@ -156,3 +245,89 @@ func TestTraefikRouter_GetRoutes(t *testing.T) {
 	assert.Equal(t, 0, c)
 	assert.False(t, m)
 }
+
+func TestTraefikRouter_ABTest(t *testing.T) {
+	mocks := newFixture(nil)
+	canary := mocks.canary.DeepCopy()
+	canary.Spec.RouteRef = &flaggerv1.LocalObjectReference{Name: "podinfo"}
+	canary.Spec.Analysis.Iterations = 10
+	canary.Spec.Analysis.Match = []istiov1beta1.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-canary": {Exact: "insider"},
+			},
+		},
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"cookie": {Regex: "^(.*?;)?(canary=always)(;.*)?$"},
+			},
+		},
+	}
+
+	_, err := mocks.meshClient.TraefikV1alpha1().IngressRoutes("default").Create(context.TODO(), &traefikv1alpha1.IngressRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			EntryPoints: []string{"web"},
+			Routes: []traefikv1alpha1.Route{
+				{
+					Match: "Host(`app.example.com`)",
+					Kind:  "Rule",
+					Services: []traefikv1alpha1.Service{
+						{Name: "podinfo", Namespace: "default", Kind: "TraefikService"},
+					},
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	router := &TraefikRouter{
+		traefikClient: mocks.meshClient,
+		logger:        mocks.logger,
+	}
+	require.NoError(t, router.Reconcile(canary))
+
+	err = router.SetRoutes(canary, 0, 100, false)
+	require.NoError(t, err)
+
+	canaryRoute, err := mocks.meshClient.TraefikV1alpha1().IngressRoutes("default").Get(context.TODO(), "podinfo-podinfo-canary", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, canaryRoute.Spec.Routes, 1)
+	assert.Equal(t, "(Host(`app.example.com`)) && ((Header(`x-canary`, `insider`)) || (HeaderRegexp(`cookie`, `^(.*?;)?(canary=always)(;.*)?$`)))",
+		canaryRoute.Spec.Routes[0].Match)
+	assert.Equal(t, []string{"web"}, canaryRoute.Spec.EntryPoints)
+	assert.Equal(t, "podinfo-canary", canaryRoute.Spec.Routes[0].Services[0].Name)
+
+	// the requests that don't match are routed to the primary
+	ts, err := mocks.meshClient.TraefikV1alpha1().TraefikServices("default").Get(context.TODO(), "podinfo", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, ts.Spec.Weighted.Services, 1)
+	assert.Equal(t, uint(100), ts.Spec.Weighted.Services[0].Weight)
+
+	p, c, _, err := router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 0, p)
+	assert.Equal(t, 100, c)
+
+	// promotion removes the canary route
+	err = router.SetRoutes(canary, 100, 0, false)
+	require.NoError(t, err)
+
+	p, c, _, err = router.GetRoutes(canary)
+	require.NoError(t, err)
+	assert.Equal(t, 100, p)
+	assert.Equal(t, 0, c)
+}
+
+func TestTraefikHeaderMatchers(t *testing.T) {
+	match := []istiov1beta1.HTTPMatchRequest{
+		{
+			Headers: map[string]istiov1alpha1.StringMatch{
+				"x-user":   {Prefix: "test."},
+				"x-canary": {Exact: "insider"},
+			},
+		},
+	}
+	assert.Equal(t, "Headers(`x-canary`, `insider`) && HeadersRegexp(`x-user`, `^test\\.`)", traefikHeaderMatchers(match, "v2"))
+	assert.Equal(t, "Header(`x-canary`, `insider`) && HeaderRegexp(`x-user`, `^test\\.`)", traefikHeaderMatchers(match, ""))
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ringReplicas is the number of virtual nodes placed on the ring for each member,
+// a higher count spreads the canaries more evenly between the members
+const ringReplicas = 128
+
+// Ring is a consistent hash ring, when a member joins or leaves
+// only the keys owned by that member are moved to other members
+type Ring struct {
+	members []string
+	hashes  []uint64
+	owners  map[uint64]string
+}
+
+// NewRing returns a ring with the given members
+func NewRing(members []string) *Ring {
+	r := &Ring{
+		owners: make(map[uint64]string, len(members)*ringReplicas),
+	}
+	for _, member := range members {
+		r.members = append(r.members, member)
+		for i := 0; i < ringReplicas; i++ {
+			h := hash(fmt.Sprintf("%s#%d", member, i))
+			if _, ok := r.owners[h]; ok {
+				continue
+			}
+			r.owners[h] = member
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Strings(r.members)
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// Owner returns the member that owns the key or an empty string if the ring has no members
+func (r *Ring) Owner(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := hash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
+
+// Members returns the sorted list of members
+func (r *Ring) Members() []string {
+	return r.members
+}
+
+// Fingerprint identifies the ring members, two rings with the same members have the same fingerprint
+func (r *Ring) Fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join(r.members, ",")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func hash(key string) uint64 {
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing_Owner(t *testing.T) {
+	ring := NewRing([]string{"flagger-0", "flagger-1", "flagger-2"})
+	assert.Equal(t, []string{"flagger-0", "flagger-1", "flagger-2"}, ring.Members())
+
+	owners := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprintf("ns-%d/podinfo", i)
+		owner := ring.Owner(key)
+		assert.Equal(t, owner, ring.Owner(key))
+		owners[key] = owner
+		counts[owner]++
+	}
+	for member, count := range counts {
+		assert.Greater(t, count, 500, "member %s owns too few keys", member)
+	}
+
+	// only the keys of the removed member move
+	ring = NewRing([]string{"flagger-0", "flagger-2"})
+	for key, owner := range owners {
+		if owner != "flagger-1" {
+			assert.Equal(t, owner, ring.Owner(key))
+		} else {
+			assert.NotEqual(t, "flagger-1", ring.Owner(key))
+		}
+	}
+
+	assert.Empty(t, NewRing(nil).Owner("default/podinfo"))
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GroupLabel is set on the shard leases, the replicas with the same group split the canaries between them
+const GroupLabel = "flagger.app/shard-group"
+
+// RingAnnotation is set on the shard leases to the fingerprint of the ring seen by the lease holder
+const RingAnnotation = "flagger.app/shard-ring"
+
+// Shard decides which canaries are reconciled by this replica.
+// A canary is owned when it matches the shard label selector and, with hashing enabled,
+// when this replica is the owner of the canary namespace/name on the consistent hash ring.
+// The ring members are the replicas that renew a Lease in the shard group,
+// when a replica stops renewing its lease the canaries it owned move to the remaining members.
+// When the ring changes, a replica keeps only the canaries it owns in both the new ring and the last ring
+// every member agreed on, the canaries that move are left without an owner until all the members have seen
+// the new ring. If a member doesn't publish the new ring within two lease durations the handoff is forced,
+// that member may then reconcile the canaries it owned in its stale ring together with their new owners.
+type Shard struct {
+	kubeClient    kubernetes.Interface
+	namespace     string
+	group         string
+	identity      string
+	selector      labels.Selector
+	hashing       bool
+	leaseDuration time.Duration
+	logger        *zap.SugaredLogger
+
+	mu        sync.RWMutex
+	ring      *Ring
+	ringSince time.Time
+	stable    *Ring
+	revision  int64
+}
+
+// NewShard returns a shard that owns the canaries matching the label selector,
+// when hashing is enabled the canaries are split between the replicas in the group
+func NewShard(kubeClient kubernetes.Interface, namespace string, group string, identity string,
+	selector labels.Selector, hashing bool, leaseDuration time.Duration, logger *zap.SugaredLogger) *Shard {
+	if selector == nil {
+		selector = labels.Everything()
+	}
+	return &Shard{
+		kubeClient:    kubeClient,
+		namespace:     namespace,
+		group:         group,
+		identity:      identity,
+		selector:      selector,
+		hashing:       hashing,
+		leaseDuration: leaseDuration,
+		logger:        logger,
+		ring:          NewRing([]string{identity}),
+		ringSince:     time.Now(),
+	}
+}
+
+// Start registers this replica in the shard group and keeps its lease renewed until the context is cancelled,
+// on shutdown the lease is deleted so that the other replicas take over right away
+func (s *Shard) Start(ctx context.Context) error {
+	if !s.hashing {
+		return nil
+	}
+	if err := s.sync(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		wait.Until(func() {
+			if err := s.sync(ctx); err != nil {
+				s.logger.Errorf("Shard %s sync failed: %v", s.identity, err)
+			}
+		}, s.leaseDuration/3, ctx.Done())
+
+		err := s.kubeClient.CoordinationV1().Leases(s.namespace).Delete(context.Background(), s.leaseName(), metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			s.logger.Errorf("Shard %s lease delete failed: %v", s.identity, err)
+		}
+	}()
+	return nil
+}
+
+// Owns returns true if the canary is reconciled by this replica
+func (s *Shard) Owns(namespace string, name string, canaryLabels map[string]string) bool {
+	if !s.selector.Matches(labels.Set(canaryLabels)) {
+		return false
+	}
+	if !s.hashing {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	if s.ring.Owner(key) != s.identity {
+		return false
+	}
+	// the canaries that move wait for every member to see the new ring
+	if s.stable != s.ring {
+		return s.stable != nil && s.stable.Owner(key) == s.identity
+	}
+	return true
+}
+
+// Revision is incremented every time the shard members or the canaries owned by this replica change
+func (s *Shard) Revision() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revision
+}
+
+// Members returns the replicas that are currently part of the shard group
+func (s *Shard) Members() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ring.Members()
+}
+
+// sync rebuilds the ring from the replicas that hold a valid lease, removes the leases that expired
+// a long time ago and renews the lease of this replica with the fingerprint of the new ring
+func (s *Shard) sync(ctx context.Context) error {
+	leases, err := s.kubeClient.CoordinationV1().Leases(s.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", GroupLabel, s.group),
+	})
+	if err != nil {
+		return fmt.Errorf("lease list query in namespace %s failed: %w", s.namespace, err)
+	}
+
+	now := time.Now()
+	members := []string{s.identity}
+	fingerprints := make(map[string]string)
+	for _, lease := range leases.Items {
+		if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == s.identity {
+			continue
+		}
+		expiry := leaseExpiry(lease, s.leaseDuration)
+		if now.Before(expiry) {
+			members = append(members, *lease.Spec.HolderIdentity)
+			fingerprints[*lease.Spec.HolderIdentity] = lease.Annotations[RingAnnotation]
+			continue
+		}
+		// garbage collect the leases of the replicas that are gone
+		if now.After(expiry.Add(s.leaseDuration)) {
+			err := s.kubeClient.CoordinationV1().Leases(s.namespace).Delete(ctx, lease.Name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				s.logger.Errorf("Shard lease %s.%s delete failed: %v", lease.Name, lease.Namespace, err)
+			}
+		}
+	}
+	sort.Strings(members)
+
+	s.mu.Lock()
+	changed := false
+	if !reflect.DeepEqual(members, s.ring.Members()) {
+		s.ring = NewRing(members)
+		s.ringSince = now
+		changed = true
+		s.logger.Infof("Shard group %s members changed to %s", s.group, strings.Join(members, ", "))
+	}
+
+	// the canaries move to their new owners once every live member has seen the same ring
+	fingerprint := s.ring.Fingerprint()
+	converged := true
+	for _, seen := range fingerprints {
+		if seen != fingerprint {
+			converged = false
+			break
+		}
+	}
+	// don't leave the moved canaries without an owner when a member never catches up
+	if !converged && s.stable != s.ring && now.Sub(s.ringSince) > 2*s.leaseDuration {
+		s.logger.Warnf("Shard group %s members didn't see ring %s within %v, handing off the canaries",
+			s.group, fingerprint, 2*s.leaseDuration)
+		converged = true
+	}
+	if converged && s.stable != s.ring {
+		s.stable = s.ring
+		changed = true
+	}
+	if changed {
+		s.revision++
+	}
+	s.mu.Unlock()
+
+	return s.renew(ctx, fingerprint)
+}
+
+// renew creates or updates the lease of this replica
+func (s *Shard) renew(ctx context.Context, fingerprint string) error {
+	now := metav1.NewMicroTime(time.Now())
+	durationSeconds := int32(s.leaseDuration.Seconds())
+	name := s.leaseName()
+
+	lease, err := s.kubeClient.CoordinationV1().Leases(s.namespace).Get(ctx, name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   s.namespace,
+				Labels:      map[string]string{GroupLabel: s.group},
+				Annotations: map[string]string{RingAnnotation: fingerprint},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &s.identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		_, err = s.kubeClient.CoordinationV1().Leases(s.namespace).Create(ctx, lease, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("lease %s.%s create query failed: %w", name, s.namespace, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("lease %s.%s get query failed: %w", name, s.namespace, err)
+	}
+
+	leaseCopy := lease.DeepCopy()
+	if leaseCopy.Annotations == nil {
+		leaseCopy.Annotations = make(map[string]string)
+	}
+	leaseCopy.Annotations[RingAnnotation] = fingerprint
+	leaseCopy.Spec.HolderIdentity = &s.identity
+	leaseCopy.Spec.LeaseDurationSeconds = &durationSeconds
+	leaseCopy.Spec.RenewTime = &now
+	_, err = s.kubeClient.CoordinationV1().Leases(s.namespace).Update(ctx, leaseCopy, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("lease %s.%s update query failed: %w", name, s.namespace, err)
+	}
+	return nil
+}
+
+func (s *Shard) leaseName() string {
+	return fmt.Sprintf("flagger-shard-%s", s.identity)
+}
+
+// leaseExpiry returns the time after which the lease holder is considered gone
+func leaseExpiry(lease coordinationv1.Lease, defaultDuration time.Duration) time.Time {
+	if lease.Spec.RenewTime == nil {
+		return time.Time{}
+	}
+	duration := defaultDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return lease.Spec.RenewTime.Add(duration)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2026 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/fluxcd/flagger/pkg/logger"
+)
+
+func TestShard_Owns(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	logger, _ := logger.NewLogger("debug")
+	ctx := context.TODO()
+
+	shardA := NewShard(kubeClient, "flagger-system", "flagger", "flagger-a", nil, true, 15*time.Second, logger)
+	shardB := NewShard(kubeClient, "flagger-system", "flagger", "flagger-b", nil, true, 15*time.Second, logger)
+	require.NoError(t, shardA.sync(ctx))
+	require.NoError(t, shardB.sync(ctx))
+	assert.Equal(t, []string{"flagger-a"}, shardA.Members())
+	assert.Equal(t, []string{"flagger-a", "flagger-b"}, shardB.Members())
+
+	// the joining replica waits for the first one to see the new ring
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("podinfo-%d", i)
+		assert.True(t, shardA.Owns("default", name, nil))
+		assert.False(t, shardB.Owns("default", name, nil))
+	}
+
+	// the first replica releases the canaries of the new ring owner right away
+	require.NoError(t, shardA.sync(ctx))
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("podinfo-%d", i)
+		assert.False(t, shardA.Owns("default", name, nil) && shardB.Owns("default", name, nil),
+			"canary %s must not be owned by both replicas during the handoff", name)
+	}
+	require.NoError(t, shardB.sync(ctx))
+	assert.Equal(t, []string{"flagger-a", "flagger-b"}, shardA.Members())
+
+	// every canary is owned by exactly one replica
+	ownedByA := 0
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("podinfo-%d", i)
+		a := shardA.Owns("default", name, nil)
+		b := shardB.Owns("default", name, nil)
+		assert.NotEqual(t, a, b, "canary %s must have a single owner", name)
+		if a {
+			ownedByA++
+		}
+	}
+	assert.Greater(t, ownedByA, 0)
+	assert.Less(t, ownedByA, 100)
+
+	// the lease of the second replica expires and its canaries move to the first one
+	lease, err := kubeClient.CoordinationV1().Leases("flagger-system").Get(ctx, "flagger-shard-flagger-b", metav1.GetOptions{})
+	require.NoError(t, err)
+	expired := metav1.NewMicroTime(time.Now().Add(-20 * time.Second))
+	lease.Spec.RenewTime = &expired
+	_, err = kubeClient.CoordinationV1().Leases("flagger-system").Update(ctx, lease, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	revision := shardA.Revision()
+	require.NoError(t, shardA.sync(ctx))
+	assert.Equal(t, revision+1, shardA.Revision())
+	assert.Equal(t, []string{"flagger-a"}, shardA.Members())
+	for i := 0; i < 100; i++ {
+		assert.True(t, shardA.Owns("default", fmt.Sprintf("podinfo-%d", i), nil))
+	}
+
+	// the lease is kept until it expired for longer than the lease duration
+	_, err = kubeClient.CoordinationV1().Leases("flagger-system").Get(ctx, "flagger-shard-flagger-b", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestShard_OwnsMemberNeverConverges(t *testing.T) {
+	durationSeconds := int32(15)
+	renewTime := metav1.NewMicroTime(time.Now())
+	holder := "flagger-stuck"
+	kubeClient := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "flagger-shard-flagger-stuck",
+			Namespace:   "flagger-system",
+			Labels:      map[string]string{GroupLabel: "flagger"},
+			Annotations: map[string]string{RingAnnotation: "stale"},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &durationSeconds,
+			RenewTime:            &renewTime,
+		},
+	})
+	logger, _ := logger.NewLogger("debug")
+	ctx := context.TODO()
+
+	shardA := NewShard(kubeClient, "flagger-system", "flagger", "flagger-a", nil, true, 15*time.Second, logger)
+	shardB := NewShard(kubeClient, "flagger-system", "flagger", "flagger-b", nil, true, 15*time.Second, logger)
+	require.NoError(t, shardA.sync(ctx))
+	require.NoError(t, shardB.sync(ctx))
+	require.NoError(t, shardA.sync(ctx))
+	assert.Equal(t, []string{"flagger-a", "flagger-b", "flagger-stuck"}, shardA.Members())
+
+	// the stuck member never publishes the ring, the canaries wait for the handoff
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("podinfo-%d", i)
+		assert.False(t, shardA.Owns("default", name, nil))
+		assert.False(t, shardB.Owns("default", name, nil))
+	}
+
+	// the handoff is forced once the ring is older than two lease durations
+	revision := shardA.Revision()
+	shardA.ringSince = time.Now().Add(-time.Minute)
+	shardB.ringSince = time.Now().Add(-time.Minute)
+	require.NoError(t, shardA.sync(ctx))
+	require.NoError(t, shardB.sync(ctx))
+	assert.Equal(t, revision+1, shardA.Revision())
+
+	ownedByA, ownedByB := 0, 0
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("podinfo-%d", i)
+		a := shardA.Owns("default", name, nil)
+		b := shardB.Owns("default", name, nil)
+		assert.False(t, a && b, "canary %s must not be owned by both replicas", name)
+		assert.Equal(t, shardA.ring.Owner("default/"+name) == "flagger-a", a)
+		if a {
+			ownedByA++
+		}
+		if b {
+			ownedByB++
+		}
+	}
+	assert.Greater(t, ownedByA, 0)
+	assert.Greater(t, ownedByB, 0)
+}
+
+func TestShard_GarbageCollectLeases(t *testing.T) {
+	durationSeconds := int32(15)
+	renewTime := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	holder := "flagger-gone"
+	kubeClient := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "flagger-shard-flagger-gone",
+			Namespace: "flagger-system",
+			Labels:    map[string]string{GroupLabel: "flagger"},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &durationSeconds,
+			RenewTime:            &renewTime,
+		},
+	})
+	logger, _ := logger.NewLogger("debug")
+
+	shard := NewShard(kubeClient, "flagger-system", "flagger", "flagger-a", nil, true, 15*time.Second, logger)
+	require.NoError(t, shard.sync(context.TODO()))
+	assert.Equal(t, []string{"flagger-a"}, shard.Members())
+
+	leases, err := kubeClient.CoordinationV1().Leases("flagger-system").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, leases.Items, 1)
+	assert.Equal(t, "flagger-shard-flagger-a", leases.Items[0].Name)
+}
+
+func TestShard_Selector(t *testing.T) {
+	logger, _ := logger.NewLogger("debug")
+	selector, err := labels.Parse("shard=blue")
+	require.NoError(t, err)
+
+	shard := NewShard(fake.NewSimpleClientset(), "flagger-system", "flagger", "flagger-a", selector, false, 15*time.Second, logger)
+	require.NoError(t, shard.Start(context.TODO()))
+
+	assert.True(t, shard.Owns("default", "podinfo", map[string]string{"shard": "blue"}))
+	assert.False(t, shard.Owns("default", "podinfo", map[string]string{"shard": "green"}))
+	assert.False(t, shard.Owns("default", "podinfo", nil))
+}